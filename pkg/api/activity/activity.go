@@ -6,6 +6,8 @@
 package activity
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
@@ -43,3 +45,24 @@ func (s *Activity) Delete(c echo.Context, id int) error {
 	}
 	return s.sdb.Delete(s.db, id)
 }
+
+// Report summarizes sync activity: the success rate and average duration by company for each day
+// from "from" to "to" (inclusive dates), slices that have never synced and the last sync of
+// each subscription
+func (s *Activity) Report(c echo.Context, from, to time.Time) (*sandpiper.ActivityReport, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	var err error
+	rpt := &sandpiper.ActivityReport{From: from, To: to}
+	if rpt.Daily, err = s.sdb.Daily(s.db, from, to); err != nil {
+		return nil, err
+	}
+	if rpt.NeverSynced, err = s.sdb.NeverSynced(s.db); err != nil {
+		return nil, err
+	}
+	if rpt.LastSyncs, err = s.sdb.LastSyncs(s.db); err != nil {
+		return nil, err
+	}
+	return rpt, nil
+}
@@ -96,3 +96,19 @@ func (ls *LogService) Delete(c echo.Context, req int) (err error) {
 	}(time.Now())
 	return ls.Service.Delete(c, req)
 }
+
+// Report logging
+func (ls *LogService) Report(c echo.Context, from, to time.Time) (resp *sandpiper.ActivityReport, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Activity report request", err,
+			map[string]interface{}{
+				"from": from,
+				"to":   to,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Report(c, from, to)
+}
@@ -6,7 +6,12 @@ package pgsql
 
 // activity service database access
 
+// The reports only count syncs (activity rows with a sync session). Other activity, such as a
+// subscription expiry logged by the server (a "successful" row with a sub_id), is left out.
+
 import (
+	"time"
+
 	"github.com/go-pg/pg/v9/orm"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
@@ -40,6 +45,13 @@ func (s *Activity) View(db orm.DB, id int) (*sandpiper.Activity, error) {
 	return activity, nil
 }
 
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("activity", "id", "company_id", "sub_id", "session_id", "success",
+		"message", "error", "duration", "created_at"),
+	Sort: params.Columns("activity", "id", "company_id", "success", "duration", "created_at"),
+}
+
 // List returns a list of all activity with scoping and pagination
 func (s *Activity) List(db orm.DB, p *params.Params) (acts []sandpiper.Activity, err error) {
 
@@ -47,8 +59,12 @@ func (s *Activity) List(db orm.DB, p *params.Params) (acts []sandpiper.Activity,
 	if p.WantRelated("subscription") {
 		q.Relation("Subscription")
 	}
-	p.AddFilter(q)
-	p.AddSort(q, "id desc")
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "id desc"); err != nil {
+		return nil, err
+	}
 	q.Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
 
 	p.Paging.Count, err = q.SelectAndCountEstimate(50000)
@@ -64,3 +80,61 @@ func (s *Activity) Delete(db orm.DB, id int) error {
 	activity := sandpiper.Activity{ID: id}
 	return db.Delete(activity)
 }
+
+// Daily returns the sync success rate and average duration by company and day for syncs
+// logged from the start of "from" to the end of "to" (newest day first)
+func (s *Activity) Daily(db orm.DB, from, to time.Time) ([]sandpiper.ActivityDay, error) {
+	var days []sandpiper.ActivityDay
+	_, err := db.Query(&days, `
+		SELECT a.company_id, c.name AS company_name, date_trunc('day', a.created_at) AS day,
+			count(*) AS syncs,
+			count(*) FILTER (WHERE a.success) AS successes,
+			avg(a.success::int) AS success_rate,
+			coalesce(avg(a.duration), 0)::bigint AS avg_duration
+		FROM activity a JOIN companies c ON c.id = a.company_id
+		WHERE a.session_id IS NOT NULL AND a.created_at >= ? AND a.created_at < ?
+		GROUP BY a.company_id, c.name, day
+		ORDER BY day DESC, lower(c.name)`, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// NeverSynced returns slices without a successful sync for any of their subscriptions
+func (s *Activity) NeverSynced(db orm.DB) ([]sandpiper.UnsyncedSlice, error) {
+	var slices []sandpiper.UnsyncedSlice
+	_, err := db.Query(&slices, `
+		SELECT sl.id AS slice_id, sl.name AS slice_name, count(sub.sub_id) AS subscriptions
+		FROM slices sl LEFT JOIN subscriptions sub ON sub.slice_id = sl.id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM activity a JOIN subscriptions x ON x.sub_id = a.sub_id
+			WHERE x.slice_id = sl.id AND a.success AND a.session_id IS NOT NULL
+		)
+		GROUP BY sl.id, sl.name
+		ORDER BY lower(sl.name)`)
+	if err != nil {
+		return nil, err
+	}
+	return slices, nil
+}
+
+// LastSyncs returns the last sync attempt and success for every subscription
+func (s *Activity) LastSyncs(db orm.DB) ([]sandpiper.LastSync, error) {
+	var syncs []sandpiper.LastSync
+	_, err := db.Query(&syncs, `
+		SELECT sub.sub_id, sub.name AS sub_name, sub.company_id, c.name AS company_name,
+			sub.slice_id, sl.name AS slice_name, sub.active,
+			max(a.created_at) AS last_attempt,
+			max(a.created_at) FILTER (WHERE a.success) AS last_success
+		FROM subscriptions sub
+			JOIN companies c ON c.id = sub.company_id
+			JOIN slices sl ON sl.id = sub.slice_id
+			LEFT JOIN activity a ON a.sub_id = sub.sub_id AND a.session_id IS NOT NULL
+		GROUP BY sub.sub_id, c.name, sl.name
+		ORDER BY lower(c.name), lower(sl.name)`)
+	if err != nil {
+		return nil, err
+	}
+	return syncs, nil
+}
@@ -0,0 +1,75 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/activity/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+)
+
+var (
+	companyID = uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	sliceID   = uuid.MustParse("20000000-0000-0000-0000-000000000000")
+	subID     = uuid.MustParse("30000000-0000-0000-0000-000000000000")
+)
+
+func TestDaily(t *testing.T) {
+	day := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	db := (&mockdb.DB{}).On("FROM activity a", mockdb.Row{
+		"company_id": companyID, "company_name": "Acme", "day": day,
+		"syncs": 4, "successes": 3, "success_rate": "0.75", "avg_duration": 2000000000,
+	})
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	days, err := pgsql.NewActivity().Daily(db, from, day)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(days)) {
+		assert.Equal(t, 4, days[0].Syncs)
+		assert.Equal(t, 0.75, days[0].SuccessRate)
+		assert.Equal(t, 2*time.Second, days[0].AvgDuration)
+	}
+	// only syncs (not expiry) through the end of the last day
+	assert.Contains(t, db.SQL[0], "WHERE a.session_id IS NOT NULL AND a.created_at >= '2020-01-01 00:00:00+00:00:00' AND a.created_at < '2020-02-01 00:00:00+00:00:00'")
+	assert.Contains(t, db.SQL[0], "count(*) FILTER (WHERE a.success) AS successes")
+	assert.Contains(t, db.SQL[0], "GROUP BY a.company_id, c.name, day")
+}
+
+func TestNeverSynced(t *testing.T) {
+	db := (&mockdb.DB{}).On("FROM slices sl", mockdb.Row{"slice_id": sliceID, "slice_name": "Brakes", "subscriptions": 2})
+	slices, err := pgsql.NewActivity().NeverSynced(db)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(slices)) {
+		assert.Equal(t, "Brakes", slices[0].SliceName)
+		assert.Equal(t, 2, slices[0].Subscriptions)
+	}
+	// a successful expiry row isn't a sync
+	assert.Contains(t, db.SQL[0], "WHERE x.slice_id = sl.id AND a.success AND a.session_id IS NOT NULL")
+}
+
+func TestLastSyncs(t *testing.T) {
+	last := time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)
+	db := (&mockdb.DB{}).On("FROM subscriptions sub",
+		mockdb.Row{"sub_id": subID, "company_name": "Acme", "slice_name": "Brakes", "active": true,
+			"last_attempt": last, "last_success": nil},
+		mockdb.Row{"sub_id": uuid.New(), "company_name": "Acme", "slice_name": "Wipers", "active": true,
+			"last_attempt": nil, "last_success": nil},
+	)
+	syncs, err := pgsql.NewActivity().LastSyncs(db)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(syncs)) {
+		assert.Equal(t, subID, syncs[0].SubID)
+		assert.True(t, last.Equal(*syncs[0].LastAttempt))
+		assert.Nil(t, syncs[0].LastSuccess)
+		assert.Nil(t, syncs[1].LastAttempt) // never attempted
+	}
+	// every subscription is listed, with only its syncs joined
+	assert.Contains(t, db.SQL[0], "LEFT JOIN activity a ON a.sub_id = sub.sub_id AND a.session_id IS NOT NULL")
+	assert.Contains(t, db.SQL[0], "max(a.created_at) FILTER (WHERE a.success) AS last_success")
+}
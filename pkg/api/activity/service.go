@@ -6,6 +6,8 @@
 package activity
 
 import (
+	"time"
+
 	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
 	"github.com/labstack/echo/v4"
@@ -22,6 +24,7 @@ type Service interface {
 	List(echo.Context, *params.Params) ([]sandpiper.Activity, error)
 	View(echo.Context, int) (*sandpiper.Activity, error)
 	Delete(echo.Context, int) error
+	Report(echo.Context, time.Time, time.Time) (*sandpiper.ActivityReport, error)
 }
 
 // New creates new activity application service
@@ -53,6 +56,9 @@ type Repository interface {
 	View(orm.DB, int) (*sandpiper.Activity, error)
 	List(orm.DB, *params.Params) ([]sandpiper.Activity, error)
 	Delete(orm.DB, int) error
+	Daily(orm.DB, time.Time, time.Time) ([]sandpiper.ActivityDay, error)
+	NeverSynced(orm.DB) ([]sandpiper.UnsyncedSlice, error)
+	LastSyncs(orm.DB) ([]sandpiper.LastSync, error)
 }
 
 // RBAC represents role-based-access-control interface
@@ -15,6 +15,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/api/activity"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/middleware/session"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
@@ -30,6 +31,7 @@ func NewHTTP(svc activity.Service, er *echo.Group) {
 	sr := er.Group("/activity")
 	sr.POST("", h.create)
 	sr.GET("", h.list)
+	sr.GET("/report", h.report)
 	sr.GET("/:id", h.view)
 	sr.DELETE("/:id", h.delete)
 }
@@ -38,8 +40,17 @@ func NewHTTP(svc activity.Service, er *echo.Group) {
 var (
 	// ErrInvalidID indicates a malformed uuid
 	ErrInvalidID = echo.NewHTTPError(http.StatusBadRequest, "Invalid numeric activity id")
+
+	// ErrInvalidDate indicates a report date not in YYYY-MM-DD format
+	ErrInvalidDate = echo.NewHTTPError(http.StatusBadRequest, "Invalid report date (use YYYY-MM-DD)")
+
+	// ErrInvalidPeriod indicates a report ending before it starts
+	ErrInvalidPeriod = echo.NewHTTPError(http.StatusBadRequest, "Report \"from\" date must not be after \"to\" date")
 )
 
+// reportDays is the default report period (ending today)
+const reportDays = 30
+
 // activity create request
 type createReq struct {
 	CompanyID uuid.UUID     `json:"company_id" validate:"required"`
@@ -60,6 +71,7 @@ func (h *HTTP) create(c echo.Context) error {
 	result, err := h.svc.Create(c, sandpiper.Activity{
 		CompanyID: r.CompanyID,
 		SubID:     r.SubID,
+		SessionID: session.ID(c), // sent by the secondary as a header
 		Success:   r.Success,
 		Message:   r.Message,
 		Error:     r.Error,
@@ -86,6 +98,40 @@ func (h *HTTP) list(c echo.Context) error {
 	return c.JSON(http.StatusOK, sandpiper.ActivityPaginated{Activity: result, Paging: p.Paging})
 }
 
+// report uses optional "from" and "to" query params (inclusive dates)
+func (h *HTTP) report(c echo.Context) error {
+	to, err := reportDate(c.QueryParam("to"), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	from, err := reportDate(c.QueryParam("from"), to.AddDate(0, 0, 1-reportDays))
+	if err != nil {
+		return err
+	}
+	if from.After(to) {
+		return ErrInvalidPeriod
+	}
+
+	result, err := h.svc.Report(c, from, to)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// reportDate parses a date (using the default if empty) truncated to midnight UTC
+func reportDate(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def.Truncate(24 * time.Hour), nil
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return d, nil
+}
+
 func (h *HTTP) view(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
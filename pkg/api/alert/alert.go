@@ -0,0 +1,111 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package alert contains services for alerting company recipients (by email or webhook) about
+// failed syncs, stale slices and expiring subscriptions. Rules are checked in the background and
+// each condition opens one alert until it clears, when a recovery notice is sent.
+package alert
+
+import (
+	"net/http"
+	"net/mail"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Custom errors
+var (
+	// ErrInvalidChannel indicates an unknown delivery channel
+	ErrInvalidChannel = echo.NewHTTPError(http.StatusBadRequest, "Alert channel must be \"email\" or \"webhook\"")
+
+	// ErrInvalidEmail indicates a malformed email address
+	ErrInvalidEmail = echo.NewHTTPError(http.StatusBadRequest, "Invalid email address")
+
+	// ErrInvalidURL indicates a webhook that is not an absolute http(s) url
+	ErrInvalidURL = echo.NewHTTPError(http.StatusBadRequest, "Invalid webhook url (must be http or https)")
+
+	// ErrInvalidRule indicates an unknown alert rule
+	ErrInvalidRule = echo.NewHTTPError(http.StatusBadRequest, "Unknown alert rule")
+)
+
+// List returns alerts (newest first) if administrator
+func (s *Alert) List(c echo.Context, p *params.Params) ([]sandpiper.Alert, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.sdb.List(s.db, p)
+}
+
+// Check runs the alert rules now (instead of waiting for the next interval) if administrator
+func (s *Alert) Check(c echo.Context) ([]sandpiper.Alert, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.Evaluate()
+}
+
+// Recipients returns the alert recipients if administrator
+func (s *Alert) Recipients(c echo.Context, p *params.Params) ([]sandpiper.AlertRecipient, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.sdb.ListRecipients(s.db, p)
+}
+
+// CreateRecipient adds an alert recipient for a company if administrator
+func (s *Alert) CreateRecipient(c echo.Context, req sandpiper.AlertRecipient) (*sandpiper.AlertRecipient, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if err := validateRecipient(&req); err != nil {
+		return nil, err
+	}
+	return s.sdb.CreateRecipient(s.db, &req)
+}
+
+// DeleteRecipient removes an alert recipient if administrator
+func (s *Alert) DeleteRecipient(c echo.Context, id uuid.UUID) error {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return err
+	}
+	return s.sdb.DeleteRecipient(s.db, id)
+}
+
+// validateRecipient checks the channel, address and rules of a recipient
+func validateRecipient(r *sandpiper.AlertRecipient) error {
+	switch r.Channel {
+	case sandpiper.AlertEmail:
+		addr, err := mail.ParseAddress(r.Address)
+		if err != nil || addr.Address != r.Address {
+			return ErrInvalidEmail
+		}
+	case sandpiper.AlertWebhook:
+		u, err := url.Parse(r.Address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidURL
+		}
+	default:
+		return ErrInvalidChannel
+	}
+	for _, rule := range r.Rules {
+		if !knownRule(rule) {
+			return ErrInvalidRule
+		}
+	}
+	return nil
+}
+
+func knownRule(rule string) bool {
+	for _, r := range sandpiper.AlertRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
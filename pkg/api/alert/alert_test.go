@@ -0,0 +1,204 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/notify"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/notify/notifytest"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	failing := sandpiper.Alert{Rule: sandpiper.AlertSyncFailure, Key: "a"}
+	stale := sandpiper.Alert{Rule: sandpiper.AlertStaleSlice, Key: "a"}
+	openFailing := sandpiper.Alert{ID: 1, Rule: sandpiper.AlertSyncFailure, Key: "a", NotifiedAt: now.Add(-2 * time.Hour)}
+	openStale := sandpiper.Alert{ID: 2, Rule: sandpiper.AlertStaleSlice, Key: "a", NotifiedAt: now.Add(-2 * time.Hour)}
+
+	cases := []struct {
+		name   string
+		open   []sandpiper.Alert
+		firing []sandpiper.Alert
+		repeat time.Duration
+		want   []string // event:rule of each change
+	}{
+		{
+			name:   "New alert opens once",
+			firing: []sandpiper.Alert{failing, failing},
+			want:   []string{"alert:sync_failure"},
+		},
+		{
+			name:   "Open alert is not repeated",
+			open:   []sandpiper.Alert{openFailing},
+			firing: []sandpiper.Alert{failing},
+		},
+		{
+			name:   "Open alert is repeated after the interval",
+			open:   []sandpiper.Alert{openFailing},
+			firing: []sandpiper.Alert{failing},
+			repeat: time.Hour,
+			want:   []string{"alert:sync_failure"},
+		},
+		{
+			name:   "Open alert within the interval",
+			open:   []sandpiper.Alert{openFailing},
+			firing: []sandpiper.Alert{failing},
+			repeat: 3 * time.Hour,
+		},
+		{
+			name:   "Cleared alert resolves (same key, other rule still open)",
+			open:   []sandpiper.Alert{openFailing, openStale},
+			firing: []sandpiper.Alert{stale},
+			want:   []string{"resolved:sync_failure"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := reconcile(tt.open, tt.firing, now, tt.repeat)
+			var got []string
+			for _, ch := range changes {
+				got = append(got, ch.event+":"+ch.alert.Rule)
+				assert.Equal(t, now, ch.alert.NotifiedAt)
+				if ch.event == sandpiper.AlertResolved {
+					assert.Equal(t, now, ch.alert.ResolvedAt)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// repo returns fixed recipients (other repository methods are not used by deliver)
+type repo struct {
+	Repository
+	recipients []sandpiper.AlertRecipient
+}
+
+func (r *repo) Recipients(orm.DB, ...uuid.UUID) ([]sandpiper.AlertRecipient, error) {
+	return r.recipients, nil
+}
+
+type ourServer struct {
+	RBAC
+	id uuid.UUID
+}
+
+func (s ourServer) OurServer() *sandpiper.Server {
+	return &sandpiper.Server{ID: s.id}
+}
+
+func TestDeliver(t *testing.T) {
+	smtpSrv, err := notifytest.NewSMTPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer smtpSrv.Close()
+
+	var notices []sandpiper.AlertNotice
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n sandpiper.AlertNotice
+		_ = json.NewDecoder(r.Body).Decode(&n)
+		notices = append(notices, n)
+	}))
+	defer hook.Close()
+
+	serverID := uuid.New()
+	svc := &Alert{
+		sdb: &repo{recipients: []sandpiper.AlertRecipient{
+			{Channel: sandpiper.AlertEmail, Address: "ops@example.com"},
+			{Channel: sandpiper.AlertWebhook, Address: hook.URL},
+			{Channel: sandpiper.AlertEmail, Address: "expiry@example.com", Rules: []string{sandpiper.AlertExpiringSub}},
+		}},
+		rbac:     ourServer{id: serverID},
+		notifier: &Delivery{Mailer: &notify.Mailer{Addr: smtpSrv.Addr, From: "sandpiper@example.com"}},
+		cfg:      &config.Alerting{},
+	}
+	a := &sandpiper.Alert{
+		ID:       7,
+		Rule:     sandpiper.AlertSyncFailure,
+		Key:      "company",
+		Title:    "Sync failed for company \"Acme\"",
+		Detail:   "connection refused",
+		OpenedAt: time.Now(),
+	}
+
+	assert.Equal(t, "", svc.deliver(sandpiper.AlertOpened, a))
+	a.ResolvedAt = time.Now()
+	assert.Equal(t, "", svc.deliver(sandpiper.AlertResolved, a))
+
+	msgs := smtpSrv.Messages()
+	if assert.Equal(t, 2, len(msgs)) {
+		assert.Equal(t, []string{"ops@example.com"}, msgs[0].To) // not the expiry-only recipient
+		assert.Contains(t, msgs[0].Data, "Subject: [Sandpiper] Sync failed")
+		assert.Contains(t, msgs[1].Data, "Subject: [Sandpiper] Resolved: Sync failed")
+		assert.True(t, strings.Contains(msgs[1].Data, "This alert has cleared."))
+	}
+	if assert.Equal(t, 2, len(notices)) {
+		assert.Equal(t, sandpiper.AlertOpened, notices[0].Event)
+		assert.Equal(t, sandpiper.AlertResolved, notices[1].Event)
+		assert.Equal(t, serverID, notices[1].ServerID)
+		assert.Equal(t, 7, notices[1].Alert.ID)
+	}
+
+	// delivery errors are reported (without stopping other recipients)
+	svc.notifier = &Delivery{}
+	errs := svc.deliver(sandpiper.AlertOpened, a)
+	assert.Contains(t, errs, "ops@example.com: "+ErrNoSMTP.Error())
+	assert.Equal(t, 3, len(notices))
+}
+
+func TestValidateRecipient(t *testing.T) {
+	cases := []struct {
+		name string
+		r    sandpiper.AlertRecipient
+		want error
+	}{
+		{
+			name: "Email",
+			r:    sandpiper.AlertRecipient{Channel: sandpiper.AlertEmail, Address: "ops@example.com"},
+		},
+		{
+			name: "Email with name",
+			r:    sandpiper.AlertRecipient{Channel: sandpiper.AlertEmail, Address: "Ops <ops@example.com>"},
+			want: ErrInvalidEmail,
+		},
+		{
+			name: "Webhook",
+			r:    sandpiper.AlertRecipient{Channel: sandpiper.AlertWebhook, Address: "https://hooks.example.com/x", Rules: []string{sandpiper.AlertStaleSlice}},
+		},
+		{
+			name: "Webhook without scheme",
+			r:    sandpiper.AlertRecipient{Channel: sandpiper.AlertWebhook, Address: "hooks.example.com/x"},
+			want: ErrInvalidURL,
+		},
+		{
+			name: "Unknown channel",
+			r:    sandpiper.AlertRecipient{Channel: "sms", Address: "555-1234"},
+			want: ErrInvalidChannel,
+		},
+		{
+			name: "Unknown rule",
+			r:    sandpiper.AlertRecipient{Channel: sandpiper.AlertEmail, Address: "ops@example.com", Rules: []string{"disk_full"}},
+			want: ErrInvalidRule,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validateRecipient(&tt.r))
+		})
+	}
+}
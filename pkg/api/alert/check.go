@@ -0,0 +1,203 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// change is an alert to notify (and save) after a check
+type change struct {
+	event string // opened (or repeated) or resolved
+	alert sandpiper.Alert
+}
+
+// Evaluate runs every rule, opening new alerts, repeating open alerts (if configured) and
+// resolving alerts that no longer fire. It returns the alerts notified.
+func (s *Alert) Evaluate() ([]sandpiper.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	firing, err := s.firing(now)
+	if err != nil {
+		return nil, err
+	}
+	open, err := s.sdb.Open(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := reconcile(open, firing, now, s.cfg.RepeatInterval())
+	notified := make([]sandpiper.Alert, 0, len(changes))
+	for _, ch := range changes {
+		a := ch.alert
+		if a.ID == 0 {
+			// save a new alert first so the notice includes its id
+			if err := s.sdb.Save(s.db, &a); err != nil {
+				return notified, err
+			}
+		}
+		a.DeliveryError = s.deliver(ch.event, &a)
+		if err := s.sdb.Save(s.db, &a); err != nil {
+			return notified, err
+		}
+		notified = append(notified, a)
+	}
+	return notified, nil
+}
+
+// StartChecks runs Evaluate in the background now and then at each interval, logging any
+// failed check and each alert notified (with its delivery errors)
+func (s *Alert) StartChecks(logger sandpiper.Logger) {
+	go func() {
+		ticker := time.NewTicker(s.cfg.CheckInterval())
+		defer ticker.Stop()
+		for {
+			notified, err := s.Evaluate()
+			if err != nil {
+				logger.Log(nil, "alert", "Check alert rules", err, nil)
+			}
+			for _, a := range notified {
+				var err error
+				if a.DeliveryError != "" {
+					err = errors.New(a.DeliveryError)
+				}
+				logger.Log(nil, "alert", "Notify alert", err,
+					map[string]interface{}{
+						"id":       a.ID,
+						"rule":     a.Rule,
+						"key":      a.Key,
+						"resolved": !a.ResolvedAt.IsZero(),
+					},
+				)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// firing returns the alerts that should be open now (for every rule)
+func (s *Alert) firing(now time.Time) ([]sandpiper.Alert, error) {
+	var firing []sandpiper.Alert
+	add := func(alerts []sandpiper.Alert, err error) error {
+		firing = append(firing, alerts...)
+		return err
+	}
+	if err := add(s.sdb.FailedSyncs(s.db, sandpiper.AlertSyncFailure, 1)); err != nil {
+		return nil, err
+	}
+	if n := s.cfg.Failures(); n > 1 {
+		if err := add(s.sdb.FailedSyncs(s.db, sandpiper.AlertConsecutiveFailures, n)); err != nil {
+			return nil, err
+		}
+	}
+	if err := add(s.sdb.StaleSlices(s.db, sandpiper.AlertStaleSlice, now.Add(-s.cfg.StalePeriod()))); err != nil {
+		return nil, err
+	}
+	if err := add(s.sdb.ExpiringSubs(s.db, sandpiper.AlertExpiringSub, now, now.Add(s.cfg.ExpiringPeriod()))); err != nil {
+		return nil, err
+	}
+	return firing, nil
+}
+
+// reconcile compares the open alerts with those firing now (by rule and key). New conditions
+// open an alert, open alerts are repeated once `repeat` has passed since the last notice (never
+// if zero) and open alerts no longer firing are resolved.
+func reconcile(open, firing []sandpiper.Alert, now time.Time, repeat time.Duration) []change {
+	key := func(a *sandpiper.Alert) string { return a.Rule + "/" + a.Key }
+
+	opened := make(map[string]*sandpiper.Alert, len(open))
+	for i := range open {
+		opened[key(&open[i])] = &open[i]
+	}
+
+	var changes []change
+	current := make(map[string]bool, len(firing))
+	for _, a := range firing {
+		k := key(&a)
+		if current[k] {
+			continue
+		}
+		current[k] = true
+		if o, ok := opened[k]; ok {
+			if repeat > 0 && now.Sub(o.NotifiedAt) >= repeat {
+				o.Title, o.Detail, o.NotifiedAt = a.Title, a.Detail, now
+				changes = append(changes, change{event: sandpiper.AlertOpened, alert: *o})
+			}
+			continue
+		}
+		a.OpenedAt, a.NotifiedAt = now, now
+		changes = append(changes, change{event: sandpiper.AlertOpened, alert: a})
+	}
+	for _, o := range open {
+		if !current[key(&o)] {
+			o.ResolvedAt, o.NotifiedAt = now, now
+			changes = append(changes, change{event: sandpiper.AlertResolved, alert: o})
+		}
+	}
+	return changes
+}
+
+// deliver sends a notice to the recipients of the alert's company and of our own company that
+// want the rule, returning any delivery errors (one recipient failing doesn't stop the others)
+func (s *Alert) deliver(event string, a *sandpiper.Alert) string {
+	ours := s.rbac.OurServer().ID
+	recipients, err := s.sdb.Recipients(s.db, a.CompanyID, ours)
+	if err != nil {
+		return err.Error()
+	}
+	notice := &sandpiper.AlertNotice{Event: event, ServerID: ours, Alert: *a}
+	subject, body := message(notice)
+
+	var errs []string
+	for _, r := range recipients {
+		if !r.Wants(a.Rule) {
+			continue
+		}
+		switch r.Channel {
+		case sandpiper.AlertEmail:
+			err = s.notifier.Email([]string{r.Address}, subject, body)
+		case sandpiper.AlertWebhook:
+			err = s.notifier.Webhook(context.Background(), r.Address, notice)
+		default:
+			err = fmt.Errorf("unknown channel %q", r.Channel)
+		}
+		if err != nil {
+			errs = append(errs, r.Address+": "+err.Error())
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
+// message returns the email subject and body of a notice
+func message(n *sandpiper.AlertNotice) (subject, body string) {
+	a := &n.Alert
+	subject = "[Sandpiper] " + a.Title
+	if n.Event == sandpiper.AlertResolved {
+		subject = "[Sandpiper] Resolved: " + a.Title
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", a.Title)
+	if n.Event == sandpiper.AlertResolved {
+		b.WriteString("This alert has cleared.\n\n")
+	}
+	fmt.Fprintf(&b, "%s\n\n", a.Detail)
+	fmt.Fprintf(&b, "Rule:     %s\n", a.Rule)
+	fmt.Fprintf(&b, "Alert:    %d\n", a.ID)
+	fmt.Fprintf(&b, "Opened:   %s\n", a.OpenedAt.Format(time.RFC3339))
+	if !a.ResolvedAt.IsZero() {
+		fmt.Fprintf(&b, "Resolved: %s\n", a.ResolvedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "Server:   %s\n", n.ServerID)
+	return subject, b.String()
+}
@@ -0,0 +1,112 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package alert
+
+// alert service logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/alert"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the alert service
+func ServiceLogger(svc alert.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents alert logging service
+type LogService struct {
+	alert.Service
+	logger sandpiper.Logger
+}
+
+const source = "alert"
+
+// List logging
+func (ls *LogService) List(c echo.Context, req *params.Params) (resp []sandpiper.Alert, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List alerts request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": fmt.Sprintf("Count: %d", len(resp)),
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.List(c, req)
+}
+
+// Check logging
+func (ls *LogService) Check(c echo.Context) (resp []sandpiper.Alert, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Check alerts request", err,
+			map[string]interface{}{
+				"resp": fmt.Sprintf("Notified: %d", len(resp)),
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Check(c)
+}
+
+// Recipients logging
+func (ls *LogService) Recipients(c echo.Context, req *params.Params) (resp []sandpiper.AlertRecipient, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List alert recipients request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": fmt.Sprintf("Count: %d", len(resp)),
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Recipients(c, req)
+}
+
+// CreateRecipient logging
+func (ls *LogService) CreateRecipient(c echo.Context, req sandpiper.AlertRecipient) (resp *sandpiper.AlertRecipient, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Create alert recipient request", err,
+			map[string]interface{}{
+				"req":  req,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.CreateRecipient(c, req)
+}
+
+// DeleteRecipient logging
+func (ls *LogService) DeleteRecipient(c echo.Context, req uuid.UUID) (err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Delete alert recipient request", err,
+			map[string]interface{}{
+				"req":  req,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.DeleteRecipient(c, req)
+}
@@ -0,0 +1,34 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package alert
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/notify"
+)
+
+// ErrNoSMTP indicates an email recipient without a mail server in the alerting configuration
+var ErrNoSMTP = errors.New("email alerts require an smtp mail server (see alerting: smtp: in the config file)")
+
+// Delivery sends alert notices by email (if a mailer is configured) and by webhook
+type Delivery struct {
+	Mailer *notify.Mailer
+}
+
+// Email sends a plain text notice
+func (d *Delivery) Email(to []string, subject, body string) error {
+	if d.Mailer == nil {
+		return ErrNoSMTP
+	}
+	return d.Mailer.Send(to, subject, body)
+}
+
+// Webhook posts the notice as json
+func (d *Delivery) Webhook(ctx context.Context, url string, notice *sandpiper.AlertNotice) error {
+	return notify.Post(ctx, url, notice)
+}
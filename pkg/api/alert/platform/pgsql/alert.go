@@ -0,0 +1,266 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// alert service database access
+
+// Each rule query returns the alerts that should be open now. Sync rules only look at activity
+// rows with a sync session (see sync.Start), so other activity (e.g. expired subscriptions)
+// never counts as a sync.
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Custom errors
+var (
+	ErrRecipientNotFound = echo.NewHTTPError(http.StatusNotFound, "Alert recipient not found.")
+	ErrCompanyNotFound   = echo.NewHTTPError(http.StatusNotFound, "Company not found.")
+)
+
+// Alert represents the client for alerts and alert_recipients tables
+type Alert struct{}
+
+// NewAlert returns a new alert database instance
+func NewAlert() *Alert {
+	return &Alert{}
+}
+
+// listFields are the fields an alert list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("alert", "id", "rule", "alert_key", "company_id", "opened_at",
+		"notified_at", "resolved_at"),
+	Sort: params.Columns("alert", "id", "rule", "opened_at", "resolved_at"),
+}
+
+// List returns alerts (newest first) with filtering and pagination
+func (s *Alert) List(db orm.DB, p *params.Params) (alerts []sandpiper.Alert, err error) {
+	q := db.Model(&alerts).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "id DESC"); err != nil {
+		return nil, err
+	}
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Open returns the unresolved alerts
+func (s *Alert) Open(db orm.DB) ([]sandpiper.Alert, error) {
+	var alerts []sandpiper.Alert
+	err := db.Model(&alerts).Where("resolved_at IS NULL").Order("id").Select()
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Save adds a new alert or updates an existing one
+func (s *Alert) Save(db orm.DB, alert *sandpiper.Alert) error {
+	if alert.ID == 0 {
+		return db.Insert(alert)
+	}
+	_, err := db.Model(alert).WherePK().
+		Column("title", "detail", "notified_at", "resolved_at", "delivery_error").
+		Update()
+	return err
+}
+
+// FailedSyncs returns an alert for each company (and subscription) whose last `n` syncs failed
+func (s *Alert) FailedSyncs(db orm.DB, rule string, n int) ([]sandpiper.Alert, error) {
+	var rows []struct {
+		CompanyID   uuid.UUID
+		CompanyName string
+		SubID       uuid.UUID
+		SubName     string
+		LastFailure time.Time
+		Error       string
+	}
+	_, err := db.Query(&rows, `
+		WITH recent AS (
+			SELECT company_id, sub_id, success, error, created_at,
+				row_number() OVER (PARTITION BY company_id, sub_id ORDER BY id DESC) AS n
+			FROM activity WHERE session_id IS NOT NULL
+		)
+		SELECT r.company_id, c.name AS company_name, r.sub_id, s.name AS sub_name,
+			max(r.created_at) AS last_failure, (array_agg(r.error ORDER BY r.n))[1] AS error
+		FROM recent r
+		LEFT JOIN companies c ON c.id = r.company_id
+		LEFT JOIN subscriptions s ON s.sub_id = r.sub_id
+		WHERE r.n <= ?0
+		GROUP BY r.company_id, c.name, r.sub_id, s.name
+		HAVING count(*) = ?0 AND bool_and(NOT r.success)`, n)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]sandpiper.Alert, len(rows))
+	for i, row := range rows {
+		what := fmt.Sprintf("company %q", row.CompanyName)
+		key := row.CompanyID.String()
+		if row.SubID != uuid.Nil {
+			what = fmt.Sprintf("subscription %q of %s", row.SubName, what)
+			key += "/" + row.SubID.String()
+		}
+		title := "Sync failed for " + what
+		if n > 1 {
+			title = fmt.Sprintf("%d syncs in a row failed for %s", n, what)
+		}
+		alerts[i] = sandpiper.Alert{
+			Rule:      rule,
+			Key:       key,
+			CompanyID: row.CompanyID,
+			Title:     title,
+			Detail:    fmt.Sprintf("Last failure at %s: %s", row.LastFailure.Format(time.RFC3339), row.Error),
+		}
+	}
+	return alerts, nil
+}
+
+// StaleSlices returns an alert for each active subscription without a successful sync since the
+// cutoff (using the slice's last good sync or a successful sync logged for its subscription)
+func (s *Alert) StaleSlices(db orm.DB, rule string, cutoff time.Time) ([]sandpiper.Alert, error) {
+	var rows []struct {
+		SubID       uuid.UUID
+		SubName     string
+		CompanyID   uuid.UUID
+		CompanyName string
+		SliceName   string
+		LastGood    time.Time
+	}
+	_, err := db.Query(&rows, `
+		SELECT * FROM (
+			SELECT sub.sub_id, sub.name AS sub_name, sub.company_id, c.name AS company_name,
+				sl.name AS slice_name, sub.created_at,
+				greatest(sl.last_good_sync, (
+					SELECT max(a.created_at) FROM activity a
+					WHERE a.success AND a.session_id IS NOT NULL AND a.company_id = sub.company_id
+						AND (a.sub_id = sub.sub_id OR a.sub_id IS NULL)
+				)) AS last_good
+			FROM subscriptions sub
+			JOIN slices sl ON sl.id = sub.slice_id
+			JOIN companies c ON c.id = sub.company_id
+			WHERE sub.active AND c.active
+		) subs
+		WHERE coalesce(last_good, created_at) < ?`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]sandpiper.Alert, len(rows))
+	for i, row := range rows {
+		detail := "Never synced successfully"
+		if !row.LastGood.IsZero() {
+			detail = "Last good sync at " + row.LastGood.Format(time.RFC3339)
+		}
+		alerts[i] = sandpiper.Alert{
+			Rule:      rule,
+			Key:       row.SubID.String(),
+			CompanyID: row.CompanyID,
+			Title:     fmt.Sprintf("Slice %q is stale for company %q", row.SliceName, row.CompanyName),
+			Detail:    detail + fmt.Sprintf(" (subscription %q)", row.SubName),
+		}
+	}
+	return alerts, nil
+}
+
+// ExpiringSubs returns an alert for each active subscription ending after `from` and by `to`
+func (s *Alert) ExpiringSubs(db orm.DB, rule string, from, to time.Time) ([]sandpiper.Alert, error) {
+	var subs []sandpiper.Subscription
+	err := db.Model(&subs).Relation("Company").
+		Where("subscription.active").
+		Where("subscription.end_date > ? AND subscription.end_date <= ?", from, to).
+		Order("subscription.end_date").Select()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]sandpiper.Alert, len(subs))
+	for i, sub := range subs {
+		alerts[i] = sandpiper.Alert{
+			Rule:      rule,
+			Key:       sub.SubID.String(),
+			CompanyID: sub.CompanyID,
+			Title:     fmt.Sprintf("Subscription %q of company %q ends soon", sub.Name, sub.Company.Name),
+			Detail:    "Ends at " + sub.EndDate.Format(time.RFC3339),
+		}
+	}
+	return alerts, nil
+}
+
+// Recipients returns the active recipients for any of the companies
+func (s *Alert) Recipients(db orm.DB, companyIDs ...uuid.UUID) ([]sandpiper.AlertRecipient, error) {
+	var recipients []sandpiper.AlertRecipient
+	err := db.Model(&recipients).
+		Where("active").Where("company_id IN (?)", pg.In(companyIDs)).
+		Order("created_at").Select()
+	if err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// recipientFields are the fields a recipient list can be filtered and sorted by
+var recipientFields = params.Fields{
+	Filter: params.Columns("alert_recipient", "id", "company_id", "channel", "address", "active"),
+	Sort:   params.Columns("alert_recipient", "channel", "address", "created_at"),
+}
+
+// ListRecipients returns alert recipients with filtering and pagination
+func (s *Alert) ListRecipients(db orm.DB, p *params.Params) (recipients []sandpiper.AlertRecipient, err error) {
+	q := db.Model(&recipients).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if err := p.AddFilter(q, recipientFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, recipientFields, "created_at"); err != nil {
+		return nil, err
+	}
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// CreateRecipient adds an alert recipient for an existing company
+func (s *Alert) CreateRecipient(db orm.DB, r *sandpiper.AlertRecipient) (*sandpiper.AlertRecipient, error) {
+	found, err := db.Model((*sandpiper.Company)(nil)).Where("id = ?", r.CompanyID).Exists()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrCompanyNotFound
+	}
+	if err := db.Insert(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteRecipient removes an alert recipient
+func (s *Alert) DeleteRecipient(db orm.DB, id uuid.UUID) error {
+	res, err := db.Model((*sandpiper.AlertRecipient)(nil)).Where("id = ?", id).Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrRecipientNotFound
+	}
+	return nil
+}
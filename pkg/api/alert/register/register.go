@@ -0,0 +1,30 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package alert
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/alert"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	al "github.com/sandpiper-framework/sandpiper/pkg/api/alert/logging"
+	at "github.com/sandpiper-framework/sandpiper/pkg/api/alert/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the alert service to its logger and transport mechanisms
+func Register(db *database.DB, log sandpiper.Logger, v1 *echo.Group, cfg *config.Alerting) {
+	rba := rbac.New(db.Settings.ServerRole)
+	rba.ServerID = db.Settings.ServerID
+	svc := alert.Initialize(db, rba, cfg)
+	if cfg != nil {
+		svc.StartChecks(log) // check the alert rules in the background
+	}
+	ls := al.ServiceLogger(svc, log)
+	at.NewHTTP(ls, v1)
+}
@@ -0,0 +1,92 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package alert
+
+// alert service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/alert/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/notify"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Service represents alert application interface
+type Service interface {
+	List(echo.Context, *params.Params) ([]sandpiper.Alert, error)
+	Check(echo.Context) ([]sandpiper.Alert, error)
+	Recipients(echo.Context, *params.Params) ([]sandpiper.AlertRecipient, error)
+	CreateRecipient(echo.Context, sandpiper.AlertRecipient) (*sandpiper.AlertRecipient, error)
+	DeleteRecipient(echo.Context, uuid.UUID) error
+}
+
+// New creates new alert application service
+func New(db *database.DB, sdb Repository, rbac RBAC, n Notifier, cfg *config.Alerting) *Alert {
+	if cfg == nil {
+		cfg = &config.Alerting{}
+	}
+	return &Alert{db: db.DB, sdb: sdb, rbac: rbac, notifier: n, cfg: cfg}
+}
+
+// Initialize initializes alert application service with defaults
+func Initialize(db *database.DB, rbac RBAC, cfg *config.Alerting) *Alert {
+	var mailer *notify.Mailer
+	if cfg != nil && cfg.SMTP != nil {
+		mailer = &notify.Mailer{
+			Addr:     cfg.SMTP.Addr(),
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.PasswordCode(),
+			From:     cfg.SMTP.From,
+		}
+	}
+	return New(db, pgsql.NewAlert(), rbac, &Delivery{Mailer: mailer}, cfg)
+}
+
+// Alert represents alert application service
+type Alert struct {
+	db       *pg.DB
+	sdb      Repository
+	rbac     RBAC
+	notifier Notifier
+	cfg      *config.Alerting
+	mu       sync.Mutex // one check at a time (background or on request)
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	List(orm.DB, *params.Params) ([]sandpiper.Alert, error)
+	Open(orm.DB) ([]sandpiper.Alert, error)
+	Save(orm.DB, *sandpiper.Alert) error
+	FailedSyncs(orm.DB, string, int) ([]sandpiper.Alert, error)
+	StaleSlices(orm.DB, string, time.Time) ([]sandpiper.Alert, error)
+	ExpiringSubs(orm.DB, string, time.Time, time.Time) ([]sandpiper.Alert, error)
+	Recipients(orm.DB, ...uuid.UUID) ([]sandpiper.AlertRecipient, error)
+	ListRecipients(orm.DB, *params.Params) ([]sandpiper.AlertRecipient, error)
+	CreateRecipient(orm.DB, *sandpiper.AlertRecipient) (*sandpiper.AlertRecipient, error)
+	DeleteRecipient(orm.DB, uuid.UUID) error
+}
+
+// Notifier delivers alert notices
+type Notifier interface {
+	Email(to []string, subject, body string) error
+	Webhook(ctx context.Context, url string, notice *sandpiper.AlertNotice) error
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+	OurServer() *sandpiper.Server
+}
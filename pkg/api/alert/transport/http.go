@@ -0,0 +1,111 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// alert service routing functions
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/alert"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents alert http service
+type HTTP struct {
+	svc alert.Service
+}
+
+// NewHTTP creates new alert http service
+func NewHTTP(svc alert.Service, er *echo.Group) {
+	h := HTTP{svc}
+	ar := er.Group("/alerts")
+	ar.GET("", h.list)         // e.g. ?filter=resolved_at:null for open alerts
+	ar.POST("/check", h.check) // check the rules now (instead of waiting for the next interval)
+	ar.GET("/recipients", h.recipients)
+	ar.POST("/recipients", h.createRecipient)
+	ar.DELETE("/recipients/:id", h.deleteRecipient)
+}
+
+// Custom errors
+var (
+	ErrInvalidRecipientUUID = echo.NewHTTPError(http.StatusBadRequest, "invalid alert recipient uuid")
+)
+
+func (h *HTTP) list(c echo.Context) error {
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.List(c, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.AlertPaginated{Alerts: result, Paging: p.Paging})
+}
+
+func (h *HTTP) check(c echo.Context) error {
+	result, err := h.svc.Check(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) recipients(c echo.Context) error {
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.Recipients(c, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.AlertRecipientsPaginated{Recipients: result, Paging: p.Paging})
+}
+
+// Alert recipient create request
+type createReq struct {
+	CompanyID uuid.UUID `json:"company_id" validate:"required"`
+	Channel   string    `json:"channel" validate:"required"` // email or webhook
+	Address   string    `json:"address" validate:"required"` // email address or webhook url
+	Rules     []string  `json:"rules"`                       // empty for all rules
+	Active    *bool     `json:"active"`                      // default true
+}
+
+func (h *HTTP) createRecipient(c echo.Context) error {
+	r := new(createReq)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	active := r.Active == nil || *r.Active
+	result, err := h.svc.CreateRecipient(c, sandpiper.AlertRecipient{
+		ID:        uuid.New(),
+		CompanyID: r.CompanyID,
+		Channel:   r.Channel,
+		Address:   r.Address,
+		Rules:     r.Rules,
+		Active:    active,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *HTTP) deleteRecipient(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRecipientUUID
+	}
+	if err := h.svc.DeleteRecipient(c, id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
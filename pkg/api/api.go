@@ -10,7 +10,10 @@ import (
 	"github.com/sandpiper-framework/sandpiper/pkg/api/web"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/health"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/metrics"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/middleware/jwt"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/middleware/session"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/server"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/zlog"
@@ -18,12 +21,20 @@ import (
 	// One import for each service to register (with identifying alias).
 	// Must use a register subdirectory to avoid "import cycle" errors.
 	ac "github.com/sandpiper-framework/sandpiper/pkg/api/activity/register"
+	al "github.com/sandpiper-framework/sandpiper/pkg/api/alert/register"
+	ad "github.com/sandpiper-framework/sandpiper/pkg/api/audit/register"
 	au "github.com/sandpiper-framework/sandpiper/pkg/api/auth/register"
 	co "github.com/sandpiper-framework/sandpiper/pkg/api/company/register"
 	gr "github.com/sandpiper-framework/sandpiper/pkg/api/grain/register"
 	pa "github.com/sandpiper-framework/sandpiper/pkg/api/password/register"
+	pu "github.com/sandpiper-framework/sandpiper/pkg/api/purge/register"
+	re "github.com/sandpiper-framework/sandpiper/pkg/api/release/register"
+	rq "github.com/sandpiper-framework/sandpiper/pkg/api/request/register"
+	ru "github.com/sandpiper-framework/sandpiper/pkg/api/rule/register"
+	sr "github.com/sandpiper-framework/sandpiper/pkg/api/search/register"
 	se "github.com/sandpiper-framework/sandpiper/pkg/api/setting/register"
 	sl "github.com/sandpiper-framework/sandpiper/pkg/api/slice/register"
+	st "github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/register"
 	su "github.com/sandpiper-framework/sandpiper/pkg/api/subscription/register"
 	sy "github.com/sandpiper-framework/sandpiper/pkg/api/sync/register"
 	ta "github.com/sandpiper-framework/sandpiper/pkg/api/tag/register"
@@ -41,18 +52,42 @@ func Start(cfg *config.Configuration) error {
 
 	// setup token, security and logging available for all services
 	sec := secure.New(cfg.App.MinPasswordStr, cfg.Server.APIKeySecretCode())
+	kr, err := secure.NewKeyring(cfg.Encryption.MasterKeys())
+	if err != nil {
+		return err
+	}
 	tok, err := jwt.New(cfg.JWT.SecretKey(), cfg.JWT.SigningAlgorithm, cfg.JWT.Duration, cfg.JWT.MinSecretLength)
 	if err != nil {
 		return err
 	}
 	log := zlog.New(cfg.App.ServiceLogging)
+	expiry := cfg.Server.ExpiryInterval() // how often to deactivate expired subscriptions
 
 	// setup echo server (singleton)
 	srv := server.New()
 
+	// request, database and sync metrics (only exposed if a scraper token is configured)
+	srv.Use(metrics.Middleware())
+	db.Metrics(metrics.Default)
+	token := cfg.Server.MetricsTokenCode()
+	if token != "" {
+		srv.GET("/metrics", metrics.Handler(metrics.Default, token, log))
+	}
+
+	// liveness and readiness for orchestrators and monitoring (the disk check covers the retention
+	// archive, the only files the server writes, since grain payloads are stored in the database).
+	// Check details are only shown to a caller with the metrics token.
+	hc := health.New()
+	db.Health(hc, cfg.Health.SyncMaxAge())
+	hc.Add("disk", health.DiskSpace(cfg.Retention.ArchivePath(), cfg.Health.MinFreeBytes()))
+	hc.Register(srv, token)
+
+	// correlate the requests of a sync session (sent by secondary servers)
+	srv.Use(session.Middleware())
+
 	// routing for static files and templates (sign-up screen)
 	// todo: create a "WebServer" service and pass in db, log, config, etc.
-	web.FileServer(srv)
+	web.FileServer(srv, db)
 
 	// create version group using token authentication middleware
 	v1 := srv.Group("/v1")
@@ -61,13 +96,21 @@ func Start(cfg *config.Configuration) error {
 	// register each service (using proper import alias)
 	au.Register(db, sec, log, srv, tok, tok.MWFunc()) // auth service (no version group)
 	ac.Register(db, sec, log, v1)                     // activity service
+	ad.Register(db, log, v1)                          // audit trail service
+	al.Register(db, log, v1, cfg.Alerting)            // alerting service
 	co.Register(db, sec, log, v1)                     // company service
-	gr.Register(db, sec, log, v1)                     // grain service
+	gr.Register(db, sec, log, v1, cfg.Validation, kr) // grain service
 	pa.Register(db, sec, log, v1)                     // password service
+	pu.Register(db, log, v1, cfg.Retention)           // purge (retention) service
+	re.Register(db, log, v1)                          // release service
+	rq.Register(db, sec, log, v1)                     // subscription request service
+	ru.Register(db, log, v1)                          // subscription rule service
+	sr.Register(db, log, v1)                          // search service
 	se.Register(db, sec, log, v1)                     // setting service
-	sl.Register(db, sec, log, v1)                     // slice service
-	su.Register(db, sec, log, v1)                     // subscription service
-	sy.Register(db, sec, log, v1)                     // sync (exchange) service
+	sl.Register(db, sec, log, v1, kr)                 // slice service
+	st.Register(db, log, v1, cfg.Validation)          // slice-type service
+	su.Register(db, sec, log, v1, expiry)             // subscription service
+	sy.Register(db, sec, log, v1, kr)                 // sync (exchange) service
 	ta.Register(db, sec, log, v1)                     // tagging service
 	us.Register(db, sec, log, v1)                     // user service
 
@@ -0,0 +1,31 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package audit contains services for the audit trail of administrative changes. Each create,
+// update and delete of a company, user, slice, grain, subscription, tag or setting records the
+// actor, source ip and the resource before and after the change (see pgsql.Record).
+package audit
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// List returns audit entries (newest first) if administrator
+func (s *Audit) List(c echo.Context, p *params.Params) ([]sandpiper.AuditEntry, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.sdb.List(s.db, p)
+}
+
+// View returns a single audit entry if administrator
+func (s *Audit) View(c echo.Context, id int) (*sandpiper.AuditEntry, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.sdb.View(s.db, id)
+}
@@ -0,0 +1,65 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package audit
+
+// audit service logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/audit"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the audit service
+func ServiceLogger(svc audit.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents audit logging service
+type LogService struct {
+	audit.Service
+	logger sandpiper.Logger
+}
+
+const source = "audit"
+
+// List logging
+func (ls *LogService) List(c echo.Context, req *params.Params) (resp []sandpiper.AuditEntry, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List audit request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": fmt.Sprintf("Count: %d", len(resp)),
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.List(c, req)
+}
+
+// View logging
+func (ls *LogService) View(c echo.Context, req int) (resp *sandpiper.AuditEntry, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "View audit request", err,
+			map[string]interface{}{
+				"req":  req,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.View(c, req)
+}
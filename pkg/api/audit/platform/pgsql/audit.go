@@ -0,0 +1,125 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// audit service database access
+
+// Other services call Record with the same orm.DB (usually a transaction) used for the change,
+// so a change is never saved without its audit entry.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Custom errors
+var (
+	ErrEntryNotFound = echo.NewHTTPError(http.StatusNotFound, "Audit entry not found.")
+)
+
+// redacted are snapshot keys never stored (secrets and grain payloads)
+var redacted = []string{"password", "token", "sync_api_key", "payload"}
+
+// Audit represents the client for audit_log table
+type Audit struct{}
+
+// NewAudit returns a new audit database instance
+func NewAudit() *Audit {
+	return &Audit{}
+}
+
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("audit_entry", "id", "user_id", "username", "company_id", "action", "resource",
+		"resource_id", "source_ip", "created_at"),
+	Sort: params.Columns("audit_entry", "id", "username", "action", "resource", "created_at"),
+}
+
+// List returns audit entries (newest first) with filtering and pagination
+func (s *Audit) List(db orm.DB, p *params.Params) (entries []sandpiper.AuditEntry, err error) {
+	q := db.Model(&entries).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "id DESC"); err != nil {
+		return nil, err
+	}
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// View returns a single audit entry by id
+func (s *Audit) View(db orm.DB, id int) (*sandpiper.AuditEntry, error) {
+	entry := &sandpiper.AuditEntry{ID: id}
+	err := db.Model(entry).WherePK().Select()
+	if err == pg.ErrNoRows {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Record saves an audit entry for a change made by the current user (from the request context).
+// Before is nil for a create and after is nil for a delete.
+func Record(db orm.DB, c echo.Context, action, resource string, id interface{}, before, after interface{}) error {
+	entry := &sandpiper.AuditEntry{
+		Action:     action,
+		Resource:   resource,
+		ResourceID: fmt.Sprint(id),
+		SourceIP:   c.RealIP(),
+	}
+	// actor (as set by the jwt middleware)
+	entry.UserID, _ = c.Get("id").(int)
+	entry.Username, _ = c.Get("username").(string)
+	entry.CompanyID, _ = c.Get("company_id").(uuid.UUID)
+
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return err
+	}
+	return db.Insert(entry)
+}
+
+// snapshot converts a resource to its json representation (without redacted keys)
+func snapshot(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for _, key := range redacted {
+		if _, ok := m[key]; ok {
+			m[key] = "[redacted]"
+		}
+	}
+	return m, nil
+}
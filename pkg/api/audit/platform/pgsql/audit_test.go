@@ -0,0 +1,52 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+func TestSnapshot(t *testing.T) {
+	cases := []struct {
+		name string
+		v    interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "Nil",
+			v:    nil,
+			want: nil,
+		},
+		{
+			name: "Nil pointer",
+			v:    (*sandpiper.Tag)(nil),
+			want: nil,
+		},
+		{
+			name: "Secrets redacted",
+			v: map[string]interface{}{
+				"name":         "acme",
+				"sync_api_key": "abc123",
+				"payload":      "H4sIAAAAAAAA",
+			},
+			want: map[string]interface{}{
+				"name":         "acme",
+				"sync_api_key": "[redacted]",
+				"payload":      "[redacted]",
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := snapshot(tt.v)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
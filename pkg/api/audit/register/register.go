@@ -0,0 +1,24 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package audit
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/audit"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	al "github.com/sandpiper-framework/sandpiper/pkg/api/audit/logging"
+	at "github.com/sandpiper-framework/sandpiper/pkg/api/audit/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the audit service to its logger and transport mechanisms
+func Register(db *database.DB, log sandpiper.Logger, v1 *echo.Group) {
+	svc := audit.Initialize(db, rbac.New(db.Settings.ServerRole))
+	ls := al.ServiceLogger(svc, log)
+	at.NewHTTP(ls, v1)
+}
@@ -0,0 +1,52 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package audit
+
+// audit service
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Service represents audit application interface (entries are only added by other services)
+type Service interface {
+	List(echo.Context, *params.Params) ([]sandpiper.AuditEntry, error)
+	View(echo.Context, int) (*sandpiper.AuditEntry, error)
+}
+
+// New creates new audit application service
+func New(db *database.DB, sdb Repository, rbac RBAC) *Audit {
+	return &Audit{db: db.DB, sdb: sdb, rbac: rbac}
+}
+
+// Initialize initializes audit application service with defaults
+func Initialize(db *database.DB, rbac RBAC) *Audit {
+	return New(db, pgsql.NewAudit(), rbac)
+}
+
+// Audit represents audit application service
+type Audit struct {
+	db   *pg.DB
+	sdb  Repository
+	rbac RBAC
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	List(orm.DB, *params.Params) ([]sandpiper.AuditEntry, error)
+	View(orm.DB, int) (*sandpiper.AuditEntry, error)
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+}
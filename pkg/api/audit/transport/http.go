@@ -0,0 +1,61 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// audit service routing functions
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/audit"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents audit http service
+type HTTP struct {
+	svc audit.Service
+}
+
+// NewHTTP creates new audit http service
+func NewHTTP(svc audit.Service, er *echo.Group) {
+	h := HTTP{svc}
+	ar := er.Group("/audit")
+	ar.GET("", h.list) // e.g. ?filter=resource:company,action:delete,created_at:ge:2020-01-01
+	ar.GET("/:id", h.view)
+}
+
+// Custom errors
+var (
+	// ErrInvalidID indicates a malformed id
+	ErrInvalidID = echo.NewHTTPError(http.StatusBadRequest, "Invalid numeric audit id")
+)
+
+func (h *HTTP) list(c echo.Context) error {
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.List(c, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.AuditPaginated{Entries: result, Paging: p.Paging})
+}
+
+func (h *HTTP) view(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return ErrInvalidID
+	}
+	result, err := h.svc.View(c, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
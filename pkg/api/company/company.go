@@ -8,19 +8,34 @@
 package company
 
 import (
+	"github.com/go-pg/pg/v9"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
+// resource identifies companies in the audit trail
+const resource = "company"
+
 // Create adds a new company if administrator
 func (s *Company) Create(c echo.Context, req sandpiper.Company) (*sandpiper.Company, error) {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
-	return s.sdb.Create(s.db, req)
+	var company *sandpiper.Company
+	err := s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if company, err = s.sdb.Create(tx, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, company.ID, nil, company)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return company, nil
 }
 
 // List returns list of companies that you can view
@@ -49,7 +64,12 @@ func (s *Company) Delete(c echo.Context, id uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	return s.sdb.Delete(s.db, company)
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Delete(tx, company); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, id, company, nil)
+	})
 }
 
 // Update contains company field request used for updating
@@ -67,6 +87,10 @@ func (s *Company) Update(c echo.Context, r *Update) (*sandpiper.Company, error)
 	if err := s.rbac.EnforceCompany(c, r.ID); err != nil {
 		return nil, err
 	}
+	before, err := s.sdb.View(s.db, r.ID)
+	if err != nil {
+		return nil, err
+	}
 	company := &sandpiper.Company{
 		ID:         r.ID,
 		Name:       r.Name,
@@ -75,11 +99,20 @@ func (s *Company) Update(c echo.Context, r *Update) (*sandpiper.Company, error)
 		SyncUserID: r.SyncUserID,
 		Active:     r.Active,
 	}
-	err := s.sdb.Update(s.db, company)
+	var after *sandpiper.Company
+	err = s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if err := s.sdb.Update(tx, company); err != nil {
+			return err
+		}
+		if after, err = s.sdb.View(tx, r.ID); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, r.ID, before, after)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return s.sdb.View(s.db, r.ID)
+	return after, nil
 }
 
 // Server returns a single server (company) that you can sync (if  admin)
@@ -56,6 +56,13 @@ func (s *Company) View(db orm.DB, id uuid.UUID) (*sandpiper.Company, error) {
 	return company, nil
 }
 
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("company", "id", "name", "sync_addr", "active", "created_at",
+		"updated_at"),
+	Sort: params.Columns("company", "name", "sync_addr", "active", "created_at"),
+}
+
 // List returns list of all companies
 func (s *Company) List(db orm.DB, sc *sandpiper.Scope, p *params.Params) (companies []sandpiper.Company, err error) {
 
@@ -63,11 +70,15 @@ func (s *Company) List(db orm.DB, sc *sandpiper.Scope, p *params.Params) (compan
 	if p.WantRelated("users") {
 		q.Relation("Users")
 	}
-	p.AddFilter(q)
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
 	if sc != nil {
 		q.Where(sc.Condition, sc.ID)
 	}
-	p.AddSort(q, "name")
+	if err := p.AddSort(q, listFields, "name"); err != nil {
+		return nil, err
+	}
 	q.Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
 
 	p.Paging.Count, err = q.SelectAndCount()
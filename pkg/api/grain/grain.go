@@ -7,29 +7,84 @@
 package grain
 
 import (
+	"net/http"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/validate"
+)
+
+// resources identifying grains in the audit trail (bulk loads are recorded once per slice)
+const (
+	resource      = "grain"
+	resourceSlice = "slice_grains"
 )
 
 // Create makes a new grain to hold our syncable data-objects. Must be a sandpiper admin.
+// The payload must pass any validation for the slice type.
 func (s *Grain) Create(c echo.Context, replaceFlag bool, req *sandpiper.Grain) (*sandpiper.Grain, error) {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
-	return s.sdb.Create(s.db, replaceFlag, req)
+	slice, err := s.sdb.Slice(s.db, *req.SliceID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.sdb.SliceType(s.db, slice.SliceType)
+	if err != nil {
+		return nil, err
+	}
+	if problems := s.validate(st.Validator, req); len(problems) > 0 {
+		return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+			"message": "Grain payload failed " + slice.SliceType + " validation",
+			"errors":  problems,
+		})
+	}
+	var grain *sandpiper.Grain
+	err = s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if replaceFlag {
+			// a replaced grain is recorded as its own delete
+			old, err := s.sdb.ViewByKeys(tx, *req.SliceID, strings.ToLower(req.Key), false)
+			if err != nil {
+				return err
+			}
+			if old.ID != uuid.Nil {
+				if err := auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, old.ID, old, nil); err != nil {
+					return err
+				}
+			}
+		}
+		if grain, err = s.sdb.Create(tx, replaceFlag, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, grain.ID, nil, grain)
+	})
+	if err != nil {
+		return nil, err
+	}
+	grainsWritten.With(slice.SliceType, "create").Inc()
+	return grain, nil
 }
 
 // View returns a single grain if allowed
 func (s *Grain) View(c echo.Context, grainID uuid.UUID) (*sandpiper.Grain, error) {
-	au := s.rbac.CurrentUser(c)
-	if !au.AtLeast(sandpiper.AdminRole) {
-		// make sure the grain is subscribed to this user's company
-		if !s.sdb.CompanySubscribed(s.db, au.CompanyID, grainID) {
-			return nil, echo.ErrForbidden
-		}
+	if err := s.enforceSubscribed(c, grainID); err != nil {
+		return nil, err
+	}
+	return s.sdb.View(s.db, grainID)
+}
+
+// Payload returns a single grain (with payload) for streaming its decoded content. It uses
+// the same authorization as View, but is a separate method so downloads are logged as such.
+func (s *Grain) Payload(c echo.Context, grainID uuid.UUID) (*sandpiper.Grain, error) {
+	if err := s.enforceSubscribed(c, grainID); err != nil {
+		return nil, err
 	}
 	return s.sdb.View(s.db, grainID)
 }
@@ -49,16 +104,27 @@ func (s *Grain) List(c echo.Context, payload bool, p *params.Params) ([]sandpipe
 	if err != nil {
 		return nil, err
 	}
-	return s.sdb.List(s.db, uuid.Nil, payload, q, p)
+	return s.sdb.List(s.db, uuid.Nil, payload, nil, q, p)
 }
 
-// ListBySlice returns a list of grains for a slice scoped by user
-func (s *Grain) ListBySlice(c echo.Context, sliceID uuid.UUID, payloadFlag bool, p *params.Params) ([]sandpiper.Grain, error) {
+// ListBySlice returns a list of grains for a slice scoped by user (optionally by grain key)
+func (s *Grain) ListBySlice(c echo.Context, sliceID uuid.UUID, payloadFlag bool, kq *params.KeyQuery, p *params.Params) ([]sandpiper.Grain, error) {
 	q, err := s.rbac.EnforceScope(c)
 	if err != nil {
 		return nil, err
 	}
-	return s.sdb.List(s.db, sliceID, payloadFlag, q, p)
+	return s.sdb.List(s.db, sliceID, payloadFlag, kq, q, p)
+}
+
+// Digests returns every grain in a slice with a hash of its payload (admin function only)
+func (s *Grain) Digests(c echo.Context, sliceID uuid.UUID) ([]sandpiper.GrainDigest, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if _, err := s.sdb.Slice(s.db, sliceID); err != nil {
+		return nil, err
+	}
+	return s.sdb.Digests(s.db, sliceID)
 }
 
 // Delete deletes a grain by id, if allowed
@@ -66,5 +132,55 @@ func (s *Grain) Delete(c echo.Context, id uuid.UUID) error {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return err
 	}
-	return s.sdb.Delete(s.db, id)
+	grain, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return err
+	}
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Delete(tx, id); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, id, grain, nil)
+	})
+}
+
+// validate checks the decoded grain payload using the validation plugin of the slice type
+func (s *Grain) validate(validator string, grain *sandpiper.Grain) []string {
+	return s.validateBatch(validator, []*sandpiper.Grain{grain})[0]
+}
+
+// validateBatch checks several grains at once (so an external validator only runs once),
+// returning the problems found for each grain (in the same order)
+func (s *Grain) validateBatch(validator string, grains []*sandpiper.Grain) [][]string {
+	problems := make([][]string, len(grains))
+	contents := make([]validate.Content, 0, len(grains))
+	index := make([]int, 0, len(grains)) // grain for each content
+	for i, grain := range grains {
+		data, err := grain.Payload.Decode(grain.Encoding)
+		if err != nil {
+			problems[i] = []string{"unable to decode payload: " + err.Error()}
+			continue
+		}
+		contents = append(contents, validate.Content{
+			Key:    grain.Key,
+			Source: grain.Source,
+			Data:   []byte(data),
+		})
+		index = append(index, i)
+	}
+	for j, p := range s.val.ValidateBatch(validator, contents) {
+		problems[index[j]] = p
+	}
+	return problems
+}
+
+// enforceSubscribed makes sure a non-admin user's company subscribes to the grain's slice
+func (s *Grain) enforceSubscribed(c echo.Context, grainID uuid.UUID) error {
+	au := s.rbac.CurrentUser(c)
+	if !au.AtLeast(sandpiper.AdminRole) {
+		if !s.sdb.CompanySubscribed(s.db, au.CompanyID, grainID) {
+			return echo.ErrForbidden
+		}
+	}
+	return nil
 }
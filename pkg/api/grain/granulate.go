@@ -0,0 +1,227 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package grain
+
+// granulation of a document (e.g. an ACES or PIES file) into item-level grains
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/granulate"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+)
+
+// item grains are stored without encoding so payloads can be compared by hash (see pgsql.KeyHashes)
+const itemEncoding = "raw"
+
+// Custom errors
+var (
+	// ErrNotGranular indicates the slice type does not support granulation
+	ErrNotGranular = echo.NewHTTPError(http.StatusBadRequest, "Slice type does not support granulation")
+
+	// ErrMixedFormats indicates the slices cannot be split from the same document
+	ErrMixedFormats = echo.NewHTTPError(http.StatusBadRequest, "Slices must use the same document format with different slice types")
+)
+
+// Granulate splits a document into item-level grains for one or more slices (each slice receiving
+// the items for its slice type, e.g. a full PIES file can fill "pies-items", "pies-marketcopy" and
+// "pies-pricesheet" slices). Only new or changed items are written and items no longer in the
+// document are removed. Document metadata (e.g. header and footer) replaces the slice metadata for
+// the format. Everything is done in a single transaction that also refreshes the slice content
+// (with the slices locked until it is done).
+func (s *Grain) Granulate(c echo.Context, sliceIDs []uuid.UUID, source string, r io.Reader) ([]sandpiper.Granulation, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+
+	// all slices must be granular with the same format (and only one slice for each granulator)
+	var format granulate.Format
+	slices := make([]*sandpiper.Slice, len(sliceIDs))
+	results := make([]sandpiper.Granulation, len(sliceIDs))
+	granulators := make([]string, len(sliceIDs))
+	used := make(map[string]bool)
+	for i, sliceID := range sliceIDs {
+		slice, err := s.sdb.Slice(s.db, sliceID)
+		if err != nil {
+			return nil, err
+		}
+		st, err := s.sdb.SliceType(s.db, slice.SliceType)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := granulate.Lookup(st.Granulator)
+		if !ok {
+			return nil, ErrNotGranular
+		}
+		if (i > 0 && f.MetaPrefix != format.MetaPrefix) || used[st.Granulator] {
+			return nil, ErrMixedFormats
+		}
+		format = f
+		slices[i] = slice
+		used[st.Granulator] = true
+		granulators[i] = st.Granulator
+		results[i] = sandpiper.Granulation{SliceID: sliceID, SliceType: slice.SliceType, Source: source}
+	}
+	if len(results) == 0 {
+		return nil, ErrNotGranular
+	}
+
+	err := s.locked(slices, func() error {
+		return s.db.RunInTransaction(func(tx *pg.Tx) error {
+			if err := s.granulate(tx, results, granulators, format, r); err != nil {
+				return err
+			}
+			for _, res := range results {
+				if err := auditsvc.Record(tx, c, sandpiper.AuditUpdate, resourceSlice, res.SliceID, nil, res); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		var fe *granulate.FormatError
+		if errors.As(err, &fe) {
+			return nil, echo.NewHTTPError(http.StatusUnprocessableEntity, "Invalid document: "+fe.Error())
+		}
+		return nil, err
+	}
+	for _, res := range results {
+		grainsWritten.With(res.SliceType, "granulate").Add(float64(res.Added))
+	}
+	return results, nil
+}
+
+// granulate parses the document once, passing each item to the differ for its granulator (the
+// slice type named by the item)
+func (s *Grain) granulate(tx orm.DB, results []sandpiper.Granulation, granulators []string, format granulate.Format, r io.Reader) error {
+	differs := make(map[string]*differ, len(results))
+	for i := range results {
+		d, err := s.newDiffer(tx, &results[i])
+		if err != nil {
+			return err
+		}
+		differs[granulators[i]] = d
+	}
+
+	meta, err := format.Parse(r, func(item granulate.Item) error {
+		if d, ok := differs[item.SliceType]; ok {
+			return d.add(item)
+		}
+		return nil // not requested
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range differs {
+		if err := d.finish(format.MetaPrefix, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// differ compares items to the grains in one slice, writing any differences in batches
+type differ struct {
+	s       *Grain
+	tx      orm.DB
+	result  *sandpiper.Granulation
+	current map[string]string // grain payload hashes by grain key (removed as items are found)
+	changed []string          // keys to remove before adding the batch (because of the unique key)
+	batch   []sandpiper.Grain
+}
+
+func (s *Grain) newDiffer(tx orm.DB, result *sandpiper.Granulation) (*differ, error) {
+	current, err := s.sdb.KeyHashes(tx, result.SliceID)
+	if err != nil {
+		return nil, err
+	}
+	return &differ{
+		s:       s,
+		tx:      tx,
+		result:  result,
+		current: current,
+		batch:   make([]sandpiper.Grain, 0, importBatchSize),
+	}, nil
+}
+
+// add includes an item if it is new or changed
+func (d *differ) add(item granulate.Item) error {
+	key := strings.ToLower(item.Key)
+	d.result.Items++
+
+	hash, found := d.current[key]
+	delete(d.current, key) // whatever is left over will be removed
+	if found {
+		if hash == fmt.Sprintf("%x", md5.Sum(item.Data)) {
+			d.result.Unchanged++
+			return nil
+		}
+		d.changed = append(d.changed, key)
+	}
+
+	d.batch = append(d.batch, sandpiper.Grain{
+		ID:       uuid.New(),
+		SliceID:  &d.result.SliceID,
+		Key:      key,
+		Source:   d.result.Source,
+		Encoding: itemEncoding,
+		Payload:  payload.PayloadData(item.Data),
+	})
+	if len(d.batch) == importBatchSize {
+		return d.flush()
+	}
+	return nil
+}
+
+// flush writes the current batch
+func (d *differ) flush() error {
+	if err := d.s.sdb.DeleteByKeys(d.tx, d.result.SliceID, d.changed); err != nil {
+		return err
+	}
+	if err := d.s.sdb.CreateBatch(d.tx, d.batch); err != nil {
+		return err
+	}
+	d.result.Deleted += len(d.changed)
+	d.result.Added += len(d.batch)
+	d.changed, d.batch = d.changed[:0], d.batch[:0]
+	return nil
+}
+
+// finish writes the last batch, removes grains no longer in the document and updates the slice
+func (d *differ) finish(metaPrefix string, meta sandpiper.MetaMap) error {
+	if err := d.flush(); err != nil {
+		return err
+	}
+
+	removed := make([]string, 0, len(d.current))
+	for key := range d.current {
+		removed = append(removed, key)
+	}
+	if err := d.s.sdb.DeleteByKeys(d.tx, d.result.SliceID, removed); err != nil {
+		return err
+	}
+	d.result.Deleted += len(removed)
+
+	if err := d.s.sdb.ReplaceMetadata(d.tx, d.result.SliceID, metaPrefix, meta); err != nil {
+		return err
+	}
+
+	// update slice content information (hash, count and date)
+	return d.s.sdb.RefreshSlice(d.tx, d.result.SliceID)
+}
@@ -0,0 +1,245 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package grain
+
+// bulk import of grains into a single slice from newline-delimited json (one grain per line)
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+)
+
+const (
+	// importBatchSize is the number of grains sent to the database in each multi-row insert
+	importBatchSize = 100
+
+	// maxImportErrors limits the line errors returned (all errors are still counted)
+	maxImportErrors = 100
+)
+
+// ErrImportRejected indicates at least one line could not be imported (so nothing was imported)
+var ErrImportRejected = echo.NewHTTPError(http.StatusUnprocessableEntity, "Bulk import rejected (see line errors)")
+
+// importLine is one line of a bulk import
+type importLine struct {
+	ID       uuid.UUID           `json:"id"` // optional
+	SliceID  uuid.UUID           `json:"slice_id"`
+	Key      string              `json:"grain_key"`
+	Source   string              `json:"source"`
+	Encoding string              `json:"encoding"`
+	Payload  payload.PayloadData `json:"payload"`
+}
+
+// Import adds grains to a slice from a newline-delimited json stream inside a single transaction.
+// All lines must be valid or nothing is imported. The replaceFlag removes all existing grains in
+// the slice first. The slice content information is refreshed as part of the same transaction.
+func (s *Grain) Import(c echo.Context, sliceID uuid.UUID, replaceFlag bool, r io.Reader) (*sandpiper.GrainImport, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	slice, err := s.sdb.Slice(s.db, sliceID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.sdb.SliceType(s.db, slice.SliceType)
+	if err != nil {
+		return nil, err
+	}
+	result := &sandpiper.GrainImport{SliceID: sliceID, Replaced: replaceFlag}
+	err = s.locked([]*sandpiper.Slice{slice}, func() error {
+		return s.db.RunInTransaction(func(tx *pg.Tx) error {
+			if err := s.importGrains(tx, result, st.Validator, r); err != nil {
+				return err
+			}
+			return auditsvc.Record(tx, c, sandpiper.AuditCreate, resourceSlice, sliceID, nil, result)
+		})
+	})
+	if err != nil {
+		result.Added = 0 // rolled back
+		return result, err
+	}
+	grainsWritten.With(slice.SliceType, "import").Add(float64(result.Added))
+	return result, nil
+}
+
+// locked runs fn with the slices locked (the same lock, refresh, unlock used by the `add` command)
+// so a sync can't start while their content is changing. Slices that were already locked are left
+// locked for whoever locked them.
+func (s *Grain) locked(slices []*sandpiper.Slice, fn func() error) (err error) {
+	var unlock []uuid.UUID
+	defer func() {
+		for _, id := range unlock {
+			if e := s.sdb.UnlockSlice(s.db, id); e != nil && err == nil {
+				err = e
+			}
+		}
+	}()
+	for _, slice := range slices {
+		if !slice.AllowSync {
+			continue
+		}
+		if err := s.sdb.LockSlice(s.db, slice.ID); err != nil {
+			return err
+		}
+		unlock = append(unlock, slice.ID)
+	}
+	return fn()
+}
+
+// importGrains reads, validates (for the slice type) and inserts (in batches) grains from the reader
+func (s *Grain) importGrains(tx orm.DB, result *sandpiper.GrainImport, validator string, r io.Reader) error {
+	// keep track of grain keys to reject duplicates (in the stream or the existing slice)
+	keys := make(map[string]bool)
+
+	if result.Replaced {
+		if err := s.sdb.DeleteBySlice(tx, result.SliceID); err != nil {
+			return err
+		}
+	} else {
+		existing, err := s.sdb.Keys(tx, result.SliceID)
+		if err != nil {
+			return err
+		}
+		for _, k := range existing {
+			keys[k] = true
+		}
+	}
+
+	batch := make([]sandpiper.Grain, 0, importBatchSize)
+	flush := func() error {
+		// stop writing after the first error (the transaction will be rolled back anyway)
+		if len(batch) > 0 && result.Failed == 0 {
+			if err := s.sdb.CreateBatch(tx, batch); err != nil {
+				return err
+			}
+			result.Added += len(batch)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	// lines are parsed as they are read, but validated (and added) a batch at a time
+	type pending struct {
+		line  int
+		grain *sandpiper.Grain
+		err   error
+	}
+	lines := make([]pending, 0, importBatchSize)
+	check := func() error {
+		grains := make([]*sandpiper.Grain, 0, len(lines))
+		for _, l := range lines {
+			if l.err == nil {
+				grains = append(grains, l.grain)
+			}
+		}
+		problems := s.validateBatch(validator, grains)
+		for _, l := range lines {
+			err := l.err
+			if err == nil {
+				p := problems[0]
+				problems = problems[1:]
+				if keys[l.grain.Key] {
+					err = errors.New("duplicate grain_key")
+				} else if len(p) > 0 {
+					err = errors.New(strings.Join(p, "; "))
+				}
+			}
+			if err != nil {
+				addImportError(result, l.line, l.grain, err)
+				continue
+			}
+			keys[l.grain.Key] = true
+			batch = append(batch, *l.grain)
+		}
+		lines = lines[:0]
+		return flush()
+	}
+
+	// use a bufio.Reader (rather than a Scanner) to avoid a maximum line length
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		b, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			result.Lines++
+			grain, err := parseImportLine(b, result.SliceID)
+			lines = append(lines, pending{line: line, grain: grain, err: err})
+			if len(lines) == importBatchSize {
+				if err := check(); err != nil {
+					return err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if err := check(); err != nil {
+		return err
+	}
+
+	if result.Failed > 0 {
+		return ErrImportRejected
+	}
+
+	// update slice content information (hash, count and date)
+	return s.sdb.RefreshSlice(tx, result.SliceID)
+}
+
+// parseImportLine converts one json line to a grain (returning a partial grain with any error)
+func parseImportLine(b []byte, sliceID uuid.UUID) (*sandpiper.Grain, error) {
+	var r importLine
+
+	if err := json.Unmarshal(b, &r); err != nil {
+		return &sandpiper.Grain{}, fmt.Errorf("invalid json: %v", err)
+	}
+	grain := &sandpiper.Grain{
+		ID:       r.ID,
+		SliceID:  &sliceID,
+		Key:      strings.ToLower(r.Key), // keys are always lowercase (see pgsql.Create)
+		Source:   r.Source,
+		Encoding: r.Encoding,
+		Payload:  r.Payload,
+	}
+	switch {
+	case r.SliceID != uuid.Nil && r.SliceID != sliceID:
+		return grain, errors.New("slice_id does not match import slice")
+	case grain.Key == "":
+		return grain, errors.New("grain_key is required")
+	case !payload.ValidEncoding(grain.Encoding):
+		return grain, fmt.Errorf("invalid encoding \"%s\"", grain.Encoding)
+	case grain.Payload == payload.Nil:
+		return grain, errors.New("payload is required")
+	}
+	if grain.ID == uuid.Nil {
+		grain.ID = uuid.New()
+	}
+	return grain, nil
+}
+
+// addImportError records a line error (limiting the number returned)
+func addImportError(result *sandpiper.GrainImport, line int, grain *sandpiper.Grain, err error) {
+	result.Failed++
+	if len(result.Errors) < maxImportErrors {
+		result.Errors = append(result.Errors, sandpiper.GrainImportError{Line: line, Key: grain.Key, Error: err.Error()})
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package grain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/validate"
+)
+
+// importRepo records the repository calls made by an import
+type importRepo struct {
+	Repository
+	existing  []string
+	deleted   bool
+	batches   [][]sandpiper.Grain
+	refreshed bool
+	locked    []uuid.UUID
+	unlocked  []uuid.UUID
+}
+
+func (r *importRepo) Keys(orm.DB, uuid.UUID) ([]string, error) {
+	return r.existing, nil
+}
+
+func (r *importRepo) DeleteBySlice(orm.DB, uuid.UUID) error {
+	r.deleted = true
+	return nil
+}
+
+func (r *importRepo) CreateBatch(db orm.DB, grains []sandpiper.Grain) error {
+	r.batches = append(r.batches, append([]sandpiper.Grain(nil), grains...))
+	return nil
+}
+
+func (r *importRepo) RefreshSlice(orm.DB, uuid.UUID) error {
+	r.refreshed = true
+	return nil
+}
+
+func (r *importRepo) LockSlice(db orm.DB, id uuid.UUID) error {
+	r.locked = append(r.locked, id)
+	return nil
+}
+
+func (r *importRepo) UnlockSlice(db orm.DB, id uuid.UUID) error {
+	r.unlocked = append(r.unlocked, id)
+	return nil
+}
+
+// rootValidator rejects payloads that don't start with "<App" (counting the batches validated)
+type rootValidator struct {
+	batches int
+}
+
+func (v *rootValidator) Validate(name string, c validate.Content) []string {
+	return v.ValidateBatch(name, []validate.Content{c})[0]
+}
+
+func (v *rootValidator) ValidateBatch(name string, cs []validate.Content) [][]string {
+	v.batches++
+	problems := make([][]string, len(cs))
+	for i, c := range cs {
+		if !strings.HasPrefix(string(c.Data), "<App") {
+			problems[i] = []string{"not an App"}
+		}
+	}
+	return problems
+}
+
+var importSlice = uuid.MustParse("10000000-0000-0000-0000-000000000000")
+
+func importLines(keys ...string) string {
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, `{"grain_key":"%s","encoding":"raw","payload":"<App>%s</App>"}`+"\n", k, k)
+	}
+	return b.String()
+}
+
+func TestParseImportLine(t *testing.T) {
+	other := uuid.MustParse("20000000-0000-0000-0000-000000000000")
+	cases := []struct {
+		name    string
+		line    string
+		wantKey string
+		wantErr string
+	}{
+		{
+			name:    "Success (key is lowercase)",
+			line:    `{"grain_key":"App:1","source":"aces.xml","encoding":"raw","payload":"<App/>"}`,
+			wantKey: "app:1",
+		},
+		{
+			name:    "Matching slice_id",
+			line:    `{"slice_id":"` + importSlice.String() + `","grain_key":"a","encoding":"raw","payload":"x"}`,
+			wantKey: "a",
+		},
+		{
+			name:    "Invalid json",
+			line:    `{"grain_key":"a",`,
+			wantErr: "invalid json",
+		},
+		{
+			name:    "Other slice_id",
+			line:    `{"slice_id":"` + other.String() + `","grain_key":"a","encoding":"raw","payload":"x"}`,
+			wantKey: "a",
+			wantErr: "slice_id does not match import slice",
+		},
+		{
+			name:    "Missing key",
+			line:    `{"encoding":"raw","payload":"x"}`,
+			wantErr: "grain_key is required",
+		},
+		{
+			name:    "Bad encoding",
+			line:    `{"grain_key":"a","encoding":"zip","payload":"x"}`,
+			wantKey: "a",
+			wantErr: `invalid encoding "zip"`,
+		},
+		{
+			name:    "Missing payload",
+			line:    `{"grain_key":"a","encoding":"raw"}`,
+			wantKey: "a",
+			wantErr: "payload is required",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			grain, err := parseImportLine([]byte(tt.line), importSlice)
+			if tt.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.wantErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, grain.ID)
+				assert.Equal(t, importSlice, *grain.SliceID)
+			}
+			assert.Equal(t, tt.wantKey, grain.Key)
+		})
+	}
+}
+
+func TestImportGrains(t *testing.T) {
+	many := make([]string, 250)
+	for i := range many {
+		many[i] = fmt.Sprintf("k%d", i)
+	}
+	bad := strings.Repeat(`{"grain_key":"a","encoding":"zip","payload":"x"}`+"\n", maxImportErrors+20)
+
+	cases := []struct {
+		name        string
+		existing    []string
+		replace     bool
+		ndjson      string
+		wantErr     error
+		wantAdded   int
+		wantFailed  int
+		wantErrors  []sandpiper.GrainImportError
+		wantBatches int
+		wantChecks  int // validation batches
+	}{
+		{
+			name:        "Success (blank lines ignored)",
+			ndjson:      importLines("a", "b") + "\n  \n" + importLines("c"),
+			wantAdded:   3,
+			wantBatches: 1,
+		},
+		{
+			name:        "Batches",
+			ndjson:      importLines(many...),
+			wantAdded:   250,
+			wantBatches: 3,
+			wantChecks:  3,
+		},
+		{
+			name:       "Invalid payload for the slice type",
+			ndjson:     importLines("a") + `{"grain_key":"b","encoding":"raw","payload":"<Asset/>"}` + "\n" + `{"grain_key":"c","encoding":"b64","payload":"!!"}`,
+			wantErr:    ErrImportRejected,
+			wantFailed: 2,
+			wantErrors: []sandpiper.GrainImportError{
+				{Line: 2, Key: "b", Error: "not an App"},
+				{Line: 3, Key: "c", Error: "unable to decode payload: illegal base64 data at input byte 0"},
+			},
+		},
+		{
+			name:       "Duplicate key in the file",
+			ndjson:     importLines("a", "b", "A"),
+			wantErr:    ErrImportRejected,
+			wantFailed: 1,
+			wantErrors: []sandpiper.GrainImportError{{Line: 3, Key: "a", Error: "duplicate grain_key"}},
+		},
+		{
+			name:       "Duplicate key in the slice",
+			existing:   []string{"b"},
+			ndjson:     importLines("a", "b"),
+			wantErr:    ErrImportRejected,
+			wantFailed: 1,
+			wantErrors: []sandpiper.GrainImportError{{Line: 2, Key: "b", Error: "duplicate grain_key"}},
+		},
+		{
+			name:        "Replace allows existing keys",
+			existing:    []string{"b"},
+			replace:     true,
+			ndjson:      importLines("a", "b"),
+			wantAdded:   2,
+			wantBatches: 1,
+		},
+		{
+			name:       "Bad encoding and slice mismatch",
+			ndjson:     importLines("a") + `{"grain_key":"b","encoding":"zip","payload":"x"}` + "\n" + `{"slice_id":"20000000-0000-0000-0000-000000000000","grain_key":"c","encoding":"raw","payload":"x"}`,
+			wantErr:    ErrImportRejected,
+			wantFailed: 2,
+			wantErrors: []sandpiper.GrainImportError{
+				{Line: 2, Key: "b", Error: `invalid encoding "zip"`},
+				{Line: 3, Key: "c", Error: "slice_id does not match import slice"},
+			},
+		},
+		{
+			name:        "Nothing written after the first error (so the transaction is rolled back)",
+			ndjson:      importLines(many[:150]...) + "not json\n" + importLines(many[150:]...),
+			wantErr:     ErrImportRejected,
+			wantAdded:   100, // the first batch (before the error) is discarded by the rollback
+			wantFailed:  1,
+			wantBatches: 1,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &importRepo{existing: tt.existing}
+			val := &rootValidator{}
+			s := &Grain{sdb: repo, val: val}
+			result := &sandpiper.GrainImport{SliceID: importSlice, Replaced: tt.replace}
+
+			err := s.importGrains(nil, result, "", strings.NewReader(tt.ndjson))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantAdded, result.Added)
+			assert.Equal(t, tt.wantFailed, result.Failed)
+			if tt.wantErrors != nil {
+				assert.Equal(t, tt.wantErrors, result.Errors)
+			}
+			assert.Equal(t, tt.wantBatches, len(repo.batches))
+			if tt.wantChecks > 0 {
+				assert.Equal(t, tt.wantChecks, val.batches)
+			}
+			assert.Equal(t, tt.replace, repo.deleted)
+			assert.Equal(t, tt.wantErr == nil, repo.refreshed)
+		})
+	}
+
+	t.Run("Line errors are capped", func(t *testing.T) {
+		s := &Grain{sdb: &importRepo{}, val: &rootValidator{}}
+		result := &sandpiper.GrainImport{SliceID: importSlice}
+		err := s.importGrains(nil, result, "", strings.NewReader(bad))
+		assert.Equal(t, ErrImportRejected, err)
+		assert.Equal(t, maxImportErrors+20, result.Failed)
+		assert.Equal(t, maxImportErrors, len(result.Errors))
+	})
+}
+
+func TestLocked(t *testing.T) {
+	open := &sandpiper.Slice{ID: importSlice, AllowSync: true}
+	closed := &sandpiper.Slice{ID: uuid.MustParse("20000000-0000-0000-0000-000000000000")}
+
+	repo := &importRepo{}
+	s := &Grain{sdb: repo}
+	err := s.locked([]*sandpiper.Slice{open, closed}, func() error {
+		assert.Equal(t, []uuid.UUID{open.ID}, repo.locked)
+		assert.Empty(t, repo.unlocked)
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, []uuid.UUID{open.ID}, repo.unlocked) // unlocked on error, already locked slice left alone
+}
@@ -8,6 +8,7 @@ package grain
 
 import (
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -66,6 +67,64 @@ func (ls *LogService) Create(c echo.Context, replaceFlag bool, req *sandpiper.Gr
 	return ls.Service.Create(c, replaceFlag, req)
 }
 
+// Import logging
+func (ls *LogService) Import(c echo.Context, sliceID uuid.UUID, replaceFlag bool, r io.Reader) (resp *sandpiper.GrainImport, err error) {
+	defer func(begin time.Time) {
+		var result string
+		if resp != nil {
+			result = fmt.Sprintf("Lines: %d, Added: %d, Failed: %d", resp.Lines, resp.Added, resp.Failed)
+		}
+		ls.logger.Log(
+			c,
+			source, "Import grains request", err,
+			map[string]interface{}{
+				"slice_id": sliceID,
+				"replace":  replaceFlag,
+				"resp":     result,
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Import(c, sliceID, replaceFlag, r)
+}
+
+// Digests logging
+func (ls *LogService) Digests(c echo.Context, sliceID uuid.UUID) (resp []sandpiper.GrainDigest, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List grain digests request", err,
+			map[string]interface{}{
+				"slice_id": sliceID,
+				"resp":     fmt.Sprintf("Count: %d", len(resp)),
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Digests(c, sliceID)
+}
+
+// Granulate logging
+func (ls *LogService) Granulate(c echo.Context, sliceIDs []uuid.UUID, src string, r io.Reader) (resp []sandpiper.Granulation, err error) {
+	defer func(begin time.Time) {
+		var results []string
+		for _, g := range resp {
+			results = append(results, fmt.Sprintf("%s Items: %d, Added: %d, Deleted: %d, Unchanged: %d", g.SliceType, g.Items, g.Added, g.Deleted, g.Unchanged))
+		}
+		ls.logger.Log(
+			c,
+			source, "Granulate document request", err,
+			map[string]interface{}{
+				"slice_ids": sliceIDs,
+				"source":    src,
+				"resp":      results,
+				"took":      time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Granulate(c, sliceIDs, src, r)
+}
+
 // List logging
 func (ls *LogService) List(c echo.Context, payload bool, req *params.Params) (resp []sandpiper.Grain, err error) {
 	// todo: consider a "debug" level that shows entire resp
@@ -111,6 +170,35 @@ func (ls *LogService) View(c echo.Context, req uuid.UUID) (resp *sandpiper.Grain
 	return ls.Service.View(c, req)
 }
 
+// Payload logging
+func (ls *LogService) Payload(c echo.Context, req uuid.UUID) (resp *sandpiper.Grain, err error) {
+	defer func(begin time.Time) {
+		var g *sandpiper.Grain
+		if resp != nil {
+			// suppress payload in log
+			g = &sandpiper.Grain{
+				ID:         resp.ID,
+				SliceID:    resp.SliceID,
+				Key:        resp.Key,
+				Source:     resp.Source,
+				Encoding:   resp.Encoding,
+				PayloadLen: resp.PayloadLen,
+			}
+		}
+		ls.logger.Log(
+			c,
+			source, "Download grain payload request", err,
+			map[string]interface{}{
+				"req":   req,
+				"range": c.Request().Header.Get("Range"),
+				"resp":  g,
+				"took":  time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Payload(c, req)
+}
+
 // ViewByKeys logging
 func (ls *LogService) ViewByKeys(c echo.Context, sliceID uuid.UUID, grainKey string, payloadFlag bool) (resp *sandpiper.Grain, err error) {
 	defer func(begin time.Time) {
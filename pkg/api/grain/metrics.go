@@ -0,0 +1,14 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package grain
+
+// grain metrics (stored bytes by slice are collected by the database package when scraped)
+
+import (
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/metrics"
+)
+
+var grainsWritten = metrics.Default.Counter("sandpiper_grains_written_total",
+	"Grains written by slice type and operation (create, import or granulate).", "slice_type", "operation")
@@ -10,6 +10,8 @@ package pgsql
 // The payload is transferred (and stored) as a (possibly encoded) binary object.
 
 import (
+	"crypto/md5"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -18,22 +20,35 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	relsvc "github.com/sandpiper-framework/sandpiper/pkg/api/release/platform/pgsql"
+	searchsvc "github.com/sandpiper-framework/sandpiper/pkg/api/search/platform/pgsql"
+	slicesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slice/platform/pgsql"
+	slicetypesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 )
 
 // Grain represents the client for grain table
-type Grain struct{}
+type Grain struct {
+	kr *secure.Keyring // for slices encrypted at rest
+}
 
 // NewGrain returns a new grain database instance
-func NewGrain() *Grain {
-	return &Grain{}
+func NewGrain(kr *secure.Keyring) *Grain {
+	return &Grain{kr: kr}
 }
 
 // Custom errors
 var (
 	// ErrGrainNotFound indicates select returned no rows
 	ErrGrainNotFound = echo.NewHTTPError(http.StatusNotFound, "Grain does not exist.")
+
+	// ErrSliceNotFound indicates the grain's slice does not exist
+	ErrSliceNotFound = echo.NewHTTPError(http.StatusNotFound, "Slice does not exist.")
+
+	// ErrCompositeSlice indicates grains were sent to a composite slice (instead of a member)
+	ErrCompositeSlice = echo.NewHTTPError(http.StatusConflict, "Composite slices only hold grains of their member slices.")
 )
 
 // Create creates a new grain in database (assumes allowed to do this).
@@ -47,30 +62,46 @@ func (s *Grain) Create(db orm.DB, replaceFlag bool, grain *sandpiper.Grain) (*sa
 		}
 	}
 
+	// encrypt payload at rest (if required), but return the original
+	data := grain.Payload
+	if err := slicesvc.NewCrypter(db, s.kr).Seal(grain); err != nil {
+		return nil, err
+	}
 	if err := db.Insert(grain); err != nil {
 		return nil, err
 	}
+	if err := searchsvc.Index(db, grain); err != nil {
+		return nil, err
+	}
+	grain.Payload = data
 	return grain, nil
 }
 
-// View returns a single grain by ID (assumes allowed to do this)
+// View returns a single grain by ID (assumes allowed to do this). Grains no longer in their slice
+// are returned from a release that still includes them (e.g. for a subscription pinned to it).
 func (s *Grain) View(db orm.DB, id uuid.UUID) (*sandpiper.Grain, error) {
 	var grain = &sandpiper.Grain{ID: id}
 
 	err := db.Model(grain).
-		Column("grain.id", "slice_id", "grain_key", "source", "encoding", "payload", "grain.created_at").
+		Column("grain.id", "slice_id", "grain_key", "source", "encoding", "payload", "key_id", "grain.created_at").
 		ColumnExpr("length(payload) AS payload_len").
 		Relation("Slice").WherePK().Select()
+	if err == pg.ErrNoRows {
+		grain, err = relsvc.Grain(db, id)
+	}
 	if err != nil {
 		return nil, selectError(err)
 	}
+	if err := slicesvc.NewCrypter(db, s.kr).Open(grain); err != nil {
+		return nil, err
+	}
 	return grain, nil
 }
 
 // ViewByKeys returns minimal grain information if found, an empty grain if not found
 func (s *Grain) ViewByKeys(db orm.DB, sliceID uuid.UUID, grainKey string, payloadFlag bool) (*sandpiper.Grain, error) {
 	// columns to select (optionally returning payload)
-	cols := "id, slice_id, grain_key, source, encoding, created_at, length(payload) AS payload_len"
+	cols := "id, slice_id, grain_key, source, encoding, key_id, created_at, length(payload) AS payload_len"
 	if payloadFlag {
 		cols = cols + ", payload"
 	}
@@ -82,28 +113,40 @@ func (s *Grain) ViewByKeys(db orm.DB, sliceID uuid.UUID, grainKey string, payloa
 	if err != nil && err != pg.ErrNoRows {
 		return nil, err
 	}
+	if err := slicesvc.NewCrypter(db, s.kr).Open(grain); err != nil {
+		return nil, err
+	}
 	return grain, nil
 }
 
-// CompanySubscribed checks if grain is included in a company's subscriptions.
+// CompanySubscribed checks if grain is included in a company's subscriptions (directly or
+// through a composite slice).
 func (s *Grain) CompanySubscribed(db orm.DB, companyID uuid.UUID, grainID uuid.UUID) bool {
 	grain := new(sandpiper.Grain)
 	err := db.Model(grain).Column("grain.id").
-		Join("INNER JOIN subscriptions AS sub ON grain.slice_id = sub.slice_id").
-		Where("sub.company_id = ?", companyID).
+		Where("grain.slice_id IN (?)", slicesvc.AccessibleSlices(db, companyID)).
 		Where("grain.id = ?", grainID).Select()
 	if err == nil {
 		return true
 	}
-	return false
+	return relsvc.GrainAccess(db, companyID, grainID)
 }
 
-// List returns a list of all grains with scoping and pagination (optionally for a slice)
-func (s *Grain) List(db orm.DB, sliceID uuid.UUID, payloadFlag bool, sc *sandpiper.Scope, p *params.Params) (grains []sandpiper.Grain, err error) {
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("grain", "id", "slice_id", "grain_key", "source", "encoding", "created_at"),
+	Sort:   params.Columns("grain", "grain_key", "source", "encoding", "created_at"),
+}
+
+// List returns a list of all grains with scoping and pagination (optionally for a slice and by grain key).
+// Key queries are ordered by key and use keyset pagination (instead of an offset) if "after" is provided.
+// The grains of a composite slice are those of its members (and subscribing to a composite gives access
+// to them).
+func (s *Grain) List(db orm.DB, sliceID uuid.UUID, payloadFlag bool, kq *params.KeyQuery, sc *sandpiper.Scope, p *params.Params) (grains []sandpiper.Grain, err error) {
 	var q *orm.Query
 
 	// columns to select (optionally returning payload)
-	cols := "grain.id, grain.slice_id, grain_key, source, encoding, grain.created_at, length(payload) AS payload_len"
+	cols := "grain.id, grain.slice_id, grain_key, source, encoding, key_id, grain.created_at, length(payload) AS payload_len"
 	if payloadFlag {
 		cols = cols + ", payload"
 	}
@@ -111,34 +154,54 @@ func (s *Grain) List(db orm.DB, sliceID uuid.UUID, payloadFlag bool, sc *sandpip
 	// build the query
 	switch {
 	case sc != nil && sliceID != uuid.Nil:
-		// both provided, join to subscriptions (by-passing slices table)
+		// both provided, limit to the slice's content within "active" subscriptions (by-passing slices table)
 		q = db.Model(&grains).ColumnExpr(cols).
-			Join("INNER JOIN subscriptions AS sub ON grain.slice_id = sub.slice_id").
-			Where("sub.company_id = ?", sc.ID).Where("active = true")
+			Where("grain.slice_id IN (?)", slicesvc.ContentSlices(db, sliceID)).
+			Where("grain.slice_id IN (?)", slicesvc.AccessibleSlices(db, sc.ID))
 	case sc != nil && sliceID == uuid.Nil:
-		// provided scope without a slice
-		// Use CTE query to get all "active" subscriptions for the scope (i.e. the company)
-		q = db.Model((*sandpiper.Subscription)(nil)).
-			Column("subscription.slice_id").Where(sc.Condition, sc.ID).Where("active = true").
-			WrapWith("scope").Table("scope").
-			Join("INNER JOIN grains AS grain ON grain.slice_id = scope.slice_id").
-			ColumnExpr(cols)
+		// provided scope without a slice, use all "active" subscriptions for the scope (i.e. the company)
+		q = db.Model(&grains).ColumnExpr(cols).
+			Where("grain.slice_id IN (?)", slicesvc.AccessibleSlices(db, sc.ID))
 	case sc == nil && sliceID != uuid.Nil:
 		// provided slice without a scope, use simple where clause
-		q = db.Model(&grains).ColumnExpr(cols).Where("slice_id = ?", sliceID)
+		q = db.Model(&grains).ColumnExpr(cols).Where("grain.slice_id IN (?)", slicesvc.ContentSlices(db, sliceID))
 	default:
 		// neither provided, simply return all grains
 		q = db.Model(&grains).ColumnExpr(cols)
 	}
 
-	// add paging
-	q = q.Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	// add filter and key conditions
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	q = kq.AddWhere(q, "grain.grain_key")
+
+	// key queries are always ordered by key (which keyset pagination relies on, so any sort is ignored)
+	if !kq.Provided() {
+		if err := p.AddSort(q, listFields, "grain.grain_key"); err != nil {
+			return nil, err
+		}
+	}
+
+	// add paging (the count is of remaining keys for keyset pagination)
+	q = q.Limit(p.Paging.PageSize)
+	if !kq.Provided() || !kq.Keyset {
+		q = q.Offset(p.Paging.Offset())
+	}
 
 	// execute the query
 	p.Paging.Count, err = q.SelectAndCount(&grains)
 	if err != nil {
 		return nil, err
 	}
+
+	// decrypt payloads encrypted at rest
+	c := slicesvc.NewCrypter(db, s.kr)
+	for i := range grains {
+		if err := c.Open(&grains[i]); err != nil {
+			return nil, err
+		}
+	}
 	return grains, nil
 }
 
@@ -148,6 +211,164 @@ func (s *Grain) Delete(db orm.DB, id uuid.UUID) error {
 	return db.Delete(&grain)
 }
 
+// Slice returns basic information about a slice that will hold grains
+func (s *Grain) Slice(db orm.DB, sliceID uuid.UUID) (*sandpiper.Slice, error) {
+	slice := &sandpiper.Slice{ID: sliceID}
+	err := db.Model(slice).Column("id", "name", "slice_type", "composite", "allow_sync").WherePK().Select()
+	if err == pg.ErrNoRows {
+		return nil, ErrSliceNotFound
+	}
+	if err == nil && slice.Composite {
+		return nil, ErrCompositeSlice
+	}
+	return slice, err
+}
+
+// SliceType returns a registered slice type (with its plugins)
+func (s *Grain) SliceType(db orm.DB, name string) (*sandpiper.SliceType, error) {
+	return slicetypesvc.Lookup(db, name)
+}
+
+// Keys returns all grain keys for a slice
+func (s *Grain) Keys(db orm.DB, sliceID uuid.UUID) ([]string, error) {
+	var keys []string
+	err := db.Model((*sandpiper.Grain)(nil)).Column("grain_key").
+		Where("slice_id = ?", sliceID).Select(&keys)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// CreateBatch adds several grains with a single multi-row insert (assumes keys are already lowercase)
+func (s *Grain) CreateBatch(db orm.DB, grains []sandpiper.Grain) error {
+	if len(grains) == 0 {
+		return nil
+	}
+	c := slicesvc.NewCrypter(db, s.kr)
+	for i := range grains {
+		if err := c.Seal(&grains[i]); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Model(&grains).Insert(); err != nil {
+		return err
+	}
+	for i := range grains {
+		if err := searchsvc.Index(db, &grains[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBySlice removes all grains from a slice
+func (s *Grain) DeleteBySlice(db orm.DB, sliceID uuid.UUID) error {
+	_, err := db.Model((*sandpiper.Grain)(nil)).Where("slice_id = ?", sliceID).Delete()
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+// KeyHashes returns an md5 hash of each grain payload (as stored) by grain key for a slice
+func (s *Grain) KeyHashes(db orm.DB, sliceID uuid.UUID) (map[string]string, error) {
+	digests, err := s.Digests(db, sliceID)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(digests))
+	for _, d := range digests {
+		hashes[d.Key] = d.Hash
+	}
+	return hashes, nil
+}
+
+// Digests returns brief information about each grain in a slice with an md5 hash of the payload (as stored,
+// but before any encryption at rest)
+func (s *Grain) Digests(db orm.DB, sliceID uuid.UUID) ([]sandpiper.GrainDigest, error) {
+	var digests []sandpiper.GrainDigest
+	err := db.Model((*sandpiper.Grain)(nil)).
+		Column("id", "grain_key", "source", "key_id").ColumnExpr("md5(payload) AS hash").
+		Where("slice_id = ?", sliceID).Order("grain_key").Select(&digests)
+	if err != nil {
+		return nil, err
+	}
+	return digests, s.openDigests(db, sliceID, digests)
+}
+
+// openDigests replaces hashes of encrypted payloads (which change with every encryption)
+func (s *Grain) openDigests(db orm.DB, sliceID uuid.UUID, digests []sandpiper.GrainDigest) error {
+	var sealed []sandpiper.Grain
+	err := db.Model(&sealed).Column("id", "payload", "key_id").
+		Where("slice_id = ?", sliceID).Where("key_id IS NOT NULL").Select()
+	if err != nil || len(sealed) == 0 {
+		return err
+	}
+	c := slicesvc.NewCrypter(db, s.kr)
+	hashes := make(map[uuid.UUID]string, len(sealed))
+	for i := range sealed {
+		if err := c.Open(&sealed[i]); err != nil {
+			return err
+		}
+		hashes[sealed[i].ID] = fmt.Sprintf("%x", md5.Sum([]byte(sealed[i].Payload)))
+	}
+	for i, d := range digests {
+		if d.KeyID != 0 {
+			digests[i].Hash = hashes[d.ID]
+		}
+	}
+	return nil
+}
+
+// DeleteByKeys removes grains from a slice by grain key (assumes keys are already lowercase)
+func (s *Grain) DeleteByKeys(db orm.DB, sliceID uuid.UUID, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := db.Model((*sandpiper.Grain)(nil)).
+		Where("slice_id = ?", sliceID).
+		Where("grain_key IN (?)", pg.In(keys)).Delete()
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+// ReplaceMetadata replaces all slice metadata with keys starting with prefix (leaving other keys alone)
+func (s *Grain) ReplaceMetadata(db orm.DB, sliceID uuid.UUID, prefix string, meta sandpiper.MetaMap) error {
+	_, err := db.Model((*sandpiper.SliceMetadata)(nil)).
+		Where("slice_id = ?", sliceID).
+		Where("substr(key, 1, ?) = ?", len(prefix), prefix).Delete()
+	if err != nil && err != pg.ErrNoRows {
+		return err
+	}
+	m := &sandpiper.SliceMetadata{SliceID: sliceID}
+	for k, v := range meta {
+		m.Key, m.Value = k, v
+		if err := db.Insert(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshSlice updates the slice content information (hash, count and date) after grain changes
+func (s *Grain) RefreshSlice(db orm.DB, sliceID uuid.UUID) error {
+	// use the slice service's database access to keep the hash calculation in one place
+	return slicesvc.NewSlice().Refresh(db, sliceID)
+}
+
+// LockSlice disallows sync operations on a slice (during a content change)
+func (s *Grain) LockSlice(db orm.DB, sliceID uuid.UUID) error {
+	return slicesvc.NewSlice().Lock(db, sliceID)
+}
+
+// UnlockSlice allows sync operations on a slice again
+func (s *Grain) UnlockSlice(db orm.DB, sliceID uuid.UUID) error {
+	return slicesvc.NewSlice().Unlock(db, sliceID)
+}
+
 // removeExistingGrain will remove a grain by alternate unique key. Only return real errors.
 func removeExistingGrain(db orm.DB, sliceID uuid.UUID, grainKey string) error {
 	// attempt to delete by unique keys
@@ -5,14 +5,18 @@
 package pgsql_test
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/api/grain/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
 func TestCreate(t *testing.T) {
@@ -70,7 +74,7 @@ func TestCreate(t *testing.T) {
 		t.Error(err)
 	}
 
-	mdb := pgsql.NewGrain()
+	mdb := pgsql.NewGrain(nil)
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -121,7 +125,7 @@ func TestView(t *testing.T) {
 		t.Error(err)
 	}
 
-	udb := pgsql.NewGrain()
+	udb := pgsql.NewGrain(nil)
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -184,7 +188,7 @@ func TestList(t *testing.T) {
 
 	db := mock.NewDB(t, dbCon, &sandpiper.Grain{})
 
-	mdb := pgsql.NewGrain()
+	mdb := pgsql.NewGrain(nil)
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -231,7 +235,7 @@ func TestDelete(t *testing.T) {
 		t.Error(err)
 	}
 
-	mdb := pgsql.NewGrain()
+	mdb := pgsql.NewGrain(nil)
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -242,3 +246,61 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestListParams(t *testing.T) {
+	cases := []struct {
+		name    string
+		filter  []string
+		sort    []string
+		kq      *params.KeyQuery
+		wantErr bool
+		wantSQL string
+	}{
+		{
+			name:    "Sorted by key by default",
+			wantSQL: `ORDER BY "grain"."grain_key" LIMIT 50`,
+		},
+		{
+			name:    "Filtered and sorted",
+			filter:  []string{"source:acme,created_at:gt:2020-01-01"},
+			sort:    []string{"created_at:desc"},
+			wantSQL: `("grain"."source" = 'acme') AND ("grain"."created_at" > '2020-01-01') ORDER BY "grain"."created_at" DESC`,
+		},
+		{
+			name:    "Key queries keep key order",
+			sort:    []string{"created_at:desc"},
+			kq:      &params.KeyQuery{Prefix: "a"},
+			wantSQL: `ORDER BY "grain"."grain_key" LIMIT 50`,
+		},
+		{
+			name:    "Unknown filter",
+			filter:  []string{"payload:x"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown sort",
+			sort:    []string{"payload"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := (&mockdb.DB{}).
+				On(`FROM "grains"`, mockdb.Row{"id": mock.TestUUID(1), "grain_key": "a"}).
+				On(`SELECT count(*)`, mockdb.Row{"count": 1})
+			p := &params.Params{Filter: tt.filter, Sort: tt.sort, Paging: sandpiper.NewPagination()}
+
+			grains, err := pgsql.NewGrain(nil).List(db, uuid.Nil, false, tt.kq, nil, p)
+			if tt.wantErr {
+				assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+				assert.Empty(t, db.SQL)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(grains))
+			if page := db.Find(`SELECT grain.id`); assert.Equal(t, 1, len(page)) {
+				assert.Contains(t, page[0], tt.wantSQL)
+			}
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/api/grain"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 
 	gl "github.com/sandpiper-framework/sandpiper/pkg/api/grain/logging"
 	gt "github.com/sandpiper-framework/sandpiper/pkg/api/grain/transport"
@@ -17,8 +19,8 @@ import (
 )
 
 // Register ties the grain service to its logger and transport mechanisms
-func Register(db *database.DB, sec grain.Securer, log sandpiper.Logger, v1 *echo.Group) {
-	svc := grain.Initialize(db, rbac.New(db.Settings.ServerRole), sec)
+func Register(db *database.DB, sec grain.Securer, log sandpiper.Logger, v1 *echo.Group, cfg *config.Validation, kr *secure.Keyring) {
+	svc := grain.Initialize(db, rbac.New(db.Settings.ServerRole), sec, cfg, kr, log)
 	ls := gl.ServiceLogger(svc, log)
 	gt.NewHTTP(ls, v1)
 }
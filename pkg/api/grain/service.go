@@ -5,6 +5,8 @@
 package grain
 
 import (
+	"io"
+
 	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
 	"github.com/google/uuid"
@@ -12,28 +14,35 @@ import (
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/api/grain/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/validate"
 )
 
 // Service represents grain application interface (note no update!)
 type Service interface {
 	Create(echo.Context, bool, *sandpiper.Grain) (*sandpiper.Grain, error)
+	Import(echo.Context, uuid.UUID, bool, io.Reader) (*sandpiper.GrainImport, error)
+	Granulate(echo.Context, []uuid.UUID, string, io.Reader) ([]sandpiper.Granulation, error)
 	List(echo.Context, bool, *params.Params) ([]sandpiper.Grain, error)
-	ListBySlice(echo.Context, uuid.UUID, bool, *params.Params) ([]sandpiper.Grain, error)
+	ListBySlice(echo.Context, uuid.UUID, bool, *params.KeyQuery, *params.Params) ([]sandpiper.Grain, error)
+	Digests(echo.Context, uuid.UUID) ([]sandpiper.GrainDigest, error)
 	View(echo.Context, uuid.UUID) (*sandpiper.Grain, error)
+	Payload(echo.Context, uuid.UUID) (*sandpiper.Grain, error)
 	ViewByKeys(echo.Context, uuid.UUID, string, bool) (*sandpiper.Grain, error)
 	Delete(echo.Context, uuid.UUID) error
 }
 
 // New creates new grain application service
-func New(db *database.DB, sdb Repository, rbac RBAC, sec Securer) *Grain {
-	return &Grain{db: db.DB, sdb: sdb, rbac: rbac, sec: sec}
+func New(db *database.DB, sdb Repository, rbac RBAC, sec Securer, val Validator) *Grain {
+	return &Grain{db: db.DB, sdb: sdb, rbac: rbac, sec: sec, val: val}
 }
 
 // Initialize initializes Grain application service with defaults
-func Initialize(db *database.DB, rbac RBAC, sec Securer) *Grain {
-	return New(db, pgsql.NewGrain(), rbac, sec)
+func Initialize(db *database.DB, rbac RBAC, sec Securer, cfg *config.Validation, kr *secure.Keyring, log sandpiper.Logger) *Grain {
+	return New(db, pgsql.NewGrain(kr), rbac, sec, validate.New(cfg, log))
 }
 
 // Grain represents grain application service
@@ -42,6 +51,7 @@ type Grain struct {
 	sdb  Repository
 	rbac RBAC
 	sec  Securer
+	val  Validator
 }
 
 // Securer represents security interface
@@ -49,14 +59,32 @@ type Securer interface {
 	Hash(string) string
 }
 
+// Validator represents grain payload validation interface (by plugin name)
+type Validator interface {
+	Validate(string, validate.Content) []string
+	ValidateBatch(string, []validate.Content) [][]string
+}
+
 // Repository represents available resource actions using a repository-abstraction-pattern interface.
 type Repository interface {
 	Create(orm.DB, bool, *sandpiper.Grain) (*sandpiper.Grain, error)
 	CompanySubscribed(db orm.DB, companyID uuid.UUID, grainID uuid.UUID) bool
 	View(orm.DB, uuid.UUID) (*sandpiper.Grain, error)
 	ViewByKeys(orm.DB, uuid.UUID, string, bool) (*sandpiper.Grain, error)
-	List(orm.DB, uuid.UUID, bool, *sandpiper.Scope, *params.Params) ([]sandpiper.Grain, error)
+	List(orm.DB, uuid.UUID, bool, *params.KeyQuery, *sandpiper.Scope, *params.Params) ([]sandpiper.Grain, error)
 	Delete(orm.DB, uuid.UUID) error
+	Slice(orm.DB, uuid.UUID) (*sandpiper.Slice, error)
+	SliceType(orm.DB, string) (*sandpiper.SliceType, error)
+	Keys(orm.DB, uuid.UUID) ([]string, error)
+	CreateBatch(orm.DB, []sandpiper.Grain) error
+	DeleteBySlice(orm.DB, uuid.UUID) error
+	KeyHashes(orm.DB, uuid.UUID) (map[string]string, error)
+	Digests(orm.DB, uuid.UUID) ([]sandpiper.GrainDigest, error)
+	DeleteByKeys(orm.DB, uuid.UUID, []string) error
+	ReplaceMetadata(orm.DB, uuid.UUID, string, sandpiper.MetaMap) error
+	RefreshSlice(orm.DB, uuid.UUID) error
+	LockSlice(orm.DB, uuid.UUID) error
+	UnlockSlice(orm.DB, uuid.UUID) error
 }
 
 // RBAC represents role-based-access-control interface
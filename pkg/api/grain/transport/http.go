@@ -7,7 +7,9 @@ package transport
 // routing of grain resources
 
 import (
+	"mime"
 	"net/http"
+	"path"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -29,7 +31,15 @@ func NewHTTP(svc grain.Service, er *echo.Group) {
 	sr := er.Group("/grains")
 	sr.POST("", h.create) // ?replace=[yes/no*]
 	sr.GET("", h.list)    // ?payload=[yes/no*]
+	// ?prefix=<key-prefix>|match=<glob>|from=<key>&to=<key>|after=<key> (see params.KeyQuery)
 	sr.GET("/slice/:id", h.listBySlice)
+	sr.POST("/slice/:id", h.importGrains) // ?replace=[yes/no*] (body is newline-delimited json)
+	sr.GET("/slice/:id/digests", h.digests)
+	sr.POST("/slice/:id/granulate", h.granulate) // ?source=<filename>&related=<slice_id>... (body is the document)
+	// raw (decoded) payload with http range support (under its own prefix, since any grain key
+	// could follow a slice id, including "payload")
+	sr.GET("/payload/:id", h.payload)
+	sr.HEAD("/payload/:id", h.payload)
 	sr.GET("/:id", h.view)
 	sr.GET("/:sliceid/:grainkey", h.viewByKeys) // ?payload=[yes/no*]
 	sr.DELETE("/:id", h.delete)
@@ -125,12 +135,85 @@ func (h *HTTP) listBySlice(c echo.Context) error {
 		return err
 	}
 
-	result, err := h.svc.ListBySlice(c, sliceID, includePayload, p)
+	// optional grain key conditions
+	kq := params.NewKeyQuery(c.QueryParams())
+
+	result, err := h.svc.ListBySlice(c, sliceID, includePayload, kq, p)
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, sandpiper.GrainsPaginated{Grains: result, Paging: p.Paging})
+	// keyset pagination continues after the last key returned
+	resp := sandpiper.GrainsPaginated{Grains: result, Paging: p.Paging}
+	if kq.Keyset && p.Paging.Count > len(result) {
+		resp.Next = result[len(result)-1].Key
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// importGrains adds grains to a slice from a newline-delimited json stream (one grain per line)
+func (h *HTTP) importGrains(c echo.Context) error {
+	var replaceFlag = false
+
+	sliceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	if c.QueryParam("replace") == "yes" {
+		replaceFlag = true
+	}
+
+	result, err := h.svc.Import(c, sliceID, replaceFlag, c.Request().Body)
+	if err == grain.ErrImportRejected {
+		// return the line errors
+		return c.JSON(err.(*echo.HTTPError).Code, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// digests returns every grain in a slice (without payload) with a hash of its payload
+func (h *HTTP) digests(c echo.Context) error {
+	sliceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	result, err := h.svc.Digests(c, sliceID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// granulate splits a document (e.g. an ACES xml file) into item-level grains for a slice. Other
+// slices can receive their own items from the same document (e.g. a PIES file's MarketCopy).
+func (h *HTTP) granulate(c echo.Context) error {
+	sliceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	sliceIDs := []uuid.UUID{sliceID}
+	for _, related := range c.QueryParams()["related"] {
+		id, err := uuid.Parse(related)
+		if err != nil {
+			return ErrInvalidSliceUUID
+		}
+		sliceIDs = append(sliceIDs, id)
+	}
+
+	result, err := h.svc.Granulate(c, sliceIDs, c.QueryParam("source"), c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
 }
 
 func (h *HTTP) view(c echo.Context) error {
@@ -147,6 +230,42 @@ func (h *HTTP) view(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+// payload streams the decoded grain payload (rather than the encoded json representation),
+// decompressing as it is sent. Grains are immutable (no update method), so the grain id is a
+// strong ETag and http.ServeContent can safely handle conditional and range requests (including
+// "If-Range").
+func (h *HTTP) payload(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidGrainUUID
+	}
+
+	grain, err := h.svc.Payload(c, id)
+	if err != nil {
+		return err
+	}
+
+	content, err := grain.Payload.NewContent(grain.Encoding)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to decode grain payload").SetInternal(err)
+	}
+	defer content.Close()
+
+	// default filename to grain_id if source is empty (as the `pull` command does)
+	fileName := path.Base(grain.Source)
+	if grain.Source == "" {
+		fileName = grain.ID.String() + ".txt"
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Header().Set("ETag", `"`+grain.ID.String()+`"`)
+
+	// content-type is taken from the file extension (sniffing the content if that fails)
+	http.ServeContent(w, c.Request(), fileName, grain.CreatedAt, content)
+	return nil
+}
+
 func (h *HTTP) viewByKeys(c echo.Context) error {
 	var includePayload = false
 
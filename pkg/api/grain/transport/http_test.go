@@ -7,9 +7,37 @@ package transport_test
 // grain service
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/grain"
+	"github.com/sandpiper-framework/sandpiper/pkg/api/grain/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/server"
 )
 
+// payloadSvc is a minimal grain service returning a single grain for the payload endpoint
+type payloadSvc struct {
+	grain.Service
+	grain *sandpiper.Grain
+	err   error
+}
+
+func (s *payloadSvc) Payload(c echo.Context, id uuid.UUID) (*sandpiper.Grain, error) {
+	return s.grain, s.err
+}
+
+func (s *payloadSvc) ViewByKeys(c echo.Context, sliceID uuid.UUID, key string, payloadFlag bool) (*sandpiper.Grain, error) {
+	return &sandpiper.Grain{SliceID: &sliceID, Key: key}, s.err
+}
+
 func TestCreate(t *testing.T) {
 
 }
@@ -29,3 +57,108 @@ func TestUpdate(t *testing.T) {
 func TestDelete(t *testing.T) {
 
 }
+
+func TestPayload(t *testing.T) {
+	id := uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	data, _ := payload.Encode(bytes.NewReader([]byte("<ACES>sandpiper rocks!</ACES>")), "z64")
+	g := &sandpiper.Grain{ID: id, Key: sandpiper.L1GrainKey, Source: "aces-file.xml", Encoding: "z64", Payload: data}
+
+	cases := []struct {
+		name       string
+		id         string
+		header     map[string]string
+		svc        *payloadSvc
+		wantStatus int
+		wantBody   string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "Fail on invalid uuid",
+			id:         "abc",
+			svc:        &payloadSvc{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Fail on access",
+			id:         id.String(),
+			svc:        &payloadSvc{err: echo.ErrForbidden},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Full payload",
+			id:         id.String(),
+			svc:        &payloadSvc{grain: g},
+			wantStatus: http.StatusOK,
+			wantBody:   "<ACES>sandpiper rocks!</ACES>",
+			wantHeader: map[string]string{
+				"Content-Type":        "text/xml; charset=utf-8",
+				"Content-Length":      "29",
+				"Content-Disposition": `attachment; filename=aces-file.xml`,
+				"ETag":                `"` + id.String() + `"`,
+			},
+		},
+		{
+			name:       "Partial payload",
+			id:         id.String(),
+			header:     map[string]string{"Range": "bytes=6-14"},
+			svc:        &payloadSvc{grain: g},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "sandpiper",
+			wantHeader: map[string]string{"Content-Range": "bytes 6-14/29"},
+		},
+		{
+			name:       "Not modified",
+			id:         id.String(),
+			header:     map[string]string{"If-None-Match": `"` + id.String() + `"`},
+			svc:        &payloadSvc{grain: g},
+			wantStatus: http.StatusNotModified,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := server.New()
+			transport.NewHTTP(tt.svc, r.Group(""))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("GET", ts.URL+"/grains/payload/"+tt.id, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantBody != "" {
+				body := new(bytes.Buffer)
+				_, _ = body.ReadFrom(res.Body)
+				assert.Equal(t, tt.wantBody, body.String())
+			}
+			for k, v := range tt.wantHeader {
+				assert.Equal(t, v, res.Header.Get(k))
+			}
+		})
+	}
+}
+
+func TestViewByKeys(t *testing.T) {
+	// a grain key of "payload" is found by key (not taken for the payload of a grain)
+	sliceID := uuid.MustParse("20000000-0000-0000-0000-000000000000")
+	r := server.New()
+	transport.NewHTTP(&payloadSvc{}, r.Group(""))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/grains/" + sliceID.String() + "/payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body := new(bytes.Buffer)
+	_, _ = body.ReadFrom(res.Body)
+	assert.Contains(t, body.String(), `"grain_key":"payload"`)
+}
@@ -9,9 +9,16 @@ package password
 import (
 	"net/http"
 
+	"github.com/go-pg/pg/v9"
 	"github.com/labstack/echo/v4"
+
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 )
 
+// resource identifies password changes in the audit trail (the hash itself is never recorded)
+const resource = "password"
+
 // Custom errors
 var (
 	ErrIncorrectPassword = echo.NewHTTPError(http.StatusBadRequest, "incorrect old password")
@@ -37,7 +44,13 @@ func (p *Password) Change(c echo.Context, userID int, oldPass, newPass string) e
 		return ErrInsecurePassword
 	}
 
+	before := *u
 	u.ChangePassword(p.sec.Hash(newPass))
 
-	return p.sdb.Update(p.db, u)
+	return p.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := p.sdb.Update(tx, u); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, userID, &before, u)
+	})
 }
@@ -0,0 +1,62 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package purge
+
+// purge service logger
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/purge"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// ServiceLogger creates new logger wrapping the purge service
+func ServiceLogger(svc purge.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents purge logging service
+type LogService struct {
+	purge.Service
+	logger sandpiper.Logger
+}
+
+const source = "purge"
+
+// Status logging
+func (ls *LogService) Status(c echo.Context) (resp *sandpiper.PurgeStatus, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Purge status request", err,
+			map[string]interface{}{
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Status(c)
+}
+
+// Run logging
+func (ls *LogService) Run(c echo.Context) (resp []sandpiper.PurgeRun, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Purge run request", err,
+			map[string]interface{}{
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Run(c)
+}
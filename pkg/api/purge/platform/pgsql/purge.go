@@ -0,0 +1,111 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// purge service database access
+
+// Rows are archived (as json, oldest first) in batches and then deleted in the same
+// transaction, so a failure leaves the table unchanged. Only the tables listed in `targets`
+// can be purged (each needs a serial "id" and a timestamp column).
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Custom errors
+var (
+	ErrUnknownTable = echo.NewHTTPError(http.StatusBadRequest, "Table does not support a retention policy.")
+)
+
+// archiveBatchSize is the number of rows read at a time
+const archiveBatchSize = 1000
+
+// targets holds the timestamp column of each table that can be purged (there is no separate
+// sync history table, each sync is logged to activity)
+var targets = map[string]string{
+	"activity":         "created_at", // sync history (on both servers)
+	"audit_log":        "created_at", // administrative changes
+	"grain_tombstones": "deleted_at", // deleted grains (recorded by a trigger on grains)
+}
+
+// Purge represents the client for purging tables
+type Purge struct{}
+
+// NewPurge returns a new purge database instance
+func NewPurge() *Purge {
+	return &Purge{}
+}
+
+// archiveRow is a table row as json
+type archiveRow struct {
+	ID   int
+	Data string
+}
+
+// Archive writes the rows created before the cutoff to w (one json object per line), returning
+// the number of rows and the highest id written (should be run in a transaction with Delete)
+func (s *Purge) Archive(db orm.DB, table string, cutoff time.Time, w io.Writer) (count, maxID int, err error) {
+	col, ok := targets[table]
+	if !ok {
+		return 0, 0, ErrUnknownTable
+	}
+	var rows []archiveRow
+	for {
+		rows = rows[:0]
+		_, err := db.Query(&rows, `
+			SELECT id, row_to_json(t)::text AS data FROM ? AS t
+			WHERE ? < ? AND id > ? ORDER BY id LIMIT ?`,
+			pg.Ident(table), pg.Ident(col), cutoff, maxID, archiveBatchSize)
+		if err != nil {
+			return count, maxID, err
+		}
+		for _, r := range rows {
+			if _, err := io.WriteString(w, r.Data+"\n"); err != nil {
+				return count, maxID, err
+			}
+			maxID = r.ID
+			count++
+		}
+		if len(rows) < archiveBatchSize {
+			return count, maxID, nil
+		}
+	}
+}
+
+// Delete removes the archived rows (created before the cutoff up to the highest id archived)
+func (s *Purge) Delete(db orm.DB, table string, cutoff time.Time, maxID int) (int, error) {
+	col, ok := targets[table]
+	if !ok {
+		return 0, ErrUnknownTable
+	}
+	res, err := db.Exec(`DELETE FROM ? WHERE ? < ? AND id <= ?`, pg.Ident(table), pg.Ident(col), cutoff, maxID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// SaveRun records a purge run
+func (s *Purge) SaveRun(db orm.DB, run *sandpiper.PurgeRun) error {
+	return db.Insert(run)
+}
+
+// LastRuns returns the most recent purge run of each table
+func (s *Purge) LastRuns(db orm.DB) ([]sandpiper.PurgeRun, error) {
+	var runs []sandpiper.PurgeRun
+	err := db.Model(&runs).DistinctOn("target").Order("target", "started_at DESC").Select()
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,128 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/purge/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+)
+
+var cutoff = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// rows returns activity rows as json with ids from..to
+func rows(from, to int) []mockdb.Row {
+	var r []mockdb.Row
+	for id := from; id <= to; id++ {
+		r = append(r, mockdb.Row{"id": id, "data": fmt.Sprintf(`{"id":%d}`, id)})
+	}
+	return r
+}
+
+func TestArchive(t *testing.T) {
+	cases := []struct {
+		name      string
+		table     string
+		db        *mockdb.DB
+		wantErr   error
+		wantCount int
+		wantMaxID int
+		wantSQL   []string
+	}{
+		{
+			name:    "Unknown table",
+			table:   "grains",
+			db:      &mockdb.DB{},
+			wantErr: pgsql.ErrUnknownTable,
+		},
+		{
+			name:  "Nothing to archive",
+			table: "activity",
+			db:    &mockdb.DB{},
+			wantSQL: []string{
+				`
+			SELECT id, row_to_json(t)::text AS data FROM "activity" AS t
+			WHERE "created_at" < '2020-01-01 00:00:00+00:00:00' AND id > 0 ORDER BY id LIMIT 1000`,
+			},
+		},
+		{
+			name:      "Batches",
+			table:     "audit_log",
+			db:        (&mockdb.DB{}).On("id > 0 ORDER", rows(1, 1000)...).On("id > 1000 ORDER", rows(1001, 1002)...),
+			wantCount: 1002,
+			wantMaxID: 1002,
+		},
+		{
+			name:    "Query fails",
+			table:   "activity",
+			db:      (&mockdb.DB{}).Fail("row_to_json", errors.New("db error")),
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			count, maxID, err := pgsql.NewPurge().Archive(tt.db, tt.table, cutoff, &w)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCount, count)
+			assert.Equal(t, tt.wantMaxID, maxID)
+			assert.Equal(t, tt.wantCount, strings.Count(w.String(), "\n"))
+			if tt.wantSQL != nil {
+				assert.Equal(t, tt.wantSQL, tt.db.SQL)
+			}
+		})
+	}
+}
+
+func TestArchiveLines(t *testing.T) {
+	db := (&mockdb.DB{}).On("row_to_json", rows(7, 8)...)
+	var w bytes.Buffer
+	_, _, err := pgsql.NewPurge().Archive(db, "activity", cutoff, &w)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":7}\n{\"id\":8}\n", w.String())
+}
+
+func TestDelete(t *testing.T) {
+	db := (&mockdb.DB{}).On("DELETE", mockdb.Row{}, mockdb.Row{})
+	n, err := pgsql.NewPurge().Delete(db, "activity", cutoff, 1002)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	// only the archived rows (rows added since can't be lost)
+	assert.Equal(t, []string{`DELETE FROM "activity" WHERE "created_at" < '2020-01-01 00:00:00+00:00:00' AND id <= 1002`}, db.SQL)
+
+	_, err = pgsql.NewPurge().Delete(&mockdb.DB{}, "grains", cutoff, 1)
+	assert.Equal(t, pgsql.ErrUnknownTable, err)
+}
+
+func TestTombstones(t *testing.T) {
+	// tombstones are purged by the time their grain was deleted
+	db := (&mockdb.DB{}).On(`"deleted_at" < `, rows(1, 2)...).
+		On(`DELETE FROM "grain_tombstones" WHERE "deleted_at"`, mockdb.Row{}, mockdb.Row{})
+	var w bytes.Buffer
+	count, maxID, err := pgsql.NewPurge().Archive(db, "grain_tombstones", cutoff, &w)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	n, err := pgsql.NewPurge().Delete(db, "grain_tombstones", cutoff, maxID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestLastRuns(t *testing.T) {
+	db := (&mockdb.DB{}).On("purge_runs", mockdb.Row{"id": 3, "target": "activity", "archived": 5})
+	runs, err := pgsql.NewPurge().LastRuns(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(runs))
+	assert.Equal(t, 5, runs[0].Archived)
+	assert.Contains(t, db.SQL[0], `SELECT DISTINCT ON (target)`)
+	assert.Contains(t, db.SQL[0], `ORDER BY "target", "started_at" DESC`)
+}
@@ -0,0 +1,166 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package purge contains services for the retention policies of tables that grow forever (e.g.
+// the activity table). Old rows are archived to compressed files and then deleted.
+package purge
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Status returns the retention policies and the last purge of each table (if administrator)
+func (s *Purge) Status(c echo.Context) (*sandpiper.PurgeStatus, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	runs, err := s.sdb.LastRuns(s.db)
+	if err != nil {
+		return nil, err
+	}
+	policies := s.days
+	if policies == nil {
+		policies = map[string]int{}
+	}
+	return &sandpiper.PurgeStatus{Policies: policies, Interval: s.interval.String(), LastRuns: runs}, nil
+}
+
+// Run purges every table with a retention policy now (if administrator)
+func (s *Purge) Run(c echo.Context) ([]sandpiper.PurgeRun, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.Purge(), nil
+}
+
+// Purge archives and deletes the rows older than the retention policy of each table, recording
+// each run (a failure only affects its own table)
+func (s *Purge) Purge() []sandpiper.PurgeRun {
+	tables := make([]string, 0, len(s.days))
+	for table := range s.days {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	runs := make([]sandpiper.PurgeRun, 0, len(tables))
+	for _, table := range tables {
+		now := time.Now()
+		run := sandpiper.PurgeRun{
+			Target:    table,
+			Cutoff:    now.AddDate(0, 0, -s.days[table]),
+			StartedAt: now,
+		}
+		if err := s.purgeTable(&run); err != nil {
+			run.Error = err.Error()
+		}
+		run.FinishedAt = time.Now()
+		if err := s.sdb.SaveRun(s.db, &run); err != nil {
+			// still returned (and logged when in the background), just not in the purge history
+			run.Error = strings.TrimPrefix(run.Error+"; run not recorded: "+err.Error(), "; ")
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// StartPurge runs Purge in the background now and then at each interval (if any policies),
+// logging each run
+func (s *Purge) StartPurge(logger sandpiper.Logger) {
+	if len(s.days) == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			for _, run := range s.Purge() {
+				var err error
+				if run.Error != "" {
+					err = errors.New(run.Error)
+				}
+				logger.Log(nil, "purge", "Purge table", err,
+					map[string]interface{}{
+						"table":    run.Target,
+						"archived": run.Archived,
+						"deleted":  run.Deleted,
+						"took":     run.FinishedAt.Sub(run.StartedAt),
+					},
+				)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeTable archives the old rows of a table to a new file and deletes them in one transaction
+// (the file is removed if nothing was archived or the purge failed)
+func (s *Purge) purgeTable(run *sandpiper.PurgeRun) (err error) {
+	if s.days[run.Target] <= 0 {
+		return fmt.Errorf("days kept must be positive (not %d)", s.days[run.Target])
+	}
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return err
+	}
+	name := filepath.Join(s.dir, fmt.Sprintf("%s-%s.jsonl.gz", run.Target, run.StartedAt.Format("20060102T150405")))
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+		if err != nil {
+			run.Archived, run.Deleted = 0, 0 // rolled back
+		}
+		if run.Archived == 0 {
+			os.Remove(name)
+		} else {
+			run.ArchiveFile = name
+		}
+	}()
+
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		return s.archive(tx, f, run)
+	})
+}
+
+// archive writes the old rows of a table to f and then deletes them (the caller's transaction
+// undoes the delete if anything fails)
+func (s *Purge) archive(tx orm.DB, f *os.File, run *sandpiper.PurgeRun) error {
+	zw := gzip.NewWriter(f)
+	count, maxID, err := s.sdb.Archive(tx, run.Target, run.Cutoff, zw)
+	if err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	// the archive must be safely written before the rows are deleted
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	deleted, err := s.sdb.Delete(tx, run.Target, run.Cutoff, maxID)
+	if err != nil {
+		return err
+	}
+	run.Archived, run.Deleted = count, deleted
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package purge
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// purgeRepo archives fixed rows and records the delete
+type purgeRepo struct {
+	Repository
+	lines      string
+	count      int
+	archiveErr error
+	deleteErr  error
+	saveErr    error
+	deletedTo  int // highest id deleted (0 if none)
+}
+
+func (r *purgeRepo) Archive(db orm.DB, table string, cutoff time.Time, w io.Writer) (int, int, error) {
+	if r.archiveErr != nil {
+		return 0, 0, r.archiveErr
+	}
+	_, err := io.WriteString(w, r.lines)
+	return r.count, 100 + r.count, err
+}
+
+func (r *purgeRepo) Delete(db orm.DB, table string, cutoff time.Time, maxID int) (int, error) {
+	if r.deleteErr != nil {
+		return 0, r.deleteErr
+	}
+	r.deletedTo = maxID
+	return r.count, nil
+}
+
+func (r *purgeRepo) SaveRun(db orm.DB, run *sandpiper.PurgeRun) error {
+	return r.saveErr
+}
+
+func TestArchive(t *testing.T) {
+	cases := []struct {
+		name        string
+		repo        *purgeRepo
+		wantErr     error
+		wantRun     sandpiper.PurgeRun
+		wantDeleted int
+	}{
+		{
+			name: "Nothing to purge",
+			repo: &purgeRepo{},
+		},
+		{
+			name:        "Archived then deleted",
+			repo:        &purgeRepo{lines: "{\"id\":101}\n{\"id\":102}\n", count: 2},
+			wantRun:     sandpiper.PurgeRun{Archived: 2, Deleted: 2},
+			wantDeleted: 102,
+		},
+		{
+			name:    "Archive fails (nothing deleted)",
+			repo:    &purgeRepo{archiveErr: errors.New("db error")},
+			wantErr: errors.New("db error"),
+		},
+		{
+			name:    "Delete fails",
+			repo:    &purgeRepo{lines: "{\"id\":101}\n", count: 1, deleteErr: errors.New("db error")},
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "purge")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer os.Remove(f.Name())
+			defer f.Close()
+
+			s := &Purge{sdb: tt.repo}
+			run := sandpiper.PurgeRun{Target: "activity"}
+			err = s.archive(nil, f, &run)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantRun.Archived, run.Archived)
+			assert.Equal(t, tt.wantRun.Deleted, run.Deleted)
+			assert.Equal(t, tt.wantDeleted, tt.repo.deletedTo)
+			if err != nil {
+				return
+			}
+			// the archive is complete before anything is deleted
+			_, _ = f.Seek(0, io.SeekStart)
+			zr, err := gzip.NewReader(f)
+			if assert.NoError(t, err) {
+				b, err := ioutil.ReadAll(zr)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.repo.lines, string(b))
+			}
+		})
+	}
+}
+
+func TestPurgeTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "purge")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	s := &Purge{sdb: &purgeRepo{}, days: map[string]int{"activity": 0}, dir: dir}
+	run := sandpiper.PurgeRun{Target: "activity", StartedAt: time.Now()}
+	err = s.purgeTable(&run)
+	assert.EqualError(t, err, "days kept must be positive (not 0)")
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Empty(t, files)
+}
+
+func TestPurgeRunNotRecorded(t *testing.T) {
+	// the run is returned with both errors (since it is missing from the purge history)
+	s := &Purge{sdb: &purgeRepo{saveErr: errors.New("connection refused")}, days: map[string]int{"activity": 0}}
+	runs := s.Purge()
+	if assert.Equal(t, 1, len(runs)) {
+		assert.Equal(t, "days kept must be positive (not 0); run not recorded: connection refused", runs[0].Error)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package purge
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/purge"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	pl "github.com/sandpiper-framework/sandpiper/pkg/api/purge/logging"
+	pt "github.com/sandpiper-framework/sandpiper/pkg/api/purge/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the purge service to its logger and transport mechanisms
+func Register(db *database.DB, log sandpiper.Logger, v1 *echo.Group, cfg *config.Retention) {
+	svc := purge.Initialize(db, rbac.New(db.Settings.ServerRole), cfg)
+	svc.StartPurge(log) // archive and delete old rows in the background
+	ls := pl.ServiceLogger(svc, log)
+	pt.NewHTTP(ls, v1)
+}
@@ -0,0 +1,63 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package purge
+
+// purge service
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/purge/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Service represents purge application interface
+type Service interface {
+	Status(echo.Context) (*sandpiper.PurgeStatus, error)
+	Run(echo.Context) ([]sandpiper.PurgeRun, error)
+}
+
+// New creates new purge application service
+func New(db *database.DB, sdb Repository, rbac RBAC, cfg *config.Retention) *Purge {
+	if cfg == nil {
+		cfg = &config.Retention{}
+	}
+	return &Purge{db: db.DB, sdb: sdb, rbac: rbac, days: cfg.Policies(), dir: cfg.ArchivePath(), interval: cfg.PurgeInterval()}
+}
+
+// Initialize initializes purge application service with defaults
+func Initialize(db *database.DB, rbac RBAC, cfg *config.Retention) *Purge {
+	return New(db, pgsql.NewPurge(), rbac, cfg)
+}
+
+// Purge represents purge application service
+type Purge struct {
+	db       *pg.DB
+	sdb      Repository
+	rbac     RBAC
+	days     map[string]int // days kept by table
+	dir      string         // archive directory
+	interval time.Duration
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	Archive(orm.DB, string, time.Time, io.Writer) (int, int, error)
+	Delete(orm.DB, string, time.Time, int) (int, error)
+	SaveRun(orm.DB, *sandpiper.PurgeRun) error
+	LastRuns(orm.DB) ([]sandpiper.PurgeRun, error)
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+}
@@ -0,0 +1,44 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// purge service routing functions
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/purge"
+)
+
+// HTTP represents purge http service
+type HTTP struct {
+	svc purge.Service
+}
+
+// NewHTTP creates new purge http service
+func NewHTTP(svc purge.Service, er *echo.Group) {
+	h := HTTP{svc}
+	pr := er.Group("/purge")
+	pr.GET("", h.status)
+	pr.POST("/run", h.run) // purge now (instead of waiting for the next interval)
+}
+
+func (h *HTTP) status(c echo.Context) error {
+	result, err := h.svc.Status(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) run(c echo.Context) error {
+	result, err := h.svc.Run(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,115 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package release
+
+// release service logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/release"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the release service
+func ServiceLogger(svc release.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents release logging service
+type LogService struct {
+	release.Service
+	logger sandpiper.Logger
+}
+
+const source = "release"
+
+// Create logging
+func (ls *LogService) Create(c echo.Context, req sandpiper.SliceRelease) (resp *sandpiper.SliceRelease, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Create release request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Create(c, req)
+}
+
+// List logging
+func (ls *LogService) List(c echo.Context, sliceID uuid.UUID, req *params.Params) (resp []sandpiper.SliceRelease, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List release request", err,
+			map[string]interface{}{
+				"slice_id": sliceID,
+				"req":      req,
+				"resp":     fmt.Sprintf("Count: %d", len(resp)),
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.List(c, sliceID, req)
+}
+
+// View logging
+func (ls *LogService) View(c echo.Context, req uuid.UUID) (resp *sandpiper.SliceRelease, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "View release request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.View(c, req)
+}
+
+// Delete logging
+func (ls *LogService) Delete(c echo.Context, req uuid.UUID) (err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Delete release request", err,
+			map[string]interface{}{
+				"req":  req,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Delete(c, req)
+}
+
+// Compare logging
+func (ls *LogService) Compare(c echo.Context, from, to uuid.UUID) (resp *sandpiper.ReleaseDiff, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Compare release request", err,
+			map[string]interface{}{
+				"from": from,
+				"to":   to,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Compare(c, from, to)
+}
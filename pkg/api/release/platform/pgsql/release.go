@@ -0,0 +1,309 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// release service database access
+
+// A release freezes a slice's content under a version label. The grains (as stored, so still
+// encrypted if the slice is) and metadata are copied, so later changes to the slice don't
+// affect it. Its hash is calculated the same way as the slice's, so a secondary syncing a
+// pinned release can verify its own copy against it.
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	slicesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slice/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Custom errors
+var (
+	ErrAlreadyExists   = echo.NewHTTPError(http.StatusInternalServerError, "Release version already exists for this slice.")
+	ErrReleaseNotFound = echo.NewHTTPError(http.StatusNotFound, "Release not found.")
+	ErrSliceNotFound   = echo.NewHTTPError(http.StatusNotFound, "Slice not found.")
+	ErrCompositeSlice  = echo.NewHTTPError(http.StatusConflict, "Composite slices are released through their members.")
+	ErrReleasePinned   = echo.NewHTTPError(http.StatusConflict, "Release is pinned by a subscription.")
+	ErrDifferentSlices = echo.NewHTTPError(http.StatusBadRequest, "Releases must be from the same slice.")
+)
+
+// Release represents the client for slice_releases table
+type Release struct{}
+
+// NewRelease returns a new release database instance
+func NewRelease() *Release {
+	return &Release{}
+}
+
+// Create cuts a new release from the current content of a slice (should be run in a transaction)
+func (s *Release) Create(db orm.DB, rel *sandpiper.SliceRelease) (*sandpiper.SliceRelease, error) {
+	slice := &sandpiper.Slice{ID: rel.SliceID}
+	err := db.Model(slice).Column("id", "composite").WherePK().Select()
+	switch {
+	case err == pg.ErrNoRows:
+		return nil, ErrSliceNotFound
+	case err != nil:
+		return nil, err
+	case slice.Composite:
+		return nil, ErrCompositeSlice
+	}
+	if err := checkDuplicate(db, rel.SliceID, rel.Version); err != nil {
+		return nil, err
+	}
+
+	// freeze content information and metadata
+	rel.ContentHash, rel.ContentCount, err = slicesvc.HashSlice(db, rel.SliceID)
+	if err != nil {
+		return nil, err
+	}
+	rel.ContentDate = time.Now()
+	var meta sandpiper.MetaArray
+	if err := db.Model(&meta).Where("slice_id = ?", rel.SliceID).Select(); err != nil {
+		return nil, err
+	}
+	rel.Metadata = meta.ToMap(rel.SliceID)
+
+	if err := db.Insert(rel); err != nil {
+		return nil, err
+	}
+
+	// copy grains (as stored)
+	_, err = db.Exec(`
+		INSERT INTO release_grains (release_id, grain_id, grain_key, encoding, payload, source, key_id, created_at)
+		SELECT ?, id, grain_key, encoding, payload, source, key_id, created_at FROM grains WHERE slice_id = ?`,
+		rel.ID, rel.SliceID)
+	if err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// View returns a single release by ID (assumes allowed to do this)
+func (s *Release) View(db orm.DB, id uuid.UUID) (*sandpiper.SliceRelease, error) {
+	rel := &sandpiper.SliceRelease{ID: id}
+	if err := db.Model(rel).WherePK().Select(); err != nil {
+		return nil, selectError(err)
+	}
+	return rel, nil
+}
+
+// List returns releases (newest first), optionally for a slice, limited by scope and paginated
+func (s *Release) List(db orm.DB, sliceID uuid.UUID, sc *sandpiper.Scope, p *params.Params) (rels []sandpiper.SliceRelease, err error) {
+	q := db.Model(&rels).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if sliceID != uuid.Nil {
+		q = q.Where("slice_id = ?", sliceID)
+	}
+	if sc != nil {
+		q = q.Where("slice_id IN (?)", slicesvc.AccessibleSlices(db, sc.ID))
+	}
+	p.Paging.Count, err = q.Order("created_at DESC").SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return rels, nil
+}
+
+// Delete removes a release (and its grains) if not pinned by a subscription
+func (s *Release) Delete(db orm.DB, rel *sandpiper.SliceRelease) error {
+	pinned, err := db.Model((*sandpiper.Subscription)(nil)).Where("release_id = ?", rel.ID).Exists()
+	if err != nil {
+		return err
+	}
+	if pinned {
+		return ErrReleasePinned
+	}
+	return db.Delete(rel)
+}
+
+// Compare returns the differences (by grain key) between two releases of a slice. A nil "to"
+// release compares with the latest slice content.
+func (s *Release) Compare(db orm.DB, from, to *sandpiper.SliceRelease) (*sandpiper.ReleaseDiff, error) {
+	diff := &sandpiper.ReleaseDiff{
+		SliceID: from.SliceID,
+		From:    from.Version,
+		To:      sandpiper.LatestRelease,
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []string{},
+	}
+
+	before, err := releaseKeys(db, from.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var after map[string]uuid.UUID
+	var meta sandpiper.MetaMap
+	if to != nil {
+		if to.SliceID != from.SliceID {
+			return nil, ErrDifferentSlices
+		}
+		diff.To = to.Version
+		if after, err = releaseKeys(db, to.ID); err != nil {
+			return nil, err
+		}
+		meta = to.Metadata
+	} else {
+		if after, err = sliceKeys(db, from.SliceID); err != nil {
+			return nil, err
+		}
+		var ma sandpiper.MetaArray
+		if err := db.Model(&ma).Where("slice_id = ?", from.SliceID).Select(); err != nil {
+			return nil, err
+		}
+		meta = ma.ToMap(from.SliceID)
+	}
+
+	for key, id := range after {
+		prev, ok := before[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, key)
+		case prev != id:
+			diff.Changed = append(diff.Changed, key)
+		default:
+			diff.Unchanged++
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	diff.MetaChanged = !meta.Equals(from.Metadata)
+
+	return diff, nil
+}
+
+// SliceAccess checks if a slice is included in a company's subscriptions (directly or through
+// a composite slice)
+func (s *Release) SliceAccess(db orm.DB, companyID, sliceID uuid.UUID) bool {
+	found, err := db.Model((*sandpiper.Slice)(nil)).
+		Where("id = ?", sliceID).
+		Where("id IN (?)", slicesvc.AccessibleSlices(db, companyID)).Exists()
+	return err == nil && found
+}
+
+// PinnedRelease returns the release a company's subscription to a slice is pinned to (or nil
+// if tracking the latest content)
+func PinnedRelease(db orm.DB, companyID, sliceID uuid.UUID) (*sandpiper.SliceRelease, error) {
+	rel := new(sandpiper.SliceRelease)
+	err := db.Model(rel).
+		Join("INNER JOIN subscriptions AS sub ON sub.release_id = slice_release.id").
+		Where("sub.company_id = ?", companyID).
+		Where("sub.slice_id = ?", sliceID).Select()
+	switch err {
+	case pg.ErrNoRows:
+		return nil, nil
+	case nil:
+		return rel, nil
+	default:
+		return nil, err
+	}
+}
+
+// Grains returns the grains (as stored) of a release (with brief or all fields)
+func Grains(db orm.DB, rel *sandpiper.SliceRelease, briefFlag bool) ([]sandpiper.Grain, error) {
+	var rgs []sandpiper.ReleaseGrain
+
+	// brief is only the ids (otherwise all columns)
+	q := db.Model(&rgs).Where("release_id = ?", rel.ID).Order("grain_id")
+	if briefFlag {
+		q = q.Column("grain_id")
+	}
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+
+	grains := make([]sandpiper.Grain, len(rgs))
+	for i := range rgs {
+		grains[i] = rgs[i].Grain(rel.SliceID)
+	}
+	return grains, nil
+}
+
+// Grain returns a grain (as stored) by id from any release, used when the grain is no longer
+// part of the slice (grains are never updated, so every copy is the same)
+func Grain(db orm.DB, grainID uuid.UUID) (*sandpiper.Grain, error) {
+	rg := new(sandpiper.ReleaseGrain)
+	if err := db.Model(rg).Where("grain_id = ?", grainID).Limit(1).Select(); err != nil {
+		return nil, err
+	}
+	rel := &sandpiper.SliceRelease{ID: rg.ReleaseID}
+	if err := db.Model(rel).Column("slice_id").WherePK().Select(); err != nil {
+		return nil, err
+	}
+	grain := rg.Grain(rel.SliceID)
+	return &grain, nil
+}
+
+// GrainAccess checks if a grain is included in a release of a slice a company can access
+func GrainAccess(db orm.DB, companyID, grainID uuid.UUID) bool {
+	found, err := db.Model((*sandpiper.ReleaseGrain)(nil)).
+		Join("INNER JOIN slice_releases AS r ON r.id = release_grain.release_id").
+		Where("release_grain.grain_id = ?", grainID).
+		Where("r.slice_id IN (?)", slicesvc.AccessibleSlices(db, companyID)).Exists()
+	return err == nil && found
+}
+
+// releaseKeys returns the grain id for each grain key in a release
+func releaseKeys(db orm.DB, releaseID uuid.UUID) (map[string]uuid.UUID, error) {
+	var rgs []sandpiper.ReleaseGrain
+	err := db.Model(&rgs).Column("grain_id", "grain_key").Where("release_id = ?", releaseID).Select()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]uuid.UUID, len(rgs))
+	for _, rg := range rgs {
+		keys[rg.Key] = rg.GrainID
+	}
+	return keys, nil
+}
+
+// sliceKeys returns the grain id for each grain key in the latest slice content
+func sliceKeys(db orm.DB, sliceID uuid.UUID) (map[string]uuid.UUID, error) {
+	var grains []sandpiper.Grain
+	err := db.Model(&grains).Column("id", "grain_key").Where("slice_id = ?", sliceID).Select()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]uuid.UUID, len(grains))
+	for _, g := range grains {
+		keys[g.Key] = g.ID
+	}
+	return keys, nil
+}
+
+// checkDuplicate returns an error if the version is already used for the slice
+func checkDuplicate(db orm.DB, sliceID uuid.UUID, version string) error {
+	found, err := db.Model((*sandpiper.SliceRelease)(nil)).
+		Where("slice_id = ?", sliceID).
+		Where("lower(version) = ?", strings.ToLower(version)).Exists()
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+func selectError(err error) error {
+	if err == pg.ErrNoRows {
+		return ErrReleaseNotFound
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/release/platform/pgsql"
+	slicesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slice/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+var (
+	sliceID = uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	grainA  = uuid.MustParse("a0000000-0000-0000-0000-000000000000")
+	grainB  = uuid.MustParse("b0000000-0000-0000-0000-000000000000")
+	grainC  = uuid.MustParse("c0000000-0000-0000-0000-000000000000")
+)
+
+func TestCreate(t *testing.T) {
+	content := func(db *mockdb.DB) *mockdb.DB {
+		return db.
+			On(`SELECT "id", "composite" FROM "slices"`, mockdb.Row{"id": sliceID, "composite": false}).
+			On(`FROM "slice_metadata"`,
+				mockdb.Row{"slice_id": sliceID, "key": "pcdb", "value": "2020-01"},
+				mockdb.Row{"slice_id": sliceID, "key": "vcdb", "value": "2020-02"}).
+			On(`SELECT "id" FROM "grains"`, mockdb.Row{"id": grainA}, mockdb.Row{"id": grainB})
+	}
+
+	t.Run("Hash matches the slice", func(t *testing.T) {
+		db := content(&mockdb.DB{})
+		rel, err := pgsql.NewRelease().Create(db, &sandpiper.SliceRelease{SliceID: sliceID, Version: "1.0"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 2, rel.ContentCount)
+		assert.Equal(t, sandpiper.MetaMap{"pcdb": "2020-01", "vcdb": "2020-02"}, rel.Metadata)
+		assert.Equal(t, 1, len(db.Find(`INSERT INTO release_grains`)))
+
+		// the slice's own hash (as refreshed) is the same for the same content
+		hash, count, err := slicesvc.HashSlice(db, sliceID)
+		assert.NoError(t, err)
+		assert.Equal(t, rel.ContentHash, hash)
+		assert.Equal(t, rel.ContentCount, count)
+	})
+
+	t.Run("Composite slice", func(t *testing.T) {
+		db := (&mockdb.DB{}).On(`SELECT "id", "composite" FROM "slices"`, mockdb.Row{"id": sliceID, "composite": true})
+		_, err := pgsql.NewRelease().Create(db, &sandpiper.SliceRelease{SliceID: sliceID, Version: "1.0"})
+		assert.Equal(t, pgsql.ErrCompositeSlice, err)
+	})
+
+	t.Run("Duplicate version", func(t *testing.T) {
+		db := content(&mockdb.DB{}).On(`lower(version) = '1.0'`, mockdb.Row{})
+		_, err := pgsql.NewRelease().Create(db, &sandpiper.SliceRelease{SliceID: sliceID, Version: "1.0"})
+		assert.Equal(t, pgsql.ErrAlreadyExists, err)
+		assert.Empty(t, db.Find(`INSERT`))
+	})
+}
+
+func TestCompare(t *testing.T) {
+	from := &sandpiper.SliceRelease{ID: uuid.New(), SliceID: sliceID, Version: "1.0", Metadata: sandpiper.MetaMap{"pcdb": "2020-01"}}
+	to := &sandpiper.SliceRelease{ID: uuid.New(), SliceID: sliceID, Version: "2.0", Metadata: sandpiper.MetaMap{"pcdb": "2020-01"}}
+	changed := uuid.MustParse("d0000000-0000-0000-0000-000000000000")
+
+	releaseGrains := func(id uuid.UUID) string {
+		return fmt.Sprintf(`FROM "release_grains" AS "release_grain" WHERE (release_id = '%s')`, id)
+	}
+	db := (&mockdb.DB{}).
+		On(releaseGrains(from.ID),
+			mockdb.Row{"grain_id": grainA, "grain_key": "a"},
+			mockdb.Row{"grain_id": grainB, "grain_key": "b"}).
+		On(releaseGrains(to.ID),
+			mockdb.Row{"grain_id": changed, "grain_key": "b"},
+			mockdb.Row{"grain_id": grainC, "grain_key": "c"}).
+		On(`FROM "grains"`,
+			mockdb.Row{"id": grainA, "grain_key": "a"},
+			mockdb.Row{"id": grainB, "grain_key": "b"}).
+		On(`FROM "slice_metadata"`, mockdb.Row{"slice_id": sliceID, "key": "pcdb", "value": "2020-02"})
+
+	t.Run("Releases", func(t *testing.T) {
+		diff, err := pgsql.NewRelease().Compare(db, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, &sandpiper.ReleaseDiff{
+			SliceID: sliceID,
+			From:    "1.0",
+			To:      "2.0",
+			Added:   []string{"c"},
+			Removed: []string{"a"},
+			Changed: []string{"b"},
+		}, diff)
+	})
+
+	t.Run("Latest content", func(t *testing.T) {
+		diff, err := pgsql.NewRelease().Compare(db, from, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &sandpiper.ReleaseDiff{
+			SliceID:     sliceID,
+			From:        "1.0",
+			To:          sandpiper.LatestRelease,
+			Added:       []string{},
+			Removed:     []string{},
+			Changed:     []string{},
+			Unchanged:   2,
+			MetaChanged: true,
+		}, diff)
+	})
+
+	t.Run("Different slices", func(t *testing.T) {
+		other := &sandpiper.SliceRelease{ID: uuid.New(), SliceID: uuid.New(), Version: "3.0"}
+		_, err := pgsql.NewRelease().Compare(db, from, other)
+		assert.Equal(t, pgsql.ErrDifferentSlices, err)
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package release
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/release"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	rl "github.com/sandpiper-framework/sandpiper/pkg/api/release/logging"
+	rt "github.com/sandpiper-framework/sandpiper/pkg/api/release/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the release service to its logger and transport mechanisms
+func Register(db *database.DB, log sandpiper.Logger, v1 *echo.Group) {
+	svc := release.Initialize(db, rbac.New(db.Settings.ServerRole))
+	ls := rl.ServiceLogger(svc, log)
+	rt.NewHTTP(ls, v1)
+}
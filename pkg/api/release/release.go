@@ -0,0 +1,78 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package release contains services for slice releases (named snapshots of slice content that
+// subscriptions can be pinned to).
+package release
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Create cuts a new release from the current content of a slice (if administrator)
+func (s *Release) Create(c echo.Context, req sandpiper.SliceRelease) (result *sandpiper.SliceRelease, err error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		result, err = s.sdb.Create(tx, &req)
+		return err
+	})
+	return result, err
+}
+
+// List returns releases (optionally for a slice) for subscribed slices
+func (s *Release) List(c echo.Context, sliceID uuid.UUID, p *params.Params) ([]sandpiper.SliceRelease, error) {
+	q, err := s.rbac.EnforceScope(c)
+	if err != nil {
+		return nil, err
+	}
+	return s.sdb.List(s.db, sliceID, q, p)
+}
+
+// View returns a single release if its slice is subscribed to the current user's company
+func (s *Release) View(c echo.Context, id uuid.UUID) (*sandpiper.SliceRelease, error) {
+	rel, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	au := s.rbac.CurrentUser(c)
+	if !au.AtLeast(sandpiper.AdminRole) && !s.sdb.SliceAccess(s.db, au.CompanyID, rel.SliceID) {
+		return nil, echo.ErrForbidden
+	}
+	return rel, nil
+}
+
+// Delete deletes a release (if administrator and not pinned by a subscription)
+func (s *Release) Delete(c echo.Context, id uuid.UUID) error {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return err
+	}
+	rel, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return err
+	}
+	return s.sdb.Delete(s.db, rel)
+}
+
+// Compare returns the differences between two releases of a slice (or between a release and
+// the latest content if "to" is not provided)
+func (s *Release) Compare(c echo.Context, fromID, toID uuid.UUID) (*sandpiper.ReleaseDiff, error) {
+	from, err := s.View(c, fromID)
+	if err != nil {
+		return nil, err
+	}
+	var to *sandpiper.SliceRelease
+	if toID != uuid.Nil {
+		if to, err = s.View(c, toID); err != nil {
+			return nil, err
+		}
+	}
+	return s.sdb.Compare(s.db, from, to)
+}
@@ -0,0 +1,62 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package release
+
+// release service
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/release/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Service represents release application interface
+type Service interface {
+	Create(echo.Context, sandpiper.SliceRelease) (*sandpiper.SliceRelease, error)
+	List(echo.Context, uuid.UUID, *params.Params) ([]sandpiper.SliceRelease, error)
+	View(echo.Context, uuid.UUID) (*sandpiper.SliceRelease, error)
+	Delete(echo.Context, uuid.UUID) error
+	Compare(echo.Context, uuid.UUID, uuid.UUID) (*sandpiper.ReleaseDiff, error)
+}
+
+// New creates new release application service
+func New(db *database.DB, sdb Repository, rbac RBAC) *Release {
+	return &Release{db: db.DB, sdb: sdb, rbac: rbac}
+}
+
+// Initialize initializes release application service with defaults
+func Initialize(db *database.DB, rbac RBAC) *Release {
+	return New(db, pgsql.NewRelease(), rbac)
+}
+
+// Release represents release application service
+type Release struct {
+	db   *pg.DB
+	sdb  Repository
+	rbac RBAC
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	Create(orm.DB, *sandpiper.SliceRelease) (*sandpiper.SliceRelease, error)
+	View(orm.DB, uuid.UUID) (*sandpiper.SliceRelease, error)
+	List(orm.DB, uuid.UUID, *sandpiper.Scope, *params.Params) ([]sandpiper.SliceRelease, error)
+	Delete(orm.DB, *sandpiper.SliceRelease) error
+	Compare(orm.DB, *sandpiper.SliceRelease, *sandpiper.SliceRelease) (*sandpiper.ReleaseDiff, error)
+	SliceAccess(orm.DB, uuid.UUID, uuid.UUID) bool
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	CurrentUser(echo.Context) *sandpiper.AuthUser
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+	EnforceScope(echo.Context) (*sandpiper.Scope, error)
+}
@@ -0,0 +1,144 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// release routing functions
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/release"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents release http service
+type HTTP struct {
+	svc release.Service
+}
+
+// NewHTTP creates new release http service
+func NewHTTP(svc release.Service, er *echo.Group) {
+	h := HTTP{svc}
+	rr := er.Group("/releases")
+	rr.POST("", h.create)
+	rr.GET("", h.list)            // ?slice=<slice_id>
+	rr.GET("/compare", h.compare) // ?from=<release_id>&to=<release_id> (latest content if no "to")
+	rr.GET("/:id", h.view)
+	rr.DELETE("/:id", h.delete)
+}
+
+// Custom errors
+var (
+	ErrInvalidReleaseUUID = echo.NewHTTPError(http.StatusBadRequest, "invalid release uuid")
+	ErrInvalidSliceUUID   = echo.NewHTTPError(http.StatusBadRequest, "invalid slice uuid")
+)
+
+// Release create request
+type createReq struct {
+	ID          uuid.UUID `json:"id"` // optional
+	SliceID     uuid.UUID `json:"slice_id" validate:"required"`
+	Version     string    `json:"version" validate:"required"`
+	Description string    `json:"description"`
+}
+
+func (r createReq) id() uuid.UUID {
+	if r.ID == uuid.Nil {
+		return uuid.New()
+	}
+	return r.ID
+}
+
+// create cuts a release from the current slice content
+func (h *HTTP) create(c echo.Context) error {
+	r := new(createReq)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	result, err := h.svc.Create(c, sandpiper.SliceRelease{
+		ID:          r.id(),
+		SliceID:     r.SliceID,
+		Version:     r.Version,
+		Description: r.Description,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *HTTP) list(c echo.Context) error {
+	sliceID, err := queryUUID(c, "slice", ErrInvalidSliceUUID)
+	if err != nil {
+		return err
+	}
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.List(c, sliceID, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.ReleasesPaginated{Releases: result, Paging: p.Paging})
+}
+
+func (h *HTTP) view(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidReleaseUUID
+	}
+	result, err := h.svc.View(c, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidReleaseUUID
+	}
+	if err := h.svc.Delete(c, id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *HTTP) compare(c echo.Context) error {
+	from, err := queryUUID(c, "from", ErrInvalidReleaseUUID)
+	if err != nil {
+		return err
+	}
+	if from == uuid.Nil {
+		return ErrInvalidReleaseUUID
+	}
+	to, err := queryUUID(c, "to", ErrInvalidReleaseUUID)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.Compare(c, from, to)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// queryUUID returns an optional uuid query parameter (uuid.Nil if not provided)
+func queryUUID(c echo.Context, name string, invalid error) (uuid.UUID, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, invalid
+	}
+	return id, nil
+}
@@ -0,0 +1,122 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package request
+
+// subscription request service logger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the subscription request service
+func ServiceLogger(svc request.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents subscription request logging service
+type LogService struct {
+	request.Service
+	logger sandpiper.Logger
+}
+
+const source = "request"
+
+// Create logging
+func (ls *LogService) Create(c echo.Context, req sandpiper.SubscriptionRequest) (resp *sandpiper.SubscriptionRequest, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Create subscription request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Create(c, req)
+}
+
+// List logging
+func (ls *LogService) List(c echo.Context, status string, req *params.Params) (resp []sandpiper.SubscriptionRequest, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List subscription request", err,
+			map[string]interface{}{
+				"status": status,
+				"req":    req,
+				"resp":   resp,
+				"took":   time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.List(c, status, req)
+}
+
+// View logging
+func (ls *LogService) View(c echo.Context, req uuid.UUID) (resp *sandpiper.SubscriptionRequest, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "View subscription request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.View(c, req)
+}
+
+// Approve logging (without the api key)
+func (ls *LogService) Approve(c echo.Context, req uuid.UUID, sliceIDs []uuid.UUID, note string) (resp *sandpiper.RequestDecision, err error) {
+	defer func(begin time.Time) {
+		var subs []sandpiper.Subscription
+		if resp != nil {
+			subs = resp.Subscriptions
+		}
+		ls.logger.Log(
+			c,
+			source, "Approve subscription request", err,
+			map[string]interface{}{
+				"req":       req,
+				"slice_ids": sliceIDs,
+				"note":      note,
+				"resp":      subs,
+				"took":      time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Approve(c, req, sliceIDs, note)
+}
+
+// Reject logging
+func (ls *LogService) Reject(c echo.Context, req uuid.UUID, note string) (resp *sandpiper.SubscriptionRequest, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Reject subscription request", err,
+			map[string]interface{}{
+				"req":  req,
+				"note": note,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Reject(c, req, note)
+}
@@ -0,0 +1,237 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// subscription request service database access
+
+import (
+	"net/http"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	companysvc "github.com/sandpiper-framework/sandpiper/pkg/api/company/platform/pgsql"
+	subsvc "github.com/sandpiper-framework/sandpiper/pkg/api/subscription/platform/pgsql"
+	usersvc "github.com/sandpiper-framework/sandpiper/pkg/api/user/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Custom errors
+var (
+	ErrRequestNotFound = echo.NewHTTPError(http.StatusNotFound, "Subscription request not found.")
+	ErrNotPending      = echo.NewHTTPError(http.StatusConflict, "Subscription request was already decided.")
+	ErrSliceNotFound   = echo.NewHTTPError(http.StatusNotFound, "Requested slice not found.")
+	ErrNoSlices        = echo.NewHTTPError(http.StatusBadRequest, "No slices to subscribe to.")
+	ErrServerInUse     = echo.NewHTTPError(http.StatusConflict, "Server id already belongs to a company.")
+)
+
+// Request represents the client for subscription_requests table
+type Request struct{}
+
+// NewRequest returns a new subscription request database instance
+func NewRequest() *Request {
+	return &Request{}
+}
+
+// Create saves a new (pending) subscription request. Used by the signup page and the api.
+func (s *Request) Create(db orm.DB, req *sandpiper.SubscriptionRequest) (*sandpiper.SubscriptionRequest, error) {
+	if err := checkSlices(db, req.SliceIDs); err != nil {
+		return nil, err
+	}
+	if req.CompanyID != nil {
+		// an existing company (so use its name)
+		company := &sandpiper.Company{ID: *req.CompanyID}
+		if err := db.Model(company).Column("name").WherePK().Select(); err != nil {
+			return nil, err
+		}
+		req.CompanyName = company.Name
+	} else if err := checkServer(db, req.ServerID); err != nil {
+		// a new company can't claim the server id (i.e. company id) of an existing one
+		return nil, err
+	}
+	req.Status = sandpiper.RequestPending
+	if err := db.Insert(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// View returns a single subscription request by ID (assumes allowed to do this)
+func (s *Request) View(db orm.DB, id uuid.UUID) (*sandpiper.SubscriptionRequest, error) {
+	req := &sandpiper.SubscriptionRequest{ID: id}
+	if err := db.Model(req).WherePK().Select(); err != nil {
+		if err == pg.ErrNoRows {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+	return req, nil
+}
+
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("subscription_request", "id", "company_id", "company_name",
+		"contact_name", "email", "server_id", "sync_addr", "kind", "status", "decided_by",
+		"decided_at", "created_at"),
+	Sort: params.Columns("subscription_request", "company_name", "contact_name", "email",
+		"kind", "status", "decided_at", "created_at"),
+}
+
+// List returns subscription requests (newest first), optionally by status and limited by scope
+func (s *Request) List(db orm.DB, status string, sc *sandpiper.Scope, p *params.Params) (reqs []sandpiper.SubscriptionRequest, err error) {
+	q := db.Model(&reqs).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if sc != nil {
+		q = q.Where(sc.Condition, sc.ID)
+	}
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "created_at DESC"); err != nil {
+		return nil, err
+	}
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// Company returns the company making a request, adding it first if new (using the server id
+// as its company id, which must not belong to another company)
+func (s *Request) Company(db orm.DB, req *sandpiper.SubscriptionRequest) (*sandpiper.Company, error) {
+	if req.CompanyID != nil {
+		company := &sandpiper.Company{ID: *req.CompanyID}
+		if err := db.Model(company).WherePK().Select(); err != nil {
+			return nil, err
+		}
+		return company, nil
+	}
+
+	id := req.ServerID
+	if id == uuid.Nil {
+		id = uuid.New()
+	} else if err := checkServer(db, id); err != nil {
+		return nil, err
+	}
+
+	// sync_addr is not used on the primary for a secondary company, but must be unique
+	addr := req.SyncAddr
+	if addr == "" {
+		addr = id.String()
+	}
+	return companysvc.NewCompany().Create(db, sandpiper.Company{
+		ID:       id,
+		Name:     req.CompanyName,
+		SyncAddr: addr,
+		Active:   true,
+	})
+}
+
+// SyncUser returns a company's sync user (creating one if necessary) with a new username
+func (s *Request) SyncUser(db orm.DB, companyID uuid.UUID) (*sandpiper.User, error) {
+	return usersvc.NewUser().CompanySyncUser(db, companyID)
+}
+
+// UpdateSyncUser saves the changed username and password of a sync user
+func (s *Request) UpdateSyncUser(db orm.DB, usr *sandpiper.User) error {
+	return usersvc.NewUser().UpdateSyncUser(db, usr)
+}
+
+// Subscribe adds active subscriptions to slices for a company (skipping slices already subscribed)
+func (s *Request) Subscribe(db orm.DB, company *sandpiper.Company, sliceIDs []uuid.UUID) ([]sandpiper.Subscription, error) {
+	if len(sliceIDs) == 0 {
+		return nil, ErrNoSlices
+	}
+	if err := checkSlices(db, sliceIDs); err != nil {
+		return nil, err
+	}
+
+	var slices []sandpiper.Slice
+	err := db.Model(&slices).Column("id", "name").
+		Where("id IN (?)", pg.In(sliceIDs)).
+		Where("id NOT IN (?)", db.Model((*sandpiper.Subscription)(nil)).
+			Column("slice_id").Where("company_id = ?", company.ID)).
+		Order("name").Select()
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]sandpiper.Subscription, 0, len(slices))
+	for _, slice := range slices {
+		sub := sandpiper.Subscription{
+			SubID:       uuid.New(),
+			SliceID:     slice.ID,
+			CompanyID:   company.ID,
+			Description: "Created by subscription request approval",
+			Active:      true,
+		}
+		if sub.Name, err = subsvc.UniqueName(db, sub.SubID, company.Name+" "+slice.Name); err != nil {
+			return nil, err
+		}
+		if err := db.Insert(&sub); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// Decide records the decision for a pending request
+func (s *Request) Decide(db orm.DB, req *sandpiper.SubscriptionRequest) error {
+	res, err := db.Model(req).
+		Column("company_id", "slice_ids", "status", "decided_by", "decided_at", "decision_note", "updated_at").
+		WherePK().Where("status = ?", sandpiper.RequestPending).Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// checkSlices makes sure requested slices exist
+func checkSlices(db orm.DB, sliceIDs []uuid.UUID) error {
+	if len(sliceIDs) == 0 {
+		return nil
+	}
+	count, err := db.Model((*sandpiper.Slice)(nil)).Where("id IN (?)", pg.In(sliceIDs)).Count()
+	if err != nil {
+		return err
+	}
+	if count != len(unique(sliceIDs)) {
+		return ErrSliceNotFound
+	}
+	return nil
+}
+
+// checkServer makes sure a server id is not already used by a company
+func checkServer(db orm.DB, serverID uuid.UUID) error {
+	if serverID == uuid.Nil {
+		return nil
+	}
+	found, err := db.Model((*sandpiper.Company)(nil)).Where("id = ?", serverID).Exists()
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrServerInUse
+	}
+	return nil
+}
+
+func unique(ids []uuid.UUID) map[uuid.UUID]bool {
+	m := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+	return m
+}
@@ -0,0 +1,129 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+var (
+	serverID = uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	sliceID  = uuid.MustParse("20000000-0000-0000-0000-000000000000")
+)
+
+// companyExists answers the check for a company with the server id
+func companyExists(db *mockdb.DB) *mockdb.DB {
+	return db.On(fmt.Sprintf(`FROM "companies" AS "company" WHERE (id = '%s')`, serverID), mockdb.Row{})
+}
+
+func TestCreate(t *testing.T) {
+	cases := []struct {
+		name     string
+		req      sandpiper.SubscriptionRequest
+		db       *mockdb.DB
+		wantErr  error
+		wantName string
+	}{
+		{
+			name: "Signup",
+			req:  sandpiper.SubscriptionRequest{CompanyName: "Acme", ServerID: serverID},
+			db:   &mockdb.DB{},
+		},
+		{
+			name:    "Signup with the server id of a company",
+			req:     sandpiper.SubscriptionRequest{CompanyName: "Acme", ServerID: serverID},
+			db:      companyExists(&mockdb.DB{}),
+			wantErr: pgsql.ErrServerInUse,
+		},
+		{
+			name:     "Existing company (uses its name)",
+			req:      sandpiper.SubscriptionRequest{CompanyID: &serverID, CompanyName: "Wrong", ServerID: serverID, SliceIDs: []uuid.UUID{sliceID}},
+			db:       companyExists(&mockdb.DB{}).On(`SELECT "name" FROM "companies"`, mockdb.Row{"name": "Acme"}).On(`SELECT count(*) FROM "slices"`, mockdb.Row{"count": 1}),
+			wantName: "Acme",
+		},
+		{
+			name:    "Slice not found",
+			req:     sandpiper.SubscriptionRequest{CompanyName: "Acme", ServerID: serverID, SliceIDs: []uuid.UUID{sliceID, uuid.New()}},
+			db:      (&mockdb.DB{}).On(`SELECT count(*) FROM "slices"`, mockdb.Row{"count": 1}),
+			wantErr: pgsql.ErrSliceNotFound,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := pgsql.NewRequest().Create(tt.db, &tt.req)
+			assert.Equal(t, tt.wantErr, err)
+			inserts := tt.db.Find(`INSERT INTO "subscription_requests"`)
+			if tt.wantErr != nil {
+				assert.Empty(t, inserts)
+				return
+			}
+			assert.Equal(t, 1, len(inserts))
+			assert.Equal(t, sandpiper.RequestPending, req.Status)
+			if tt.wantName != "" {
+				assert.Equal(t, tt.wantName, req.CompanyName)
+			}
+		})
+	}
+}
+
+func TestCompany(t *testing.T) {
+	t.Run("New company", func(t *testing.T) {
+		db := &mockdb.DB{}
+		company, err := pgsql.NewRequest().Company(db, &sandpiper.SubscriptionRequest{CompanyName: "Acme", ServerID: serverID})
+		assert.NoError(t, err)
+		assert.Equal(t, serverID, company.ID) // the server id becomes the company id
+		assert.Equal(t, 1, len(db.Find(`INSERT INTO "companies"`)))
+	})
+
+	t.Run("Server id taken since the signup", func(t *testing.T) {
+		db := companyExists(&mockdb.DB{})
+		_, err := pgsql.NewRequest().Company(db, &sandpiper.SubscriptionRequest{CompanyName: "Acme", ServerID: serverID})
+		assert.Equal(t, pgsql.ErrServerInUse, err)
+		assert.Empty(t, db.Find("INSERT"))
+	})
+}
+
+func TestDecide(t *testing.T) {
+	req := &sandpiper.SubscriptionRequest{
+		ID:        uuid.New(),
+		CompanyID: &serverID,
+		SliceIDs:  []uuid.UUID{sliceID},
+		Status:    sandpiper.RequestApproved,
+	}
+
+	// only a pending request can be decided (i.e. one update row)
+	db := (&mockdb.DB{}).On(`UPDATE "subscription_requests"`, mockdb.Row{})
+	assert.NoError(t, pgsql.NewRequest().Decide(db, req))
+	assert.Equal(t, pgsql.ErrNotPending, pgsql.NewRequest().Decide(&mockdb.DB{}, req))
+}
+
+func TestSubscribe(t *testing.T) {
+	company := &sandpiper.Company{ID: serverID, Name: "Acme"}
+
+	_, err := pgsql.NewRequest().Subscribe(&mockdb.DB{}, company, nil)
+	assert.Equal(t, pgsql.ErrNoSlices, err)
+
+	db := (&mockdb.DB{}).
+		On(`SELECT count(*) FROM "slices"`, mockdb.Row{"count": 1}).
+		On(`SELECT "id", "name" FROM "slices"`, mockdb.Row{"id": sliceID, "name": "Brakes"}).
+		On(`lower(name) = 'acme brakes'`, mockdb.Row{}) // name already used
+	subs, err := pgsql.NewRequest().Subscribe(db, company, []uuid.UUID{sliceID})
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(subs)) {
+		assert.Equal(t, sliceID, subs[0].SliceID)
+		assert.Equal(t, serverID, subs[0].CompanyID)
+		assert.True(t, subs[0].Active)
+		assert.Equal(t, fmt.Sprintf("Acme Brakes (%s)", subs[0].SubID.String()[:8]), subs[0].Name)
+	}
+	assert.Equal(t, 1, len(db.Find(`INSERT INTO "subscriptions"`)))
+}
@@ -0,0 +1,24 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package request
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	rl "github.com/sandpiper-framework/sandpiper/pkg/api/request/logging"
+	rt "github.com/sandpiper-framework/sandpiper/pkg/api/request/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the subscription request service to its logger and transport mechanisms
+func Register(db *database.DB, sec request.Securer, log sandpiper.Logger, v1 *echo.Group) {
+	svc := request.Initialize(db, rbac.New(db.Settings.ServerRole), sec)
+	ls := rl.ServiceLogger(svc, log)
+	rt.NewHTTP(ls, v1)
+}
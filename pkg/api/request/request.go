@@ -0,0 +1,229 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package request contains services for subscription requests (asking a primary server for
+// access to slices) and their approval.
+package request
+
+import (
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
+)
+
+// audit trail resources (the same names as the services that otherwise change them)
+const (
+	resource        = "subscription_request"
+	apiKeyResource  = "sync_api_key" // by company id
+	companyResource = "company"
+	userResource    = "user"
+	subResource     = "subscription"
+)
+
+// Create saves a request for access to slices from an existing company (if company admin on a
+// primary server). Prospective companies use the signup page instead.
+func (s *Request) Create(c echo.Context, req sandpiper.SubscriptionRequest) (*sandpiper.SubscriptionRequest, error) {
+	if err := s.rbac.EnforceServerRole(sandpiper.PrimaryServer); err != nil {
+		return nil, err
+	}
+	if err := s.rbac.EnforceRole(c, sandpiper.CompanyAdminRole); err != nil {
+		return nil, err
+	}
+	au := s.rbac.CurrentUser(c)
+	req.CompanyID = &au.CompanyID
+	req.ServerID = au.CompanyID
+	if req.Email == "" {
+		req.Email = au.Email
+	}
+	return s.sdb.Create(s.db, &req)
+}
+
+// List returns subscription requests (optionally by status) that you can view
+func (s *Request) List(c echo.Context, status string, p *params.Params) ([]sandpiper.SubscriptionRequest, error) {
+	q, err := s.rbac.EnforceScope(c)
+	if err != nil {
+		return nil, err
+	}
+	return s.sdb.List(s.db, status, q, p)
+}
+
+// View returns a single subscription request (if admin or from your company)
+func (s *Request) View(c echo.Context, id uuid.UUID) (*sandpiper.SubscriptionRequest, error) {
+	req, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	au := s.rbac.CurrentUser(c)
+	if !au.AtLeast(sandpiper.AdminRole) && (req.CompanyID == nil || *req.CompanyID != au.CompanyID) {
+		return nil, echo.ErrForbidden
+	}
+	return req, nil
+}
+
+// Approve grants a pending request (if administrator) in one step. It adds the company (if
+// new), its sync user with an api key (if it doesn't have one) and subscriptions to the slices
+// (the requested slices unless others are provided), recording the decision. A signup (a new
+// company) can be approved without slices, leaving subscriptions to be added later.
+func (s *Request) Approve(c echo.Context, id uuid.UUID, sliceIDs []uuid.UUID, note string) (result *sandpiper.RequestDecision, err error) {
+	if err := s.enforceAdmin(c); err != nil {
+		return nil, err
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		result, err = s.approve(tx, c, id, sliceIDs, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// approve grants a pending request (should be run in a transaction)
+func (s *Request) approve(tx orm.DB, c echo.Context, id uuid.UUID, sliceIDs []uuid.UUID, note string) (*sandpiper.RequestDecision, error) {
+	result := new(sandpiper.RequestDecision)
+	req, err := s.sdb.View(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != sandpiper.RequestPending {
+		return nil, pgsql.ErrNotPending
+	}
+	before := *req
+	if len(sliceIDs) == 0 {
+		sliceIDs = req.SliceIDs
+	}
+	if len(sliceIDs) == 0 && req.CompanyID != nil {
+		// nothing to grant an existing company
+		return nil, pgsql.ErrNoSlices
+	}
+	company, err := s.sdb.Company(tx, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.CompanyID == nil {
+		if err := auditsvc.Record(tx, c, sandpiper.AuditCreate, companyResource, company.ID, nil, company); err != nil {
+			return nil, err
+		}
+	}
+	if company.SyncUserID == 0 {
+		if result.APIKey, err = s.createAPIKey(tx, c, company.ID); err != nil {
+			return nil, err
+		}
+	}
+	result.Subscriptions = []sandpiper.Subscription{}
+	if len(sliceIDs) > 0 {
+		if result.Subscriptions, err = s.sdb.Subscribe(tx, company, sliceIDs); err != nil {
+			return nil, err
+		}
+		for i := range result.Subscriptions {
+			sub := &result.Subscriptions[i]
+			if err := auditsvc.Record(tx, c, sandpiper.AuditCreate, subResource, sub.SubID, nil, sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+	req.CompanyID = &company.ID
+	req.SliceIDs = sliceIDs
+	s.decide(c, req, sandpiper.RequestApproved, note)
+	result.Request = req
+	if err := s.sdb.Decide(tx, req); err != nil {
+		return nil, err
+	}
+	if err := auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, req.ID, &before, req); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Reject declines a pending request (if administrator), recording the decision
+func (s *Request) Reject(c echo.Context, id uuid.UUID, note string) (*sandpiper.SubscriptionRequest, error) {
+	if err := s.enforceAdmin(c); err != nil {
+		return nil, err
+	}
+	var req *sandpiper.SubscriptionRequest
+	err := s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		req, err = s.reject(tx, c, id, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// reject declines a pending request (should be run in a transaction)
+func (s *Request) reject(tx orm.DB, c echo.Context, id uuid.UUID, note string) (*sandpiper.SubscriptionRequest, error) {
+	req, err := s.sdb.View(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != sandpiper.RequestPending {
+		return nil, pgsql.ErrNotPending
+	}
+	before := *req
+	s.decide(c, req, sandpiper.RequestRejected, note)
+	if err := s.sdb.Decide(tx, req); err != nil {
+		return nil, err
+	}
+	if err := auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, req.ID, &before, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// decide fills in the decision fields of a request
+func (s *Request) decide(c echo.Context, req *sandpiper.SubscriptionRequest, status, note string) {
+	req.Status = status
+	req.DecidedBy = s.rbac.CurrentUser(c).ID
+	req.DecidedAt = time.Now()
+	req.DecisionNote = note
+}
+
+// createAPIKey adds a sync user for a company and returns its api key (like user.CreateAPIKey)
+func (s *Request) createAPIKey(tx orm.DB, c echo.Context, companyID uuid.UUID) (*sandpiper.APIKey, error) {
+	usr, err := s.sdb.SyncUser(tx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auditsvc.Record(tx, c, sandpiper.AuditCreate, userResource, usr.ID, nil, usr); err != nil {
+		return nil, err
+	}
+	pw, err := s.sec.RandomPassword(26)
+	if err != nil {
+		return nil, err
+	}
+	usr.ChangePassword(s.sec.Hash(pw))
+	if err := s.sdb.UpdateSyncUser(tx, usr); err != nil {
+		return nil, err
+	}
+	if err := auditsvc.Record(tx, c, sandpiper.AuditCreate, apiKeyResource, companyID, nil, usr); err != nil {
+		return nil, err
+	}
+	creds := &secure.Credentials{
+		Username: usr.Username,
+		Password: pw,
+	}
+	key, err := creds.APIKey(s.sec.APIKeySecret())
+	if err != nil {
+		return nil, err
+	}
+	return &sandpiper.APIKey{PrimaryID: s.rbac.OurServer().ID, SyncAPIKey: string(key)}, nil
+}
+
+// enforceAdmin makes sure requests are decided by an administrator on a primary server
+func (s *Request) enforceAdmin(c echo.Context) error {
+	if err := s.rbac.EnforceServerRole(sandpiper.PrimaryServer); err != nil {
+		return err
+	}
+	return s.rbac.EnforceRole(c, sandpiper.AdminRole)
+}
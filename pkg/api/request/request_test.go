@@ -0,0 +1,211 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package request
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// requestRepo holds one request and records the approval steps
+type requestRepo struct {
+	Repository
+	req        sandpiper.SubscriptionRequest
+	company    *sandpiper.Company
+	subscribed []uuid.UUID
+	syncUser   *sandpiper.User
+	decided    *sandpiper.SubscriptionRequest
+}
+
+func (r *requestRepo) Create(db orm.DB, req *sandpiper.SubscriptionRequest) (*sandpiper.SubscriptionRequest, error) {
+	return req, nil
+}
+
+func (r *requestRepo) View(orm.DB, uuid.UUID) (*sandpiper.SubscriptionRequest, error) {
+	req := r.req
+	return &req, nil
+}
+
+func (r *requestRepo) Company(orm.DB, *sandpiper.SubscriptionRequest) (*sandpiper.Company, error) {
+	return r.company, nil
+}
+
+func (r *requestRepo) SyncUser(db orm.DB, companyID uuid.UUID) (*sandpiper.User, error) {
+	r.syncUser = &sandpiper.User{Username: "sync", CompanyID: companyID}
+	return r.syncUser, nil
+}
+
+func (r *requestRepo) UpdateSyncUser(orm.DB, *sandpiper.User) error {
+	return nil
+}
+
+func (r *requestRepo) Subscribe(db orm.DB, company *sandpiper.Company, sliceIDs []uuid.UUID) ([]sandpiper.Subscription, error) {
+	r.subscribed = sliceIDs
+	subs := make([]sandpiper.Subscription, len(sliceIDs))
+	for i, id := range sliceIDs {
+		subs[i] = sandpiper.Subscription{SliceID: id, CompanyID: company.ID, Active: true}
+	}
+	return subs, nil
+}
+
+func (r *requestRepo) Decide(db orm.DB, req *sandpiper.SubscriptionRequest) error {
+	r.decided = req
+	return nil
+}
+
+// admin is a primary server administrator
+type admin struct {
+	RBAC
+	user sandpiper.AuthUser
+}
+
+func (a admin) CurrentUser(echo.Context) *sandpiper.AuthUser        { return &a.user }
+func (admin) EnforceRole(echo.Context, sandpiper.AccessLevel) error { return nil }
+func (admin) EnforceServerRole(string) error                        { return nil }
+func (admin) OurServer() *sandpiper.Server                          { return &sandpiper.Server{ID: uuid.New()} }
+
+// securer creates fixed passwords
+type securer struct{}
+
+func (securer) Hash(pw string) string              { return "hashed:" + pw }
+func (securer) RandomPassword(int) (string, error) { return "password", nil }
+func (securer) APIKeySecret() string               { return "u7WJ3kpqyvAkKb7HIfYJoSok2DoqTa9YhaCUhUujqb8=" }
+
+func TestApprove(t *testing.T) {
+	companyID := uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	requested := []uuid.UUID{uuid.MustParse("20000000-0000-0000-0000-000000000000")}
+	chosen := []uuid.UUID{uuid.MustParse("30000000-0000-0000-0000-000000000000")}
+
+	cases := []struct {
+		name       string
+		req        sandpiper.SubscriptionRequest
+		company    sandpiper.Company
+		sliceIDs   []uuid.UUID
+		wantErr    error
+		wantSlices []uuid.UUID
+		wantAPIKey bool
+		wantAudit  []string
+	}{
+		{
+			name:       "Signup (new company gets a sync user)",
+			req:        sandpiper.SubscriptionRequest{Status: sandpiper.RequestPending, ServerID: companyID, SliceIDs: requested},
+			company:    sandpiper.Company{ID: companyID},
+			wantSlices: requested,
+			wantAPIKey: true,
+			wantAudit: []string{"create company", "create user", "create sync_api_key",
+				"create subscription", "update subscription_request"},
+		},
+		{
+			name:       "Slices chosen by the administrator",
+			req:        sandpiper.SubscriptionRequest{Status: sandpiper.RequestPending, CompanyID: &companyID, SliceIDs: requested},
+			company:    sandpiper.Company{ID: companyID, SyncUserID: 5},
+			sliceIDs:   chosen,
+			wantSlices: chosen,
+			wantAudit:  []string{"create subscription", "update subscription_request"},
+		},
+		{
+			name:       "Signup without slices",
+			req:        sandpiper.SubscriptionRequest{Status: sandpiper.RequestPending, ServerID: companyID},
+			company:    sandpiper.Company{ID: companyID},
+			wantAPIKey: true,
+			wantAudit:  []string{"create company", "create user", "create sync_api_key", "update subscription_request"},
+		},
+		{
+			name:    "Existing company without slices",
+			req:     sandpiper.SubscriptionRequest{Status: sandpiper.RequestPending, CompanyID: &companyID},
+			company: sandpiper.Company{ID: companyID, SyncUserID: 5},
+			wantErr: pgsql.ErrNoSlices,
+		},
+		{
+			name:    "Already decided",
+			req:     sandpiper.SubscriptionRequest{Status: sandpiper.RequestRejected, ServerID: companyID, SliceIDs: requested},
+			wantErr: pgsql.ErrNotPending,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &requestRepo{req: tt.req, company: &tt.company}
+			s := &Request{sdb: repo, rbac: admin{user: sandpiper.AuthUser{ID: 7}}, sec: securer{}}
+
+			db := &mockdb.DB{}
+			c := echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())
+			result, err := s.approve(db, c, uuid.New(), tt.sliceIDs, "ok")
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Nil(t, repo.decided)
+				assert.Empty(t, audited(db))
+				return
+			}
+			assert.Equal(t, tt.wantSlices, repo.subscribed)
+			assert.Equal(t, len(tt.wantSlices), len(result.Subscriptions))
+			assert.Equal(t, tt.wantAPIKey, result.APIKey != nil)
+			assert.Equal(t, tt.wantAPIKey, repo.syncUser != nil)
+			assert.Equal(t, tt.wantAudit, audited(db))
+			for _, entry := range db.Find("INSERT INTO audit_log") {
+				assert.NotContains(t, entry, "hashed:") // no passwords
+			}
+			if assert.NotNil(t, repo.decided) {
+				assert.Equal(t, sandpiper.RequestApproved, repo.decided.Status)
+				assert.Equal(t, companyID, *repo.decided.CompanyID)
+				assert.Equal(t, tt.wantSlices, repo.decided.SliceIDs) // the slices actually granted
+				assert.Equal(t, 7, repo.decided.DecidedBy)
+				assert.Equal(t, "ok", repo.decided.DecisionNote)
+			}
+		})
+	}
+}
+
+func TestReject(t *testing.T) {
+	repo := &requestRepo{req: sandpiper.SubscriptionRequest{Status: sandpiper.RequestPending}}
+	s := &Request{sdb: repo, rbac: admin{user: sandpiper.AuthUser{ID: 7}}}
+	db := &mockdb.DB{}
+	c := echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())
+	req, err := s.reject(db, c, uuid.New(), "no")
+	assert.NoError(t, err)
+	assert.Equal(t, sandpiper.RequestRejected, req.Status)
+	assert.Equal(t, req, repo.decided)
+	assert.Equal(t, []string{"update subscription_request"}, audited(db))
+
+	repo = &requestRepo{req: sandpiper.SubscriptionRequest{Status: sandpiper.RequestApproved}}
+	s.sdb = repo
+	db = &mockdb.DB{}
+	_, err = s.reject(db, c, uuid.New(), "no")
+	assert.Equal(t, pgsql.ErrNotPending, err)
+	assert.Nil(t, repo.decided)
+	assert.Empty(t, audited(db))
+}
+
+// audited returns the action and resource of each audit entry saved (in order)
+func audited(db *mockdb.DB) []string {
+	var entries []string
+	for _, sql := range db.Find("INSERT INTO audit_log") {
+		if m := auditEntry.FindStringSubmatch(sql); m != nil {
+			entries = append(entries, m[1]+" "+m[2])
+		}
+	}
+	return entries
+}
+
+var auditEntry = regexp.MustCompile(`'(create|update|delete)', '(\w+)'`)
+
+func TestCreate(t *testing.T) {
+	companyID := uuid.New()
+	s := &Request{sdb: &requestRepo{}, rbac: admin{user: sandpiper.AuthUser{CompanyID: companyID, Email: "admin@acme.com"}}}
+	req, err := s.Create(nil, sandpiper.SubscriptionRequest{CompanyID: new(uuid.UUID), ServerID: uuid.New()})
+	assert.NoError(t, err)
+	assert.Equal(t, companyID, *req.CompanyID) // always for your own company
+	assert.Equal(t, companyID, req.ServerID)
+	assert.Equal(t, "admin@acme.com", req.Email)
+}
@@ -0,0 +1,74 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package request
+
+// subscription request service
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Service represents subscription request application interface
+type Service interface {
+	Create(echo.Context, sandpiper.SubscriptionRequest) (*sandpiper.SubscriptionRequest, error)
+	List(echo.Context, string, *params.Params) ([]sandpiper.SubscriptionRequest, error)
+	View(echo.Context, uuid.UUID) (*sandpiper.SubscriptionRequest, error)
+	Approve(echo.Context, uuid.UUID, []uuid.UUID, string) (*sandpiper.RequestDecision, error)
+	Reject(echo.Context, uuid.UUID, string) (*sandpiper.SubscriptionRequest, error)
+}
+
+// New creates new subscription request application service
+func New(db *database.DB, sdb Repository, rbac RBAC, sec Securer) *Request {
+	return &Request{db: db.DB, sdb: sdb, rbac: rbac, sec: sec}
+}
+
+// Initialize initializes subscription request application service with defaults
+func Initialize(db *database.DB, rbac RBAC, sec Securer) *Request {
+	return New(db, pgsql.NewRequest(), rbac, sec)
+}
+
+// Request represents subscription request application service
+type Request struct {
+	db   *pg.DB
+	sdb  Repository
+	rbac RBAC
+	sec  Securer
+}
+
+// Securer represents security interface
+type Securer interface {
+	Hash(string) string
+	RandomPassword(int) (string, error)
+	APIKeySecret() string
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	Create(orm.DB, *sandpiper.SubscriptionRequest) (*sandpiper.SubscriptionRequest, error)
+	View(orm.DB, uuid.UUID) (*sandpiper.SubscriptionRequest, error)
+	List(orm.DB, string, *sandpiper.Scope, *params.Params) ([]sandpiper.SubscriptionRequest, error)
+	Company(orm.DB, *sandpiper.SubscriptionRequest) (*sandpiper.Company, error)
+	SyncUser(orm.DB, uuid.UUID) (*sandpiper.User, error)
+	UpdateSyncUser(orm.DB, *sandpiper.User) error
+	Subscribe(orm.DB, *sandpiper.Company, []uuid.UUID) ([]sandpiper.Subscription, error)
+	Decide(orm.DB, *sandpiper.SubscriptionRequest) error
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	CurrentUser(echo.Context) *sandpiper.AuthUser
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+	EnforceScope(echo.Context) (*sandpiper.Scope, error)
+	EnforceServerRole(string) error
+	OurServer() *sandpiper.Server
+}
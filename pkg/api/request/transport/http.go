@@ -0,0 +1,134 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// subscription request routing functions
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/request"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents subscription request http service
+type HTTP struct {
+	svc request.Service
+}
+
+// NewHTTP creates new subscription request http service
+func NewHTTP(svc request.Service, er *echo.Group) {
+	h := HTTP{svc}
+	rr := er.Group("/requests")
+	rr.POST("", h.create)
+	rr.GET("", h.list) // ?status=pending
+	rr.GET("/:id", h.view)
+	rr.PUT("/approve/:id", h.approve)
+	rr.PUT("/reject/:id", h.reject)
+}
+
+// Custom errors
+var (
+	ErrInvalidRequestUUID = echo.NewHTTPError(http.StatusBadRequest, "invalid subscription request uuid")
+	ErrInvalidStatus      = echo.NewHTTPError(http.StatusBadRequest, "status must be pending, approved or rejected")
+)
+
+// Subscription request create request (from an existing company)
+type createReq struct {
+	ContactName string      `json:"contact_name"`
+	Email       string      `json:"email"`
+	SliceIDs    []uuid.UUID `json:"slice_ids" validate:"required,min=1"`
+	Message     string      `json:"message"`
+}
+
+func (h *HTTP) create(c echo.Context) error {
+	r := new(createReq)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	result, err := h.svc.Create(c, sandpiper.SubscriptionRequest{
+		ID:          uuid.New(),
+		ContactName: r.ContactName,
+		Email:       r.Email,
+		SliceIDs:    r.SliceIDs,
+		Message:     r.Message,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *HTTP) list(c echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "", sandpiper.RequestPending, sandpiper.RequestApproved, sandpiper.RequestRejected:
+	default:
+		return ErrInvalidStatus
+	}
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.List(c, status, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.RequestsPaginated{Requests: result, Paging: p.Paging})
+}
+
+func (h *HTTP) view(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRequestUUID
+	}
+	result, err := h.svc.View(c, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Subscription request decision (slices are only used for approval, replacing the requested slices)
+type decisionReq struct {
+	SliceIDs []uuid.UUID `json:"slice_ids"`
+	Note     string      `json:"note"`
+}
+
+func (h *HTTP) approve(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRequestUUID
+	}
+	req := new(decisionReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	result, err := h.svc.Approve(c, id, req.SliceIDs, req.Note)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) reject(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRequestUUID
+	}
+	req := new(decisionReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	result, err := h.svc.Reject(c, id, req.Note)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,129 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package rule
+
+// subscription rule service logger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/rule"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the subscription rule service
+func ServiceLogger(svc rule.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents subscription rule logging service
+type LogService struct {
+	rule.Service
+	logger sandpiper.Logger
+}
+
+const source = "rule"
+
+// Create logging
+func (ls *LogService) Create(c echo.Context, req sandpiper.SubscriptionRule) (resp *sandpiper.SubscriptionRule, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Create subscription rule request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Create(c, req)
+}
+
+// List logging
+func (ls *LogService) List(c echo.Context, req *params.Params) (resp []sandpiper.SubscriptionRule, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List subscription rule request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.List(c, req)
+}
+
+// View logging
+func (ls *LogService) View(c echo.Context, req uuid.UUID) (resp *sandpiper.SubscriptionRule, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "View subscription rule request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.View(c, req)
+}
+
+// Update logging
+func (ls *LogService) Update(c echo.Context, req *rule.Update) (resp *sandpiper.SubscriptionRule, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Update subscription rule request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Update(c, req)
+}
+
+// Delete logging
+func (ls *LogService) Delete(c echo.Context, req uuid.UUID) (err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Delete subscription rule request", err,
+			map[string]interface{}{
+				"req":  req,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Delete(c, req)
+}
+
+// Preview logging
+func (ls *LogService) Preview(c echo.Context, req uuid.UUID) (resp *sandpiper.RuleEffect, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Preview subscription rule request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Preview(c, req)
+}
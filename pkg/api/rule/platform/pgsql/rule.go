@@ -0,0 +1,349 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// subscription rule service database access
+
+// Rules are reconciled rather than tracked. The subscriptions wanted by the active rules are
+// compared with the existing ones, so the same code handles new rules, (re)tagged slices and
+// disabled rules. Only subscriptions created by a rule are ever retired, and only those a rule
+// retired (rather than an administrator) are ever reactivated.
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	subsvc "github.com/sandpiper-framework/sandpiper/pkg/api/subscription/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// subResource identifies subscriptions in the audit trail (as in the subscription service)
+const subResource = "subscription"
+
+// Custom errors
+var (
+	ErrAlreadyExists   = echo.NewHTTPError(http.StatusInternalServerError, "Company already has a rule for this tag.")
+	ErrRuleNotFound    = echo.NewHTTPError(http.StatusNotFound, "Subscription rule not found.")
+	ErrCompanyNotFound = echo.NewHTTPError(http.StatusNotFound, "Company not found.")
+	ErrTagNotFound     = echo.NewHTTPError(http.StatusNotFound, "Tag not found.")
+)
+
+// Rule represents the client for subscription_rules table
+type Rule struct{}
+
+// NewRule returns a new subscription rule database instance
+func NewRule() *Rule {
+	return &Rule{}
+}
+
+// Create creates a new subscription rule (assumes allowed to do this)
+func (s *Rule) Create(db orm.DB, rule *sandpiper.SubscriptionRule) (*sandpiper.SubscriptionRule, error) {
+	if err := checkIDs(db, rule); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicate(db, rule); err != nil {
+		return nil, err
+	}
+	if err := db.Insert(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// View returns a single subscription rule by ID with its company and tag (assumes allowed to do this)
+func (s *Rule) View(db orm.DB, id uuid.UUID) (*sandpiper.SubscriptionRule, error) {
+	rule := &sandpiper.SubscriptionRule{ID: id}
+	err := db.Model(rule).Relation("Company").Relation("Tag").WherePK().Select()
+	if err != nil {
+		return nil, selectError(err)
+	}
+	return rule, nil
+}
+
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("subscription_rule", "id", "company_id", "tag_id", "active",
+		"created_at"),
+	Sort: params.Columns("subscription_rule", "description", "active", "created_at"),
+}
+
+// List returns list of all subscription rules
+func (s *Rule) List(db orm.DB, p *params.Params) (rules []sandpiper.SubscriptionRule, err error) {
+	q := db.Model(&rules).Relation("Company").Relation("Tag").
+		Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "subscription_rule.created_at"); err != nil {
+		return nil, err
+	}
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Update changes the tag, description and active flag of a rule (assumes allowed to do this)
+func (s *Rule) Update(db orm.DB, rule *sandpiper.SubscriptionRule) error {
+	if err := checkIDs(db, rule); err != nil {
+		return err
+	}
+	if err := checkDuplicate(db, rule); err != nil {
+		return err
+	}
+	_, err := db.Model(rule).Column("tag_id", "description", "active", "updated_at").WherePK().Update()
+	return err
+}
+
+// Delete removes the subscription rule by primary key (subscriptions it created are kept)
+func (s *Rule) Delete(db orm.DB, rule *sandpiper.SubscriptionRule) error {
+	return db.Delete(rule)
+}
+
+// Preview returns the subscription changes that enabling a rule would make (without making them)
+func (s *Rule) Preview(db orm.DB, rule *sandpiper.SubscriptionRule) (*sandpiper.RuleEffect, error) {
+	return plan(db, rule.CompanyID, uuid.Nil, rule.ID)
+}
+
+// Apply reconciles the rule subscriptions of a company with its active rules
+func (s *Rule) Apply(db orm.DB, companyID uuid.UUID) (*sandpiper.RuleEffect, error) {
+	return Apply(db, companyID, uuid.Nil)
+}
+
+// Audit records the subscription changes made by Apply
+func (s *Rule) Audit(db orm.DB, c echo.Context, effect *sandpiper.RuleEffect) error {
+	return Audit(db, c, effect)
+}
+
+// Apply creates, reactivates or retires rule subscriptions so they match the active rules,
+// limited to a company and/or a slice (if not uuid.Nil). Returns the changes made.
+func Apply(db orm.DB, companyID, sliceID uuid.UUID) (*sandpiper.RuleEffect, error) {
+	effect, err := plan(db, companyID, sliceID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ch := range effect.Create {
+		sub := &sandpiper.Subscription{
+			SubID:       uuid.New(),
+			SliceID:     ch.SliceID,
+			CompanyID:   ch.CompanyID,
+			Description: "Created by a subscription rule",
+			Active:      true,
+			RuleID:      ch.RuleID,
+		}
+		if sub.Name, err = subsvc.UniqueName(db, sub.SubID, ch.CompanyName+" "+ch.SliceName); err != nil {
+			return nil, err
+		}
+		if err := db.Insert(sub); err != nil {
+			return nil, err
+		}
+		effect.Create[i].SubID = &sub.SubID
+		effect.Create[i].After = sub
+	}
+	for i, ch := range effect.Reactivate {
+		sub := &sandpiper.Subscription{SubID: *ch.SubID, RuleID: ch.RuleID}
+		_, err := db.Model(sub).
+			Set("active = true").Set("rule_retired = false").Set("rule_id = ?rule_id").Set("updated_at = now()").
+			WherePK().Update()
+		if err != nil {
+			return nil, err
+		}
+		after := *ch.Before
+		after.Active, after.RuleRetired, after.RuleID = true, false, ch.RuleID
+		effect.Reactivate[i].After = &after
+	}
+	for i, ch := range effect.Retire {
+		sub := &sandpiper.Subscription{SubID: *ch.SubID}
+		_, err := db.Model(sub).
+			Set("active = false").Set("rule_retired = true").Set("updated_at = now()").
+			WherePK().Update()
+		if err != nil {
+			return nil, err
+		}
+		after := *ch.Before
+		after.Active, after.RuleRetired = false, true
+		effect.Retire[i].After = &after
+	}
+	return effect, nil
+}
+
+// Audit records each subscription change made by Apply (in the same transaction)
+func Audit(db orm.DB, c echo.Context, effect *sandpiper.RuleEffect) error {
+	for _, ch := range effect.Create {
+		if err := auditsvc.Record(db, c, sandpiper.AuditCreate, subResource, *ch.SubID, nil, ch.After); err != nil {
+			return err
+		}
+	}
+	for _, changes := range [][]sandpiper.RuleChange{effect.Reactivate, effect.Retire} {
+		for _, ch := range changes {
+			if err := auditsvc.Record(db, c, sandpiper.AuditUpdate, subResource, *ch.SubID, ch.Before, ch.After); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ruleMatch is a subscription wanted by an active rule
+type ruleMatch struct {
+	RuleID      uuid.UUID
+	CompanyID   uuid.UUID
+	CompanyName string
+	SliceID     uuid.UUID
+	SliceName   string
+}
+
+// plan compares the subscriptions wanted by the active rules (treating "enableID" as active)
+// with the existing subscriptions, limited to a company and/or a slice (if not uuid.Nil)
+func plan(db orm.DB, companyID, sliceID, enableID uuid.UUID) (*sandpiper.RuleEffect, error) {
+	var matches []ruleMatch
+
+	// the oldest rule "owns" a subscription wanted by more than one rule
+	q := db.Model().TableExpr("subscription_rules AS r").
+		DistinctOn("r.company_id, st.slice_id").
+		ColumnExpr("r.id AS rule_id, r.company_id, c.name AS company_name, st.slice_id, s.name AS slice_name").
+		Join("JOIN slice_tags AS st ON st.tag_id = r.tag_id").
+		Join("JOIN slices AS s ON s.id = st.slice_id").
+		Join("JOIN companies AS c ON c.id = r.company_id").
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.Where("r.active").WhereOr("r.id = ?", enableID), nil
+		}).
+		Order("r.company_id", "st.slice_id", "r.created_at")
+	if companyID != uuid.Nil {
+		q = q.Where("r.company_id = ?", companyID)
+	}
+	if sliceID != uuid.Nil {
+		q = q.Where("st.slice_id = ?", sliceID)
+	}
+	if err := q.Select(&matches); err != nil {
+		return nil, err
+	}
+
+	var subs []sandpiper.Subscription
+	q = db.Model(&subs).Relation("Company").Relation("Slice")
+	if companyID != uuid.Nil {
+		q = q.Where("subscription.company_id = ?", companyID)
+	}
+	if sliceID != uuid.Nil {
+		q = q.Where("subscription.slice_id = ?", sliceID)
+	}
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+
+	type pair struct{ company, slice uuid.UUID }
+	existing := make(map[pair]*sandpiper.Subscription, len(subs))
+	for i := range subs {
+		existing[pair{subs[i].CompanyID, subs[i].SliceID}] = &subs[i]
+	}
+
+	effect := &sandpiper.RuleEffect{
+		Create:     []sandpiper.RuleChange{},
+		Reactivate: []sandpiper.RuleChange{},
+		Retire:     []sandpiper.RuleChange{},
+	}
+	now := time.Now()
+	wanted := make(map[pair]bool, len(matches))
+	for _, m := range matches {
+		key := pair{m.CompanyID, m.SliceID}
+		wanted[key] = true
+		ch := sandpiper.RuleChange{
+			RuleID:      uuidPtr(m.RuleID),
+			CompanyID:   m.CompanyID,
+			CompanyName: m.CompanyName,
+			SliceID:     m.SliceID,
+			SliceName:   m.SliceName,
+		}
+		sub, ok := existing[key]
+		switch {
+		case !ok:
+			effect.Create = append(effect.Create, ch)
+		case !sub.Active && sub.RuleRetired && !sub.Expired(now): // expired subscriptions need renewing
+			ch.SubID = uuidPtr(sub.SubID)
+			ch.Before = snapshot(sub)
+			effect.Reactivate = append(effect.Reactivate, ch)
+		}
+	}
+	for _, sub := range subs {
+		if sub.RuleID != nil && sub.Active && !wanted[pair{sub.CompanyID, sub.SliceID}] {
+			ch := sandpiper.RuleChange{
+				SubID:     uuidPtr(sub.SubID),
+				RuleID:    sub.RuleID,
+				CompanyID: sub.CompanyID,
+				SliceID:   sub.SliceID,
+				Before:    snapshot(&sub),
+			}
+			if sub.Company != nil {
+				ch.CompanyName = sub.Company.Name
+			}
+			if sub.Slice != nil {
+				ch.SliceName = sub.Slice.Name
+			}
+			effect.Retire = append(effect.Retire, ch)
+		}
+	}
+	return effect, nil
+}
+
+// checkIDs makes sure the company and tag of a rule exist
+func checkIDs(db orm.DB, rule *sandpiper.SubscriptionRule) error {
+	found, err := db.Model((*sandpiper.Company)(nil)).Where("id = ?", rule.CompanyID).Exists()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrCompanyNotFound
+	}
+	found, err = db.Model((*sandpiper.Tag)(nil)).Where("id = ?", rule.TagID).Exists()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// checkDuplicate returns an error if the company already has a (different) rule for the tag
+func checkDuplicate(db orm.DB, rule *sandpiper.SubscriptionRule) error {
+	found, err := db.Model((*sandpiper.SubscriptionRule)(nil)).
+		Where("company_id = ?", rule.CompanyID).
+		Where("tag_id = ?", rule.TagID).
+		Where("id <> ?", rule.ID).Exists()
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// snapshot copies a subscription for the audit trail (without its company and slice)
+func snapshot(sub *sandpiper.Subscription) *sandpiper.Subscription {
+	cp := *sub
+	cp.Company, cp.Slice = nil, nil
+	return &cp
+}
+
+func uuidPtr(id uuid.UUID) *uuid.UUID {
+	return &id
+}
+
+func selectError(err error) error {
+	if err == pg.ErrNoRows {
+		return ErrRuleNotFound
+	}
+	return err
+}
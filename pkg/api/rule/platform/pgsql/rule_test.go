@@ -0,0 +1,119 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+func TestApply(t *testing.T) {
+	var (
+		ruleID    = uuid.MustParse("10000000-0000-0000-0000-000000000000")
+		companyID = uuid.MustParse("20000000-0000-0000-0000-000000000000")
+		sliceID   = uuid.MustParse("30000000-0000-0000-0000-000000000000")
+		subID     = uuid.MustParse("40000000-0000-0000-0000-000000000000")
+		past      = time.Now().Add(-time.Hour)
+	)
+	match := mockdb.Row{
+		"rule_id": ruleID, "company_id": companyID, "company_name": "Acme",
+		"slice_id": sliceID, "slice_name": "Brakes",
+	}
+	sub := func(active bool, ruleID interface{}, retired bool, end interface{}) mockdb.Row {
+		return mockdb.Row{
+			"sub_id": subID, "company_id": companyID, "slice_id": sliceID, "active": active,
+			"rule_id": ruleID, "rule_retired": retired, "end_date": end,
+			"company__id": companyID, "company__name": "Acme", "slice__id": sliceID, "slice__name": "Brakes",
+		}
+	}
+
+	cases := []struct {
+		name    string
+		matches []mockdb.Row
+		subs    []mockdb.Row
+		want    string // "create", "reactivate", "retire" or "" (no change)
+	}{
+		{
+			name:    "Create a wanted subscription",
+			matches: []mockdb.Row{match},
+			want:    "create",
+		},
+		{
+			name:    "Reactivate a subscription retired by a rule",
+			matches: []mockdb.Row{match},
+			subs:    []mockdb.Row{sub(false, nil, true, nil)}, // its rule was deleted
+			want:    "reactivate",
+		},
+		{
+			name:    "Rule subscription deactivated by hand",
+			matches: []mockdb.Row{match},
+			subs:    []mockdb.Row{sub(false, ruleID, false, nil)},
+		},
+		{
+			name:    "Expired subscription retired by a rule",
+			matches: []mockdb.Row{match},
+			subs:    []mockdb.Row{sub(false, ruleID, true, past)},
+		},
+		{
+			name: "Retire an unwanted rule subscription",
+			subs: []mockdb.Row{sub(true, ruleID, false, nil)},
+			want: "retire",
+		},
+		{
+			name: "Subscription created by hand",
+			subs: []mockdb.Row{sub(true, nil, false, nil)},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := (&mockdb.DB{}).
+				On(`FROM subscription_rules AS r`, tt.matches...).
+				On(`FROM "subscriptions" AS "subscription"`, tt.subs...)
+
+			effect, err := Apply(db, companyID, uuid.Nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got := map[string]int{
+				"create":     len(effect.Create),
+				"reactivate": len(effect.Reactivate),
+				"retire":     len(effect.Retire),
+			}
+			want := map[string]int{"create": 0, "reactivate": 0, "retire": 0}
+			if tt.want != "" {
+				want[tt.want] = 1
+			}
+			assert.Equal(t, want, got)
+
+			// each change is saved and audited (as a subscription)
+			c := echo.New().NewContext(httptest.NewRequest("POST", "/", nil), httptest.NewRecorder())
+			assert.NoError(t, Audit(db, c, effect))
+			assert.Equal(t, want[tt.want], len(append(db.Find("INSERT INTO \"subscriptions\""), db.Find("UPDATE")...)))
+			assert.Equal(t, want[tt.want], len(db.Find("INSERT INTO audit_log")))
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	rule := &sandpiper.SubscriptionRule{ID: uuid.New(), CompanyID: uuid.New()}
+	db := (&mockdb.DB{}).On(`FROM subscription_rules AS r`, mockdb.Row{
+		"rule_id": rule.ID, "company_id": rule.CompanyID, "company_name": "Acme",
+		"slice_id": uuid.New(), "slice_name": "Brakes",
+	})
+
+	// the (disabled) rule is treated as active, and nothing is changed
+	effect, err := NewRule().Preview(db, rule)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(effect.Create))
+	assert.Empty(t, append(db.Find("INSERT"), db.Find("UPDATE")...))
+}
@@ -0,0 +1,151 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package rule contains services for subscription rules (subscribing a company to every slice
+// with a tag).
+package rule
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// resource identifies subscription rules in the audit trail (the subscriptions they change are
+// recorded as subscriptions)
+const resource = "subscription_rule"
+
+// Create adds a new subscription rule (if administrator on a primary server). If created
+// active, its subscriptions are created immediately.
+func (s *Rule) Create(c echo.Context, req sandpiper.SubscriptionRule) (result *sandpiper.SubscriptionRule, err error) {
+	if err := s.enforceAdmin(c); err != nil {
+		return nil, err
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if result, err = s.sdb.Create(tx, &req); err != nil {
+			return err
+		}
+		if err := auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, result.ID, nil, result); err != nil {
+			return err
+		}
+		return s.apply(tx, c, req.CompanyID)
+	})
+	return result, err
+}
+
+// List returns list of subscription rules (if administrator)
+func (s *Rule) List(c echo.Context, p *params.Params) ([]sandpiper.SubscriptionRule, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.sdb.List(s.db, p)
+}
+
+// View returns a single subscription rule (if administrator)
+func (s *Rule) View(c echo.Context, id uuid.UUID) (*sandpiper.SubscriptionRule, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	return s.sdb.View(s.db, id)
+}
+
+// Update contains subscription rule fields used for updating
+type Update struct {
+	ID          uuid.UUID
+	TagID       int
+	Description string
+	Active      bool
+}
+
+// Update changes a subscription rule and reconciles the company's rule subscriptions
+func (s *Rule) Update(c echo.Context, r *Update) (*sandpiper.SubscriptionRule, error) {
+	if err := s.enforceAdmin(c); err != nil {
+		return nil, err
+	}
+	var after *sandpiper.SubscriptionRule
+	err := s.db.RunInTransaction(func(tx *pg.Tx) error {
+		before, err := s.sdb.View(tx, r.ID)
+		if err != nil {
+			return err
+		}
+		rule := *before
+		rule.TagID = r.TagID
+		rule.Description = r.Description
+		rule.Active = r.Active
+		if err := s.sdb.Update(tx, &rule); err != nil {
+			return err
+		}
+		if after, err = s.sdb.View(tx, r.ID); err != nil {
+			return err
+		}
+		if err := auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, r.ID, before, after); err != nil {
+			return err
+		}
+		return s.apply(tx, c, rule.CompanyID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Delete removes a subscription rule, first retiring the subscriptions only it wanted
+func (s *Rule) Delete(c echo.Context, id uuid.UUID) error {
+	if err := s.enforceAdmin(c); err != nil {
+		return err
+	}
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		before, err := s.sdb.View(tx, id)
+		if err != nil {
+			return err
+		}
+		rule := *before
+		rule.Active = false
+		if err := s.sdb.Update(tx, &rule); err != nil {
+			return err
+		}
+		if err := s.apply(tx, c, rule.CompanyID); err != nil {
+			return err
+		}
+		if err := s.sdb.Delete(tx, &rule); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, id, before, nil)
+	})
+}
+
+// Preview returns the subscriptions that enabling a rule would create or reactivate (if administrator)
+func (s *Rule) Preview(c echo.Context, id uuid.UUID) (*sandpiper.RuleEffect, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	rule, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sdb.Preview(s.db, rule)
+}
+
+// apply reconciles a company's rule subscriptions, recording each change in the audit trail
+func (s *Rule) apply(tx orm.DB, c echo.Context, companyID uuid.UUID) error {
+	effect, err := s.sdb.Apply(tx, companyID)
+	if err != nil {
+		return err
+	}
+	return s.sdb.Audit(tx, c, effect)
+}
+
+// enforceAdmin makes sure rules are only changed by an administrator on a primary server
+// (secondary servers receive their subscriptions through a sync)
+func (s *Rule) enforceAdmin(c echo.Context) error {
+	if err := s.rbac.EnforceServerRole(sandpiper.PrimaryServer); err != nil {
+		return err
+	}
+	return s.rbac.EnforceRole(c, sandpiper.AdminRole)
+}
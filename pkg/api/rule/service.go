@@ -0,0 +1,64 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package rule
+
+// subscription rule service
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/rule/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Service represents subscription rule application interface
+type Service interface {
+	Create(echo.Context, sandpiper.SubscriptionRule) (*sandpiper.SubscriptionRule, error)
+	List(echo.Context, *params.Params) ([]sandpiper.SubscriptionRule, error)
+	View(echo.Context, uuid.UUID) (*sandpiper.SubscriptionRule, error)
+	Update(echo.Context, *Update) (*sandpiper.SubscriptionRule, error)
+	Delete(echo.Context, uuid.UUID) error
+	Preview(echo.Context, uuid.UUID) (*sandpiper.RuleEffect, error)
+}
+
+// New creates new subscription rule application service
+func New(db *database.DB, sdb Repository, rbac RBAC) *Rule {
+	return &Rule{db: db.DB, sdb: sdb, rbac: rbac}
+}
+
+// Initialize initializes subscription rule application service with defaults
+func Initialize(db *database.DB, rbac RBAC) *Rule {
+	return New(db, pgsql.NewRule(), rbac)
+}
+
+// Rule represents subscription rule application service
+type Rule struct {
+	db   *pg.DB
+	sdb  Repository
+	rbac RBAC
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	Create(orm.DB, *sandpiper.SubscriptionRule) (*sandpiper.SubscriptionRule, error)
+	View(orm.DB, uuid.UUID) (*sandpiper.SubscriptionRule, error)
+	List(orm.DB, *params.Params) ([]sandpiper.SubscriptionRule, error)
+	Update(orm.DB, *sandpiper.SubscriptionRule) error
+	Delete(orm.DB, *sandpiper.SubscriptionRule) error
+	Preview(orm.DB, *sandpiper.SubscriptionRule) (*sandpiper.RuleEffect, error)
+	Apply(orm.DB, uuid.UUID) (*sandpiper.RuleEffect, error)
+	Audit(orm.DB, echo.Context, *sandpiper.RuleEffect) error
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+	EnforceServerRole(string) error
+}
@@ -0,0 +1,150 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// subscription rule routing functions
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/rule"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents subscription rule http service
+type HTTP struct {
+	svc rule.Service
+}
+
+// NewHTTP creates new subscription rule http service
+func NewHTTP(svc rule.Service, er *echo.Group) {
+	h := HTTP{svc}
+	rr := er.Group("/rules")
+	rr.POST("", h.create)
+	rr.GET("", h.list)
+	rr.GET("/preview/:id", h.preview) // subscriptions that enabling the rule would change
+	rr.GET("/:id", h.view)
+	rr.PUT("/:id", h.update) // not a PATCH, body must include *all* fields
+	rr.DELETE("/:id", h.delete)
+}
+
+// Custom errors
+var (
+	ErrInvalidRuleUUID = echo.NewHTTPError(http.StatusBadRequest, "invalid subscription rule uuid")
+)
+
+// Subscription rule create request
+type createReq struct {
+	ID          uuid.UUID `json:"id"` // optional
+	CompanyID   uuid.UUID `json:"company_id" validate:"required"`
+	TagID       int       `json:"tag_id" validate:"required"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"` // preview before enabling
+}
+
+func (r createReq) id() uuid.UUID {
+	if r.ID == uuid.Nil {
+		return uuid.New()
+	}
+	return r.ID
+}
+
+// create populates createReq from supplied json body
+func (h *HTTP) create(c echo.Context) error {
+	r := new(createReq)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	result, err := h.svc.Create(c, sandpiper.SubscriptionRule{
+		ID:          r.id(),
+		CompanyID:   r.CompanyID,
+		TagID:       r.TagID,
+		Description: r.Description,
+		Active:      r.Active,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *HTTP) list(c echo.Context) error {
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.List(c, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.RulesPaginated{Rules: result, Paging: p.Paging})
+}
+
+func (h *HTTP) view(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRuleUUID
+	}
+	result, err := h.svc.View(c, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Subscription rule update request
+type updateReq struct {
+	TagID       int    `json:"tag_id" validate:"required"`
+	Description string `json:"description"`
+	Active      bool   `json:"active"`
+}
+
+func (h *HTTP) update(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRuleUUID
+	}
+	req := new(updateReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	result, err := h.svc.Update(c, &rule.Update{
+		ID:          id,
+		TagID:       req.TagID,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) delete(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRuleUUID
+	}
+	if err := h.svc.Delete(c, id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *HTTP) preview(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidRuleUUID
+	}
+	result, err := h.svc.Preview(c, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,69 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package search
+
+// search service logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/search"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the search service
+func ServiceLogger(svc search.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents search logging service
+type LogService struct {
+	search.Service
+	logger sandpiper.Logger
+}
+
+const source = "search"
+
+// Search logging
+func (ls *LogService) Search(c echo.Context, text string, sliceID uuid.UUID, req *params.Params) (resp []sandpiper.SearchResult, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Search request", err,
+			map[string]interface{}{
+				"q":        text,
+				"slice_id": sliceID,
+				"req":      req,
+				"resp":     fmt.Sprintf("Count: %d", len(resp)),
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Search(c, text, sliceID, req)
+}
+
+// Reindex logging
+func (ls *LogService) Reindex(c echo.Context, sliceID uuid.UUID) (resp *sandpiper.SearchReindex, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Search reindex request", err,
+			map[string]interface{}{
+				"slice_id": sliceID,
+				"resp":     resp,
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Reindex(c, sliceID)
+}
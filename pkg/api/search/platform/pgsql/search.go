@@ -0,0 +1,119 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// search service database access
+
+// Grains are indexed (using the "simple" text search configuration to keep part numbers intact)
+// in the "grain_search" table when they are added. Index rows are removed by a foreign key
+// constraint when a grain is deleted. Grain keys are weighted highest, then source file names,
+// then payload text.
+
+import (
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Search represents the client for the grain_search table
+type Search struct{}
+
+// NewSearch returns a new search database instance
+func NewSearch() *Search {
+	return &Search{}
+}
+
+// Search returns grains matching the query text, optionally limited to a slice and by scope
+func (s *Search) Search(db orm.DB, text string, sliceID uuid.UUID, sc *sandpiper.Scope, p *params.Params) ([]sandpiper.SearchResult, error) {
+	var results []sandpiper.SearchResult
+
+	q := db.Model().TableExpr("grain_search AS gs").
+		ColumnExpr("gs.grain_id, gs.slice_id, sl.name AS slice_name, g.grain_key, g.source").
+		ColumnExpr("ts_rank(gs.document, tsq) AS rank").
+		Join("CROSS JOIN plainto_tsquery('simple', ?) AS tsq", text).
+		Join("INNER JOIN grains AS g ON g.id = gs.grain_id").
+		Join("INNER JOIN slices AS sl ON sl.id = gs.slice_id").
+		Where("gs.document @@ tsq")
+
+	// composite slices hold no grains, so include (and allow access through) their members
+	// (the slice service can't be used here because it indexes grains with this package)
+	if sliceID != uuid.Nil {
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			return q.Where("gs.slice_id = ?", sliceID).
+				WhereOr("gs.slice_id IN (SELECT member_id FROM slice_members WHERE composite_id = ?)", sliceID), nil
+		})
+	}
+	if sc != nil {
+		// only "active" subscriptions within their terms for the scope (i.e. the company)
+		q = q.Where(`gs.slice_id IN (
+			SELECT slice_id FROM subscriptions AS sub WHERE sub.company_id = ?0 AND `+sandpiper.SubInTerm+`
+			UNION SELECT sm.member_id FROM subscriptions AS sub
+			JOIN slice_members AS sm ON sm.composite_id = sub.slice_id
+			WHERE sub.company_id = ?0 AND `+sandpiper.SubInTerm+`)`, sc.ID)
+	}
+
+	q = q.OrderExpr("rank DESC, g.grain_key").
+		Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+
+	var err error
+	p.Paging.Count, err = q.SelectAndCount(&results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// reindexBatch is the number of grains (with payloads) read at a time by Reindex
+const reindexBatch = 100
+
+// Reindex replaces the search documents of all grains (optionally for one slice) using the text
+// extracted from their decoded payloads (so compressed payloads are searchable after an upgrade).
+// Grains are read in batches ordered by id so large slices are never completely in memory.
+func (s *Search) Reindex(db orm.DB, sliceID uuid.UUID) (int, error) {
+	var count int
+	var last uuid.UUID
+	for {
+		var grains []sandpiper.Grain
+		q := db.Model(&grains).
+			Column("id", "slice_id", "grain_key", "source", "encoding", "payload", "key_id").
+			Where("id > ?", last).
+			Order("id").Limit(reindexBatch)
+		if sliceID != uuid.Nil {
+			q = q.Where("slice_id = ?", sliceID)
+		}
+		if err := q.Select(); err != nil {
+			return count, err
+		}
+		if len(grains) == 0 {
+			return count, nil
+		}
+		for i := range grains {
+			if err := Index(db, &grains[i]); err != nil {
+				return count, err
+			}
+		}
+		count += len(grains)
+		last = grains[len(grains)-1].ID
+	}
+}
+
+// Index adds (or replaces) the search document for grains (called by services that add grains)
+func Index(db orm.DB, grains ...*sandpiper.Grain) error {
+	for _, g := range grains {
+		_, err := db.Exec(`
+			INSERT INTO grain_search (grain_id, slice_id, document)
+			VALUES (?, ?, setweight(to_tsvector('simple', ?), 'A') ||
+			              setweight(to_tsvector('simple', ?), 'B') ||
+			              to_tsvector('simple', ?))
+			ON CONFLICT (grain_id) DO UPDATE SET document = EXCLUDED.document`,
+			g.ID, g.SliceID, g.Key, g.Source, Text(g))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
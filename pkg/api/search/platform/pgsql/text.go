@@ -0,0 +1,84 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// extract searchable text from a grain payload
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// maxTextBytes keeps the search document well under the postgresql tsvector limit (1MB)
+const maxTextBytes = 512 * 1024
+
+// Text returns the distinct words in a grain's decoded payload (empty for binary payloads).
+// Only element text and attribute values are used from xml payloads (not the markup). Words
+// are only needed once in the document because we don't search for phrases. Payloads encrypted
+// at rest are never indexed (which would defeat the purpose).
+func Text(g *sandpiper.Grain) string {
+	if g.KeyID != 0 {
+		return ""
+	}
+	data, err := g.Payload.Decode(g.Encoding)
+	if err != nil || !utf8.ValidString(data) || strings.IndexByte(data, 0) != -1 {
+		return ""
+	}
+
+	var b strings.Builder
+	seen := make(map[string]bool)
+	add := func(s string) bool {
+		for _, w := range strings.Fields(s) {
+			lw := strings.ToLower(w)
+			if seen[lw] {
+				continue
+			}
+			if b.Len()+len(w)+1 > maxTextBytes {
+				return false
+			}
+			seen[lw] = true
+			b.WriteString(w)
+			b.WriteByte(' ')
+		}
+		return true
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(data), "<") || !addXML(data, add) {
+		b.Reset()
+		seen = make(map[string]bool)
+		add(data)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// addXML adds element text and attribute values (returning false if not well-formed xml)
+func addXML(data string, add func(string) bool) bool {
+	d := xml.NewDecoder(strings.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			for _, a := range t.Attr {
+				if !add(a.Value) {
+					return true // full
+				}
+			}
+		case xml.CharData:
+			if !add(string(t)) {
+				return true // full
+			}
+		}
+	}
+}
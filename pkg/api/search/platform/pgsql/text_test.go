@@ -0,0 +1,67 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/search/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+)
+
+func TestText(t *testing.T) {
+	encode := func(s, enc string) payload.PayloadData {
+		p, err := payload.Encode(bytes.NewReader([]byte(s)), enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	cases := []struct {
+		name  string
+		grain sandpiper.Grain
+		want  string
+	}{
+		{
+			name:  "Compressed xml",
+			grain: sandpiper.Grain{Encoding: "z64", Payload: encode(`<App id="7"><Part>BB-100</Part><Note>bb-100 front</Note></App>`, "z64")},
+			want:  "7 BB-100 front",
+		},
+		{
+			name:  "Plain text",
+			grain: sandpiper.Grain{Encoding: "raw", Payload: encode("part BB-100\npart BB-200", "raw")},
+			want:  "part BB-100 BB-200",
+		},
+		{
+			name:  "Malformed xml is plain text",
+			grain: sandpiper.Grain{Encoding: "raw", Payload: encode("<Part>BB-100", "raw")},
+			want:  "<Part>BB-100",
+		},
+		{
+			name:  "Binary",
+			grain: sandpiper.Grain{Encoding: "b64", Payload: encode("\x89PNG\r\n\x1a\n\x00\x00", "b64")},
+			want:  "",
+		},
+		{
+			name:  "Encrypted at rest",
+			grain: sandpiper.Grain{Encoding: "raw", Payload: encode("part BB-100", "raw"), KeyID: 1},
+			want:  "",
+		},
+		{
+			name:  "Bad encoding",
+			grain: sandpiper.Grain{Encoding: "b64", Payload: "!!!"},
+			want:  "",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pgsql.Text(&tt.grain); got != tt.want {
+				t.Errorf("got \"%s\" want \"%s\"", got, tt.want)
+			}
+		})
+	}
+}
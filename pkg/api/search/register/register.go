@@ -0,0 +1,24 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package search
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/search"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	sl "github.com/sandpiper-framework/sandpiper/pkg/api/search/logging"
+	st "github.com/sandpiper-framework/sandpiper/pkg/api/search/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the search service to its logger and transport mechanisms
+func Register(db *database.DB, log sandpiper.Logger, v1 *echo.Group) {
+	svc := search.Initialize(db, rbac.New(db.Settings.ServerRole))
+	ls := sl.ServiceLogger(svc, log)
+	st.NewHTTP(ls, v1)
+}
@@ -0,0 +1,42 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package search contains services for full-text search of grain keys, sources and payloads.
+package search
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ErrMissingQuery indicates no search text was provided
+var ErrMissingQuery = echo.NewHTTPError(http.StatusBadRequest, "Search text is required (e.g. ?q=bb-100)")
+
+// Search returns grains matching all words in the text (optionally for one slice) scoped by user
+func (s *Search) Search(c echo.Context, text string, sliceID uuid.UUID, p *params.Params) ([]sandpiper.SearchResult, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrMissingQuery
+	}
+	sc, err := s.rbac.EnforceScope(c)
+	if err != nil {
+		return nil, err
+	}
+	return s.sdb.Search(s.db, text, sliceID, sc, p)
+}
+
+// Reindex rebuilds the search documents of all grains (or the grains in a slice) if administrator
+func (s *Search) Reindex(c echo.Context, sliceID uuid.UUID) (*sandpiper.SearchReindex, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	n, err := s.sdb.Reindex(s.db, sliceID)
+	return &sandpiper.SearchReindex{SliceID: sliceID, Grains: n}, err
+}
@@ -0,0 +1,74 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package search_test
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/search"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+type repo struct {
+	search.Repository
+	reindexed uuid.UUID
+}
+
+func (r *repo) Search(orm.DB, string, uuid.UUID, *sandpiper.Scope, *params.Params) ([]sandpiper.SearchResult, error) {
+	return []sandpiper.SearchResult{{GrainKey: "app:1"}}, nil
+}
+
+func (r *repo) Reindex(db orm.DB, sliceID uuid.UUID) (int, error) {
+	r.reindexed = sliceID
+	return 7, nil
+}
+
+type role struct {
+	search.RBAC
+	admin bool
+}
+
+func (r role) EnforceRole(echo.Context, sandpiper.AccessLevel) error {
+	if !r.admin {
+		return echo.ErrForbidden
+	}
+	return nil
+}
+
+func (r role) EnforceScope(echo.Context) (*sandpiper.Scope, error) {
+	return nil, nil
+}
+
+func TestSearch(t *testing.T) {
+	svc := search.New(&database.DB{}, &repo{}, role{})
+
+	_, err := svc.Search(nil, "  ", uuid.Nil, &params.Params{})
+	assert.Equal(t, search.ErrMissingQuery, err)
+
+	results, err := svc.Search(nil, "bb-100", uuid.Nil, &params.Params{})
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestReindex(t *testing.T) {
+	sliceID := uuid.MustParse("10000000-0000-0000-0000-000000000000")
+
+	r := &repo{}
+	_, err := search.New(&database.DB{}, r, role{}).Reindex(nil, sliceID)
+	assert.Equal(t, echo.ErrForbidden, err)
+	assert.Equal(t, uuid.Nil, r.reindexed)
+
+	result, err := search.New(&database.DB{}, r, role{admin: true}).Reindex(nil, sliceID)
+	assert.Nil(t, err)
+	assert.Equal(t, &sandpiper.SearchReindex{SliceID: sliceID, Grains: 7}, result)
+	assert.Equal(t, sliceID, r.reindexed)
+}
@@ -0,0 +1,54 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package search
+
+// search service
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/search/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Service represents search application interface
+type Service interface {
+	Search(echo.Context, string, uuid.UUID, *params.Params) ([]sandpiper.SearchResult, error)
+	Reindex(echo.Context, uuid.UUID) (*sandpiper.SearchReindex, error)
+}
+
+// New creates new search application service
+func New(db *database.DB, sdb Repository, rbac RBAC) *Search {
+	return &Search{db: db.DB, sdb: sdb, rbac: rbac}
+}
+
+// Initialize initializes search application service with defaults
+func Initialize(db *database.DB, rbac RBAC) *Search {
+	return New(db, pgsql.NewSearch(), rbac)
+}
+
+// Search represents search application service
+type Search struct {
+	db   *pg.DB
+	sdb  Repository
+	rbac RBAC
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	Search(orm.DB, string, uuid.UUID, *sandpiper.Scope, *params.Params) ([]sandpiper.SearchResult, error)
+	Reindex(orm.DB, uuid.UUID) (int, error)
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+	EnforceScope(echo.Context) (*sandpiper.Scope, error)
+}
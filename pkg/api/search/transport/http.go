@@ -0,0 +1,78 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// routing of search requests
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/search"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents search http service
+type HTTP struct {
+	svc search.Service
+}
+
+// NewHTTP creates new search http service
+func NewHTTP(svc search.Service, er *echo.Group) {
+	h := HTTP{svc}
+	er.GET("/search", h.search)           // ?q=<text>&slice=<slice_id>&page=1&pagesize=50
+	er.POST("/search/reindex", h.reindex) // ?slice=<slice_id>
+}
+
+// ErrInvalidSliceUUID indicates a malformed slice id
+var ErrInvalidSliceUUID = echo.NewHTTPError(http.StatusBadRequest, "Invalid slice uuid")
+
+func (h *HTTP) search(c echo.Context) error {
+	sliceID, err := sliceParam(c)
+	if err != nil {
+		return err
+	}
+
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+
+	q := c.QueryParam("q")
+	result, err := h.svc.Search(c, q, sliceID, p)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, sandpiper.SearchPaginated{Query: q, Results: result, Paging: p.Paging})
+}
+
+func (h *HTTP) reindex(c echo.Context) error {
+	sliceID, err := sliceParam(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.Reindex(c, sliceID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// sliceParam returns the optional "slice" query parameter
+func sliceParam(c echo.Context) (uuid.UUID, error) {
+	slice := c.QueryParam("slice")
+	if slice == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(slice)
+	if err != nil {
+		return uuid.Nil, ErrInvalidSliceUUID
+	}
+	return id, nil
+}
@@ -7,18 +7,33 @@
 package setting
 
 import (
+	"github.com/go-pg/pg/v9"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 )
 
+// resource identifies the settings record in the audit trail
+const resource = "setting"
+
 // Create makes a new setting to hold our syncable data-objects. Must be a sandpiper admin.
 func (s *Setting) Create(c echo.Context, req *sandpiper.Setting) (*sandpiper.Setting, error) {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
-	return s.sdb.Create(s.db, req)
+	var set *sandpiper.Setting
+	err := s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if set, err = s.sdb.Create(tx, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, set.ID, nil, set)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
 }
 
 // View returns the database settings record
@@ -41,14 +56,27 @@ func (s *Setting) Update(c echo.Context, r *Update) (*sandpiper.Setting, error)
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
+	before, err := s.sdb.View(s.db)
+	if err != nil {
+		return nil, err
+	}
 	set := sandpiper.Setting{
 		ID:         true,
 		ServerRole: r.ServerRole,
 		ServerID:   r.ServerID,
 	}
-	err := s.sdb.Update(s.db, &set)
+	var after *sandpiper.Setting
+	err = s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if err := s.sdb.Update(tx, &set); err != nil {
+			return err
+		}
+		if after, err = s.sdb.View(tx); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, true, before, after)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return s.sdb.View(s.db)
+	return after, nil
 }
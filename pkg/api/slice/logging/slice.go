@@ -173,3 +173,84 @@ func (ls *LogService) Unlock(c echo.Context, req uuid.UUID) (err error) {
 	}(time.Now())
 	return ls.Service.Unlock(c, req)
 }
+
+// SetMetadata logging
+func (ls *LogService) SetMetadata(c echo.Context, req uuid.UUID, meta sandpiper.MetaMap, replace bool) (resp sandpiper.MetaArray, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Set slice metadata request", err,
+			map[string]interface{}{
+				"slice_id": req,
+				"meta":     meta,
+				"replace":  replace,
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.SetMetadata(c, req, meta, replace)
+}
+
+// DeleteMetadata logging
+func (ls *LogService) DeleteMetadata(c echo.Context, req uuid.UUID, key string) (err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Delete slice metadata request", err,
+			map[string]interface{}{
+				"slice_id": req,
+				"key":      key,
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.DeleteMetadata(c, req, key)
+}
+
+// Rekey logging
+func (ls *LogService) Rekey(c echo.Context, req uuid.UUID, encrypt bool) (resp *sandpiper.SliceKeyRotation, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Rekey slice request", err,
+			map[string]interface{}{
+				"slice_id": req,
+				"encrypt":  encrypt,
+				"resp":     resp,
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Rekey(c, req, encrypt)
+}
+
+// ReplaceMembers logging
+func (ls *LogService) ReplaceMembers(c echo.Context, req uuid.UUID, members []uuid.UUID) (resp *sandpiper.Slice, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Replace slice members request", err,
+			map[string]interface{}{
+				"slice_id": req,
+				"members":  members,
+				"took":     time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.ReplaceMembers(c, req, members)
+}
+
+// Rewrap logging
+func (ls *LogService) Rewrap(c echo.Context) (resp int, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Rewrap slice keys request", err,
+			map[string]interface{}{
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Rewrap(c)
+}
@@ -0,0 +1,100 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package slice
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// metaRepo returns a slice (recording any metadata changes, which should not happen here)
+type metaRepo struct {
+	Repository
+	slice   *sandpiper.Slice
+	changed bool
+}
+
+func (r *metaRepo) View(orm.DB, uuid.UUID) (*sandpiper.Slice, error) {
+	return r.slice, nil
+}
+
+func (r *metaRepo) SetMetadata(orm.DB, uuid.UUID, sandpiper.MetaMap, bool) error {
+	r.changed = true
+	return nil
+}
+
+func (r *metaRepo) DeleteMetadata(orm.DB, uuid.UUID, string) error {
+	r.changed = true
+	return nil
+}
+
+// admin allows every role
+type admin struct {
+	RBAC
+}
+
+func (admin) EnforceRole(echo.Context, sandpiper.AccessLevel) error {
+	return nil
+}
+
+func TestSetMetadata(t *testing.T) {
+	cases := []struct {
+		name      string
+		allowSync bool
+		meta      sandpiper.MetaMap
+		replace   bool
+		wantErr   error
+		wantCode  int
+	}{
+		{
+			name:      "Slice not locked",
+			allowSync: true,
+			meta:      sandpiper.MetaMap{"pcdb": "2020-01"},
+			wantErr:   ErrSliceNotLocked,
+		},
+		{
+			name:      "Replace on a slice not locked",
+			allowSync: true,
+			replace:   true,
+			wantErr:   ErrSliceNotLocked,
+		},
+		{
+			name:    "No metadata",
+			wantErr: ErrNoMetadata,
+		},
+		{
+			name:     "Invalid key",
+			meta:     sandpiper.MetaMap{"pcdb version": "2020-01"},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &metaRepo{slice: &sandpiper.Slice{AllowSync: tt.allowSync}}
+			s := &Slice{sdb: repo, rbac: admin{}}
+			_, err := s.SetMetadata(nil, uuid.New(), tt.meta, tt.replace)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+			} else if assert.IsType(t, &echo.HTTPError{}, err) {
+				assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+			}
+			assert.False(t, repo.changed)
+		})
+	}
+}
+
+func TestDeleteMetadata(t *testing.T) {
+	repo := &metaRepo{slice: &sandpiper.Slice{AllowSync: true}}
+	s := &Slice{sdb: repo, rbac: admin{}}
+	assert.Equal(t, ErrSliceNotLocked, s.DeleteMetadata(nil, uuid.New(), "pcdb"))
+	assert.False(t, repo.changed)
+}
@@ -0,0 +1,151 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// Composite slices (slices built from other slices).
+
+// A composite slice holds no grains of its own. Its content is the union of its member slices
+// (which must be regular slices of the same slice-type) and its hash is derived from theirs.
+// Subscribing to a composite gives access to the grains of all of its members.
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Custom errors
+var (
+	ErrNotComposite    = echo.NewHTTPError(http.StatusConflict, "Members can only be added to a composite slice.")
+	ErrNestedComposite = echo.NewHTTPError(http.StatusConflict, "A composite slice cannot be a member of another slice.")
+	ErrMemberType      = echo.NewHTTPError(http.StatusConflict, "Member slices must have the same slice-type as the composite.")
+	ErrMemberNotFound  = echo.NewHTTPError(http.StatusNotFound, "Member slice not found.")
+)
+
+// Members returns the member slices of a composite slice (sorted by id)
+func (s *Slice) Members(db orm.DB, compositeID uuid.UUID) ([]sandpiper.Slice, error) {
+	return members(db, compositeID)
+}
+
+// ReplaceMembers sets the member slices of a composite slice (replacing any existing members)
+func (s *Slice) ReplaceMembers(db orm.DB, compositeID uuid.UUID, memberIDs []uuid.UUID) error {
+	composite := &sandpiper.Slice{ID: compositeID}
+	if err := db.Model(composite).Column("slice_type", "composite").WherePK().Select(); err != nil {
+		return selectError(err)
+	}
+	if !composite.Composite {
+		return ErrNotComposite
+	}
+
+	if _, err := db.Model((*sandpiper.SliceMember)(nil)).
+		Where("composite_id = ?", compositeID).Delete(); err != nil {
+		return err
+	}
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	var members []sandpiper.Slice
+	if err := db.Model(&members).Column("id", "slice_type", "composite").
+		Where("id IN (?)", pg.In(memberIDs)).Select(); err != nil {
+		return err
+	}
+	found := make(map[uuid.UUID]bool)
+	for _, m := range members {
+		switch {
+		case m.Composite:
+			return ErrNestedComposite
+		case m.SliceType != composite.SliceType:
+			return ErrMemberType
+		}
+		found[m.ID] = true
+	}
+
+	for _, id := range memberIDs {
+		if !found[id] {
+			return ErrMemberNotFound
+		}
+		sm := &sandpiper.SliceMember{CompositeID: compositeID, MemberID: id}
+		if _, err := db.Model(sm).OnConflict("DO NOTHING").Insert(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContentSlices returns a subquery of the slice ids holding grains for a slice (its members
+// if a composite, or just the slice itself)
+func ContentSlices(db orm.DB, sliceID uuid.UUID) *orm.Query {
+	return db.Model((*sandpiper.SliceMember)(nil)).
+		ColumnExpr("member_id AS slice_id").
+		Where("composite_id = ?", sliceID).
+		Union(db.Model().ColumnExpr("?::uuid AS slice_id", sliceID))
+}
+
+// AccessibleSlices returns a subquery of the slice ids a company can read grains from (active
+// subscriptions within their terms plus the members of any subscribed composite slices)
+func AccessibleSlices(db orm.DB, companyID uuid.UUID) *orm.Query {
+	return db.Model().TableExpr("subscriptions AS sub").ColumnExpr("sub.slice_id").
+		Where("sub.company_id = ?", companyID).Where(sandpiper.SubInTerm).
+		Union(db.Model().TableExpr("subscriptions AS sub").
+			ColumnExpr("sm.member_id AS slice_id").
+			Join("JOIN slice_members AS sm ON sm.composite_id = sub.slice_id").
+			Where("sub.company_id = ?", companyID).Where(sandpiper.SubInTerm))
+}
+
+// members returns the member slices of a composite slice (sorted by id)
+func members(db orm.DB, compositeID uuid.UUID) ([]sandpiper.Slice, error) {
+	var ms []sandpiper.Slice
+	err := db.Model(&ms).
+		Where("id IN (?)", db.Model((*sandpiper.SliceMember)(nil)).
+			Column("member_id").Where("composite_id = ?", compositeID)).
+		Order("id").Select()
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// composites returns the ids of composite slices that include a member slice
+func composites(db orm.DB, memberID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Model((*sandpiper.SliceMember)(nil)).Column("composite_id").
+		Where("member_id = ?", memberID).Select(&ids)
+	return ids, err
+}
+
+// hashComposite returns a sha1 hash of a composite slice's metadata and member content
+// (members must be refreshed first) and the total grain count of its members
+func hashComposite(db orm.DB, sliceID uuid.UUID, meta sandpiper.MetaArray) (string, int, error) {
+	var b bytes.Buffer
+
+	ms, err := members(db, sliceID)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(meta) == 0 && len(ms) == 0 {
+		return "", 0, nil
+	}
+
+	count := 0
+	for _, m := range ms {
+		b.Write(m.ID[:])
+		b.WriteString(m.ContentHash)
+		count += m.ContentCount
+	}
+	for _, m := range meta {
+		b.WriteString(m.Key)
+		b.WriteString(m.Value)
+	}
+	return fmt.Sprintf("%x", sha1.Sum(b.Bytes())), count, nil
+}
@@ -0,0 +1,108 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slice/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+)
+
+var (
+	compositeID = uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	memberA     = uuid.MustParse("20000000-0000-0000-0000-000000000000")
+	memberB     = uuid.MustParse("30000000-0000-0000-0000-000000000000")
+)
+
+func TestReplaceMembers(t *testing.T) {
+	const (
+		selectComposite = `SELECT "slice_type", "composite" FROM "slices"`
+		selectMembers   = `SELECT "id", "slice_type", "composite" FROM "slices"`
+	)
+	aces := mockdb.Row{"slice_type": "aces-file", "composite": true}
+	member := func(id uuid.UUID, sliceType string, composite bool) mockdb.Row {
+		return mockdb.Row{"id": id, "slice_type": sliceType, "composite": composite}
+	}
+
+	cases := []struct {
+		name        string
+		composite   []mockdb.Row
+		members     []mockdb.Row
+		ids         []uuid.UUID
+		wantErr     error
+		wantInserts int
+	}{
+		{
+			name:      "Not a composite",
+			composite: []mockdb.Row{{"slice_type": "aces-file", "composite": false}},
+			ids:       []uuid.UUID{memberA},
+			wantErr:   pgsql.ErrNotComposite,
+		},
+		{
+			name:      "Nested composite",
+			composite: []mockdb.Row{aces},
+			members:   []mockdb.Row{member(memberA, "aces-file", false), member(memberB, "aces-file", true)},
+			ids:       []uuid.UUID{memberA, memberB},
+			wantErr:   pgsql.ErrNestedComposite,
+		},
+		{
+			name:      "Slice-type mismatch",
+			composite: []mockdb.Row{aces},
+			members:   []mockdb.Row{member(memberA, "pies-file", false)},
+			ids:       []uuid.UUID{memberA},
+			wantErr:   pgsql.ErrMemberType,
+		},
+		{
+			name:        "Success",
+			composite:   []mockdb.Row{aces},
+			members:     []mockdb.Row{member(memberA, "aces-file", false), member(memberB, "aces-file", false)},
+			ids:         []uuid.UUID{memberA, memberB},
+			wantInserts: 2,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := (&mockdb.DB{}).
+				On(selectComposite, tt.composite...).
+				On(selectMembers, tt.members...)
+			err := pgsql.NewSlice().ReplaceMembers(db, compositeID, tt.ids)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantInserts, len(db.Find(`INSERT INTO "slice_members"`)))
+			if tt.wantErr == nil {
+				assert.Equal(t, 1, len(db.Find(`DELETE FROM "slice_members"`))) // existing members replaced
+			}
+		})
+	}
+}
+
+func TestRefreshComposite(t *testing.T) {
+	// refreshing a member also refreshes the composites that include it
+	refresh := func(hashA string) string {
+		db := (&mockdb.DB{}).
+			On(`"composite_id" FROM "slice_members"`).
+			On(fmt.Sprintf(`"composite_id" FROM "slice_members" AS "slice_member" WHERE (member_id = '%s')`, memberA),
+				mockdb.Row{"composite_id": compositeID}).
+			On(fmt.Sprintf(`SELECT "composite" FROM "slices" AS "slice" WHERE (id = '%s')`, compositeID),
+				mockdb.Row{"composite": true}).
+			On(`FROM "slices" AS "slice" WHERE (id IN (SELECT "member_id"`,
+				mockdb.Row{"id": memberA, "content_hash": hashA, "content_count": 3},
+				mockdb.Row{"id": memberB, "content_hash": "bbb", "content_count": 4})
+
+		assert.NoError(t, pgsql.NewSlice().Refresh(db, memberA))
+		updates := db.Find(`UPDATE "slices"`)
+		if !assert.Equal(t, 2, len(updates)) {
+			return ""
+		}
+		return updates[1]
+	}
+
+	// a member's new content changes the composite
+	assert.NotEqual(t, refresh("aaa"), refresh("ccc"))
+}
@@ -0,0 +1,251 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// Encryption of grain payloads at rest (for slices with the "encrypted" flag).
+
+// The stored payload (already encoded) is sealed with the slice's active data key and the
+// grain's "key_id" records which key was used. Any service adding grains calls Seal before
+// inserting and any service returning payloads calls Open after selecting.
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+
+	searchsvc "github.com/sandpiper-framework/sandpiper/pkg/api/search/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
+)
+
+// rekeyBatchSize is the number of grains re-encrypted per query
+const rekeyBatchSize = 100
+
+// Crypter encrypts and decrypts grain payloads. Slice settings and unwrapped data keys are
+// cached, so create a new one for each request (or job).
+type Crypter struct {
+	db     orm.DB
+	kr     *secure.Keyring
+	active map[uuid.UUID]*sandpiper.SliceKey // active key by slice_id (nil if not encrypted)
+	keys   map[int]string                    // unwrapped data keys by key_id
+}
+
+// NewCrypter returns a grain payload crypter
+func NewCrypter(db orm.DB, kr *secure.Keyring) *Crypter {
+	return &Crypter{
+		db:     db,
+		kr:     kr,
+		active: make(map[uuid.UUID]*sandpiper.SliceKey),
+		keys:   make(map[int]string),
+	}
+}
+
+// Seal encrypts payloads (in place) for grains in encrypted slices
+func (c *Crypter) Seal(grains ...*sandpiper.Grain) error {
+	for _, g := range grains {
+		if g.KeyID != 0 || g.SliceID == nil {
+			continue // already sealed (or not ready to insert)
+		}
+		sk, err := c.activeKey(*g.SliceID)
+		if err != nil {
+			return err
+		}
+		if sk == nil {
+			continue // slice is not encrypted
+		}
+		sealed, err := secure.Seal([]byte(g.Payload), c.keys[sk.ID])
+		if err != nil {
+			return err
+		}
+		g.Payload = payload.PayloadData(sealed)
+		g.KeyID = sk.ID
+	}
+	return nil
+}
+
+// Open decrypts payloads (in place) for grains that were encrypted at rest
+func (c *Crypter) Open(grains ...*sandpiper.Grain) error {
+	for _, g := range grains {
+		if g.KeyID == 0 || g.Payload == "" {
+			continue // not encrypted (or payload not selected)
+		}
+		key, err := c.dataKey(g.KeyID)
+		if err != nil {
+			return err
+		}
+		data, err := secure.Open(string(g.Payload), key)
+		if err != nil {
+			return err
+		}
+		g.Payload = payload.PayloadData(data)
+		g.KeyID = 0
+	}
+	return nil
+}
+
+// activeKey returns the key for new payloads in a slice (adding one if necessary), or nil if
+// the slice is not encrypted
+func (c *Crypter) activeKey(sliceID uuid.UUID) (*sandpiper.SliceKey, error) {
+	if sk, ok := c.active[sliceID]; ok {
+		return sk, nil
+	}
+
+	var encrypted bool
+	err := c.db.Model((*sandpiper.Slice)(nil)).Column("encrypted").
+		Where("id = ?", sliceID).Select(pg.Scan(&encrypted))
+	if err != nil {
+		return nil, selectError(err)
+	}
+	if !encrypted {
+		c.active[sliceID] = nil
+		return nil, nil
+	}
+
+	sk := new(sandpiper.SliceKey)
+	err = c.db.Model(sk).Where("slice_id = ?", sliceID).Where("active").Select()
+	switch err {
+	case nil:
+		if _, err := c.dataKey(sk.ID); err != nil {
+			return nil, err
+		}
+	case pg.ErrNoRows:
+		key, wrapped, err := c.kr.NewDataKey()
+		if err != nil {
+			return nil, err
+		}
+		sk = &sandpiper.SliceKey{SliceID: sliceID, DataKey: wrapped, Active: true}
+		if err := c.db.Insert(sk); err != nil {
+			return nil, err
+		}
+		c.keys[sk.ID] = key
+	default:
+		return nil, err
+	}
+	c.active[sliceID] = sk
+	return sk, nil
+}
+
+// dataKey returns an unwrapped data key by id
+func (c *Crypter) dataKey(keyID int) (string, error) {
+	if key, ok := c.keys[keyID]; ok {
+		return key, nil
+	}
+	sk := &sandpiper.SliceKey{ID: keyID}
+	if err := c.db.Model(sk).WherePK().Select(); err != nil {
+		return "", err
+	}
+	key, _, err := c.kr.Unwrap(sk.DataKey)
+	if err != nil {
+		return "", err
+	}
+	c.keys[keyID] = key
+	return key, nil
+}
+
+// Rekey sets a slice's encryption flag and re-encrypts (or decrypts) all of its grains. A new
+// data key is always created for an encrypted slice, so calling it again rotates the key. Old
+// keys are removed when no longer used (release copies keep their keys). Should be run in a
+// transaction.
+func (s *Slice) Rekey(db orm.DB, kr *secure.Keyring, sliceID uuid.UUID, encrypt bool) (*sandpiper.SliceKeyRotation, error) {
+	slice := &sandpiper.Slice{ID: sliceID, Encrypted: encrypt}
+	res, err := db.Model(slice).Column("encrypted").WherePK().Update()
+	if err != nil {
+		return nil, err
+	}
+	if res.RowsAffected() == 0 {
+		return nil, ErrSliceNotFound
+	}
+
+	// retire the current key (a new one is added on first use)
+	_, err = db.Model((*sandpiper.SliceKey)(nil)).Set("active = false").
+		Where("slice_id = ?", sliceID).Where("active").Update()
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewCrypter(db, kr)
+	result := &sandpiper.SliceKeyRotation{SliceID: sliceID, Encrypted: encrypt}
+	if encrypt {
+		sk, err := c.activeKey(sliceID)
+		if err != nil {
+			return nil, err
+		}
+		result.KeyID = sk.ID
+	}
+
+	for {
+		// each pass selects grains not yet using the new key (or still encrypted)
+		var grains []sandpiper.Grain
+		q := db.Model(&grains).
+			Column("id", "slice_id", "grain_key", "source", "encoding", "payload", "key_id").
+			Where("slice_id = ?", sliceID).Order("id").Limit(rekeyBatchSize)
+		if encrypt {
+			q = q.Where("key_id IS DISTINCT FROM ?", result.KeyID)
+		} else {
+			q = q.Where("key_id IS NOT NULL")
+		}
+		if err := q.Select(); err != nil {
+			return nil, err
+		}
+		if len(grains) == 0 {
+			break
+		}
+		for i := range grains {
+			g := &grains[i]
+			if err := c.Open(g); err != nil {
+				return nil, err
+			}
+			if err := c.Seal(g); err != nil {
+				return nil, err
+			}
+			if _, err := db.Model(g).Column("payload", "key_id").WherePK().Update(); err != nil {
+				return nil, err
+			}
+			// payload text is only searchable when not encrypted
+			if err := searchsvc.Index(db, g); err != nil {
+				return nil, err
+			}
+		}
+		result.Grains += len(grains)
+	}
+
+	// previous keys are no longer used by any grain (but may be by a release)
+	_, err = db.Model((*sandpiper.SliceKey)(nil)).
+		Where("slice_id = ?", sliceID).Where("NOT active").
+		Where("id NOT IN (SELECT key_id FROM release_grains WHERE key_id IS NOT NULL)").Delete()
+	if err != nil && err != pg.ErrNoRows {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Rewrap wraps every data key with the current master key (after a master key rotation) and
+// returns the number of keys changed. Retired master keys can be removed from the config after.
+func (s *Slice) Rewrap(db orm.DB, kr *secure.Keyring) (int, error) {
+	var keys []sandpiper.SliceKey
+	if err := db.Model(&keys).Order("id").Select(); err != nil {
+		return 0, err
+	}
+	var count int
+	for i := range keys {
+		sk := &keys[i]
+		key, current, err := kr.Unwrap(sk.DataKey)
+		if err != nil {
+			return count, err
+		}
+		if current {
+			continue
+		}
+		if sk.DataKey, err = kr.Wrap(key); err != nil {
+			return count, err
+		}
+		if _, err := db.Model(sk).Column("data_key").WherePK().Update(); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
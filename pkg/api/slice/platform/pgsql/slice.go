@@ -20,6 +20,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	slicetypesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
@@ -28,6 +29,7 @@ import (
 var (
 	ErrAlreadyExists = echo.NewHTTPError(http.StatusInternalServerError, "Slice name already exists.")
 	ErrSliceNotFound = echo.NewHTTPError(http.StatusNotFound, "Slice not found.")
+	ErrMetaNotFound  = echo.NewHTTPError(http.StatusNotFound, "Slice metadata key not found.")
 )
 
 // Slice represents the client for slice table
@@ -51,48 +53,18 @@ func (sl sliceList) IDs() []uuid.UUID {
 	return ids
 }
 
-// FilterByTags creates a new Slices array using the tag query
-func (sl sliceList) FilterByTags(db orm.DB, tags *params.TagQuery) (sliceList, error) {
-	var tagged sliceList
+// taggedSlices returns a subquery of slice ids matching the tag query
+func taggedSlices(db orm.DB, tags *params.TagQuery) *orm.Query {
 	// See "Toxi" Solution http://howto.philippkeller.com/2005/04/24/Tags-Database-schemas/
-
-	if tags.IsUnion { // slice must include at least one of the tags (union)
-		err := db.Model(&tagged).Column("slice.id").
-			Join("INNER JOIN slice_tags AS st ON slice.id = st.slice_id").
-			Join("INNER JOIN tags AS t ON st.tag_id = t.id").
-			Where("t.name IN (?)", pg.In(tags.TagList)).
-			Group("slice.id").Select()
-		if err != nil {
-			return nil, err
-		}
-	} else { // slice must include all of the tags (intersection)
-		err := db.Model(&tagged).Column("slice.id").
-			Join("INNER JOIN slice_tags AS st ON slice.id = st.slice_id").
-			Join("INNER JOIN tags AS t ON st.tag_id = t.id").
-			Where("t.name IN (?)", pg.In(tags.TagList)).
-			Group("slice.id").
-			Having("COUNT(slice.id) = ?", tags.Count()).Select()
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// put tagged slice ids in a set (map) for fast access
-	taggedSet := make(map[uuid.UUID]bool)
-	for _, slice := range tagged {
-		taggedSet[slice.ID] = true
+	q := db.Model().TableExpr("slice_tags AS st").Column("st.slice_id").
+		Join("INNER JOIN tags AS t ON st.tag_id = t.id").
+		Where("t.name IN (?)", pg.In(tags.TagList)).
+		Group("st.slice_id")
+	if !tags.IsUnion {
+		// slice must include all of the tags (intersection)
+		q = q.Having("COUNT(st.slice_id) = ?", tags.Count())
 	}
-
-	// run through received slice list "sl" and include in results only if found in tagged set
-	// uses "filtering without allocating" (https://github.com/golang/go/wiki/SliceTricks)
-	results := sl[:0]
-	for _, slice := range sl {
-		if taggedSet[slice.ID] {
-			results = append(results, slice)
-		}
-	}
-
-	return results, nil
+	return q
 }
 
 // Create adds a new slice with optional metadata (assumes allowed to do this)
@@ -146,6 +118,12 @@ func (s *Slice) ViewBySub(db orm.DB, companyID uuid.UUID, sliceID uuid.UUID) (*s
 
 	// insert any metadata for the slice as a map
 	slice.Metadata, err = metaDataMap(db, sliceID)
+	if err != nil || !slice.Composite {
+		return slice, err
+	}
+
+	// include member slices of a composite
+	slice.Members, err = members(db, sliceID)
 
 	return slice, err
 }
@@ -177,7 +155,16 @@ func (s *Slice) ViewByName(db orm.DB, companyID uuid.UUID, name string) (*sandpi
 	return slice, err
 }
 
-// List returns a list of all slices limited by scope and paginated
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("slice", "id", "name", "slice_type", "content_hash", "content_count",
+		"content_date", "allow_sync", "encrypted", "composite", "sync_status", "last_good_sync",
+		"created_at", "updated_at"),
+	Sort: params.Columns("slice", "name", "slice_type", "content_count", "content_date",
+		"allow_sync", "sync_status", "last_good_sync", "created_at", "updated_at"),
+}
+
+// List returns a list of all slices (optionally limited by tags) limited by scope and paginated
 func (s *Slice) List(db orm.DB, p *params.Params, tags *params.TagQuery, sc *sandpiper.Scope) ([]sandpiper.Slice, error) {
 	var slices sliceList
 
@@ -189,30 +176,31 @@ func (s *Slice) List(db orm.DB, p *params.Params, tags *params.TagQuery, sc *san
 		return q, nil
 	}
 
-	// we can't use sql pagination because we could further limit by tags
-	err := db.Model(&slices).Relation("Companies", filterFn).Order("name").Select()
-	if err != nil {
-		return nil, err
+	q := db.Model(&slices).Relation("Companies", filterFn)
+	if sc != nil {
+		// only slices the scope (i.e. the company) can read (including composite members)
+		q = q.Where("slice.id IN (?)", AccessibleSlices(db, sc.ID))
 	}
-
-	// create new Slices limited to TagQuery (if one was provided)
 	if tags.Provided() {
-		slices, err = slices.FilterByTags(db, tags)
-		if err != nil {
-			return nil, err
-		}
+		q = q.Where("slice.id IN (?)", taggedSlices(db, tags))
+	}
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "name"); err != nil {
+		return nil, err
 	}
+	q = q.Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
 
+	var err error
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
 	if len(slices) == 0 {
 		return slices, nil
 	}
 
-	// perform our own pagination (rather than with sql)
-	offset := p.Paging.Offset() - 1
-	if offset >= 0 {
-		slices = slices[offset : offset+p.Paging.PageSize]
-	}
-
 	// look up metadata for all slices returned above (using an "in" list)
 	var meta sandpiper.MetaArray
 	ids := slices.IDs()
@@ -240,20 +228,78 @@ func (s *Slice) Metadata(db orm.DB, sliceID uuid.UUID) (sandpiper.MetaArray, err
 	return meta, nil
 }
 
+// ReleaseMetadata returns the metadata of the release a company's subscription to a slice is
+// pinned to (or nil if tracking the latest content)
+func (s *Slice) ReleaseMetadata(db orm.DB, companyID, sliceID uuid.UUID) (sandpiper.MetaArray, error) {
+	rel := new(sandpiper.SliceRelease)
+	err := db.Model(rel).Column("slice_release.metadata").
+		Join("INNER JOIN subscriptions AS sub ON sub.release_id = slice_release.id").
+		Where("sub.company_id = ?", companyID).
+		Where("sub.slice_id = ?", sliceID).Select()
+	switch err {
+	case pg.ErrNoRows:
+		return nil, nil
+	case nil:
+		return rel.Metadata.ToArray(sliceID), nil
+	default:
+		return nil, err
+	}
+}
+
+// SetMetadata adds (or changes) metadata keys for a slice, first removing all existing keys
+// if replacing (assumes allowed to do this)
+func (s *Slice) SetMetadata(db orm.DB, sliceID uuid.UUID, meta sandpiper.MetaMap, replace bool) error {
+	if replace {
+		_, err := db.Model((*sandpiper.SliceMetadata)(nil)).Where("slice_id = ?", sliceID).Delete()
+		if err != nil && err != pg.ErrNoRows {
+			return err
+		}
+	}
+	m := &sandpiper.SliceMetadata{SliceID: sliceID}
+	for k, v := range meta {
+		m.Key, m.Value = k, v
+		_, err := db.Model(m).OnConflict("(slice_id, key) DO UPDATE").Set("value = EXCLUDED.value").Insert()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMetadata removes a metadata key from a slice (assumes allowed to do this)
+func (s *Slice) DeleteMetadata(db orm.DB, sliceID uuid.UUID, key string) error {
+	res, err := db.Model((*sandpiper.SliceMetadata)(nil)).
+		Where("slice_id = ?", sliceID).Where("key = ?", key).Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrMetaNotFound
+	}
+	return nil
+}
+
 // Update updates slice info by primary key (assumes allowed to do this)
 func (s *Slice) Update(db orm.DB, slice *sandpiper.Slice) error {
-	// todo: should we delete all metadata and re-add from the map?
-	_, err := db.Model(slice).Update()
+	// metadata is changed with SetMetadata and DeleteMetadata
+	// encryption is only changed by Rekey (which also re-encrypts grains)
+	// and a slice cannot become (or stop being) a composite once created
+	_, err := db.Model(slice).WherePK().ExcludeColumn("encrypted", "composite").Update()
 	return err
 }
 
+// SliceTypes returns the registered slice types
+func (s *Slice) SliceTypes(db orm.DB) ([]sandpiper.SliceType, error) {
+	return slicetypesvc.All(db)
+}
+
 // Delete a slice
 func (s *Slice) Delete(db orm.DB, slice *sandpiper.Slice) error {
 	// WARNING: Foreign key constraints remove related metadata and grains!
 	return db.Delete(slice)
 }
 
-// Refresh a slice's content information
+// Refresh a slice's content information (and any composite slices that include it)
 func (s *Slice) Refresh(db orm.DB, sliceID uuid.UUID) error {
 
 	// calculate hash of all grains in the slice
@@ -270,8 +316,24 @@ func (s *Slice) Refresh(db orm.DB, sliceID uuid.UUID) error {
 		ContentDate:  time.Now(),
 	}
 	_, err = db.Model(&slice).Column("content_hash", "content_count", "content_date").WherePK().Update()
+	if err != nil {
+		return err
+	}
+	if err := CountBytes(db, sliceID); err != nil {
+		return err
+	}
 
-	return err
+	// a composite's hash is derived from its members, so refresh those including this slice
+	ids, err := composites(db, sliceID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.Refresh(db, id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Lock disallows sync operations on this slice
@@ -326,12 +388,24 @@ func selectError(err error) error {
 	return err
 }
 
-// HashSlice returns a sha1 hash of all metadata and grains in a slice
+// CountBytes saves the stored (compressed) payload size of a slice's grains (for metrics, so
+// a scrape doesn't have to read every grain)
+func CountBytes(db orm.DB, sliceID uuid.UUID) error {
+	_, err := db.Exec(`
+		UPDATE slices SET content_bytes =
+			(SELECT coalesce(sum(pg_column_size(payload)), 0) FROM grains WHERE slice_id = ?0)
+		WHERE id = ?0`, sliceID)
+	return err
+}
+
+// HashSlice returns a sha1 hash of all metadata and grains in a slice (or of the metadata
+// and member hashes for a composite slice)
 func HashSlice(db orm.DB, sliceID uuid.UUID) (string, int, error) {
 	var (
-		ids  []uuid.UUID
-		b    bytes.Buffer
-		meta sandpiper.MetaArray
+		ids       []uuid.UUID
+		b         bytes.Buffer
+		meta      sandpiper.MetaArray
+		composite bool
 	)
 
 	var hash = func(meta sandpiper.MetaArray, ids []uuid.UUID) string {
@@ -356,6 +430,16 @@ func HashSlice(db orm.DB, sliceID uuid.UUID) (string, int, error) {
 		return "", 0, err
 	}
 
+	// composite slices hold no grains of their own
+	err := db.Model((*sandpiper.Slice)(nil)).Column("composite").
+		Where("id = ?", sliceID).Select(pg.Scan(&composite))
+	if err != nil && err != pg.ErrNoRows {
+		return "", 0, err
+	}
+	if composite {
+		return hashComposite(db, sliceID, meta)
+	}
+
 	// get grain ids for the slice (sorted!)
 	if err := db.Model().Table("grains").Column("id").
 		Where("slice_id = ?", sliceID).
@@ -5,7 +5,21 @@
 package pgsql_test
 
 import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slice/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
 func TestCreate(t *testing.T) {
@@ -21,9 +35,164 @@ func TestUpdate(t *testing.T) {
 }
 
 func TestList(t *testing.T) {
+	companyID := uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	sliceA := uuid.MustParse("a0000000-0000-0000-0000-000000000000")
+	sliceB := uuid.MustParse("b0000000-0000-0000-0000-000000000000")
+	scope := &sandpiper.Scope{Condition: "company_id = ?", ID: companyID}
+	composite := fmt.Sprintf("JOIN slice_members AS sm ON sm.composite_id = sub.slice_id WHERE (sub.company_id = '%s') AND (%s)",
+		companyID, sandpiper.SubInTerm)
+
+	cases := []struct {
+		name     string
+		filter   []string
+		sort     []string
+		page     int
+		tags     *params.TagQuery
+		scope    *sandpiper.Scope
+		wantErr  bool
+		wantSQL  []string
+		skipSQL  []string
+		wantMeta bool
+	}{
+		{
+			name:    "All slices (sorted by name)",
+			tags:    &params.TagQuery{},
+			wantSQL: []string{`ORDER BY "name" LIMIT 50`},
+			skipSQL: []string{"subscriptions AS sub", "slice_tags"},
+		},
+		{
+			name:    "Scoped to active subscriptions (and composite members)",
+			tags:    &params.TagQuery{},
+			scope:   scope,
+			wantSQL: []string{fmt.Sprintf("FROM subscriptions AS sub WHERE (sub.company_id = '%s') AND (%s)", companyID, sandpiper.SubInTerm), composite},
+		},
+		{
+			name:    "Any of the tags",
+			tags:    &params.TagQuery{IsUnion: true, TagList: []string{"aces", "pies"}},
+			wantSQL: []string{`WHERE (t.name IN ('aces','pies')) GROUP BY "st"."slice_id"))`},
+			skipSQL: []string{"HAVING"},
+		},
+		{
+			name:    "All of the tags",
+			tags:    &params.TagQuery{TagList: []string{"aces", "pies"}},
+			wantSQL: []string{"HAVING (COUNT(st.slice_id) = 2)"},
+		},
+		{
+			name:    "Sorted and paged",
+			sort:    []string{"content_date:desc,name"},
+			page:    3,
+			tags:    &params.TagQuery{},
+			wantSQL: []string{`ORDER BY "slice"."content_date" DESC, "slice"."name" ASC LIMIT 50 OFFSET 100`},
+		},
+		{
+			name:    "Invalid sort",
+			sort:    []string{"sync_api_key"},
+			tags:    &params.TagQuery{},
+			wantErr: true,
+		},
+		{
+			name:    "Filtered",
+			filter:  []string{"slice_type:aces-file,allow_sync:true"},
+			tags:    &params.TagQuery{},
+			wantSQL: []string{`("slice"."slice_type" = 'aces-file') AND ("slice"."allow_sync" = 'true')`},
+		},
+		{
+			name:    "Unknown filter",
+			filter:  []string{"sync_api_key:x"},
+			tags:    &params.TagQuery{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := (&mockdb.DB{}).
+				On(`FROM "slices"`, mockdb.Row{"id": sliceA, "name": "a"}, mockdb.Row{"id": sliceB, "name": "b"}).
+				On(`SELECT count(*) FROM "slices"`, mockdb.Row{"count": 2}).
+				On(`FROM "slice_metadata"`, mockdb.Row{"slice_id": sliceB, "key": "pcdb", "value": "2020-01"})
+			p := &params.Params{Filter: tt.filter, Sort: tt.sort, Paging: sandpiper.NewPagination()}
+			if tt.page > 0 {
+				p.Paging.PageNumber = tt.page
+			}
+
+			slices, err := pgsql.NewSlice().List(db, p, tt.tags, tt.scope)
+			if tt.wantErr {
+				assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+				assert.Empty(t, db.SQL)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 2, p.Paging.Count)
+			if assert.Equal(t, 2, len(slices)) {
+				assert.Empty(t, slices[0].Metadata)
+				assert.Equal(t, sandpiper.MetaMap{"pcdb": "2020-01"}, slices[1].Metadata)
+			}
 
+			page, count := db.Find(`SELECT "slice"."id"`), db.Find(`SELECT count(*)`)
+			if !assert.Equal(t, 1, len(page)) || !assert.Equal(t, 1, len(count)) {
+				return
+			}
+			for _, s := range tt.wantSQL {
+				assert.Contains(t, page[0], s)
+				if !strings.Contains(s, "ORDER BY") {
+					assert.Contains(t, count[0], s) // counted with the same conditions
+				}
+			}
+			for _, s := range tt.skipSQL {
+				assert.NotContains(t, page[0], s)
+			}
+		})
+	}
 }
 
 func TestDelete(t *testing.T) {
 
 }
+
+func TestMetadataRefresh(t *testing.T) {
+	sliceID := uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	grain := uuid.MustParse("a0000000-0000-0000-0000-000000000000")
+	db := (&mockdb.DB{}).On(`SELECT "id" FROM "grains"`, mockdb.Row{"id": grain})
+
+	// the refreshed hash covers the grains and the (changed) metadata
+	refresh := func(meta ...mockdb.Row) string {
+		db.On(`FROM "slice_metadata"`, meta...)
+		assert.NoError(t, pgsql.NewSlice().Refresh(db, sliceID))
+		updates := db.Find(`UPDATE "slices"`)
+		return updates[len(updates)-1]
+	}
+	hash := func(s string) string {
+		return fmt.Sprintf(`"content_hash" = '%x'`, sha1.Sum([]byte(string(grain[:])+s)))
+	}
+
+	assert.Contains(t, refresh(), hash(""))
+
+	assert.NoError(t, pgsql.NewSlice().SetMetadata(db, sliceID, sandpiper.MetaMap{"pcdb": "2020-01"}, true))
+	assert.Equal(t, 1, len(db.Find(`DELETE FROM "slice_metadata"`)))
+	assert.Equal(t, 1, len(db.Find(`INSERT INTO "slice_metadata"`)))
+	assert.Contains(t, refresh(mockdb.Row{"slice_id": sliceID, "key": "pcdb", "value": "2020-01"}), hash("pcdb2020-01"))
+
+	db.On(`DELETE FROM "slice_metadata"`, mockdb.Row{})
+	assert.NoError(t, pgsql.NewSlice().DeleteMetadata(db, sliceID, "pcdb"))
+	assert.Contains(t, refresh(), hash(""))
+}
+
+func TestRefreshBytes(t *testing.T) {
+	// the payload size is saved with the content hash (so a metrics scrape only reads slices)
+	sliceID := uuid.MustParse("10000000-0000-0000-0000-000000000000")
+	db := &mockdb.DB{}
+	assert.NoError(t, pgsql.NewSlice().Refresh(db, sliceID))
+	counts := db.Find("SET content_bytes")
+	if assert.Equal(t, 1, len(counts)) {
+		assert.Contains(t, counts[0], "sum(pg_column_size(payload))")
+		assert.Contains(t, counts[0], "WHERE slice_id = '10000000-0000-0000-0000-000000000000'")
+		assert.Contains(t, counts[0], "WHERE id = '10000000-0000-0000-0000-000000000000'")
+	}
+
+	db.Fail("SET content_bytes", errors.New("db error"))
+	assert.EqualError(t, pgsql.NewSlice().Refresh(db, sliceID), "db error")
+}
+
+func TestDeleteMetadata(t *testing.T) {
+	err := pgsql.NewSlice().DeleteMetadata(&mockdb.DB{}, uuid.New(), "pcdb")
+	assert.Equal(t, pgsql.ErrMetaNotFound, err)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sandpiper-framework/sandpiper/pkg/api/slice"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 
 	sl "github.com/sandpiper-framework/sandpiper/pkg/api/slice/logging"
 	st "github.com/sandpiper-framework/sandpiper/pkg/api/slice/transport"
@@ -17,8 +18,8 @@ import (
 )
 
 // Register ties the slice service to its logger and transport mechanisms
-func Register(db *database.DB, sec slice.Securer, log sandpiper.Logger, v1 *echo.Group) {
-	svc := slice.Initialize(db, rbac.New(db.Settings.ServerRole), sec)
+func Register(db *database.DB, sec slice.Securer, log sandpiper.Logger, v1 *echo.Group, kr *secure.Keyring) {
+	svc := slice.Initialize(db, rbac.New(db.Settings.ServerRole), sec, kr)
 	ls := sl.ServiceLogger(svc, log)
 	st.NewHTTP(ls, v1)
 }
@@ -0,0 +1,44 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package slice
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
+)
+
+// rekeyRepo returns a slice (recording any key rotation, which should not happen here)
+type rekeyRepo struct {
+	Repository
+	slice   *sandpiper.Slice
+	rekeyed bool
+}
+
+func (r *rekeyRepo) View(orm.DB, uuid.UUID) (*sandpiper.Slice, error) {
+	return r.slice, nil
+}
+
+func (r *rekeyRepo) Rekey(orm.DB, *secure.Keyring, uuid.UUID, bool) (*sandpiper.SliceKeyRotation, error) {
+	r.rekeyed = true
+	return &sandpiper.SliceKeyRotation{}, nil
+}
+
+func TestRekeyComposite(t *testing.T) {
+	kr, err := secure.NewKeyring("u7WJ3kpqyvAkKb7HIfYJoSok2DoqTa9YhaCUhUujqb8=", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &rekeyRepo{slice: &sandpiper.Slice{Composite: true}}
+	s := &Slice{sdb: repo, rbac: admin{}, kr: kr}
+	_, err = s.Rekey(nil, uuid.New(), true)
+	assert.Equal(t, ErrEncryptedComposite, err)
+	assert.False(t, repo.rekeyed)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 )
 
 // Service represents slice application interface
@@ -23,21 +24,26 @@ type Service interface {
 	View(echo.Context, uuid.UUID) (*sandpiper.Slice, error)
 	ViewByName(echo.Context, string) (*sandpiper.Slice, error)
 	Metadata(echo.Context, uuid.UUID) (sandpiper.MetaArray, error)
+	SetMetadata(echo.Context, uuid.UUID, sandpiper.MetaMap, bool) (sandpiper.MetaArray, error)
+	DeleteMetadata(echo.Context, uuid.UUID, string) error
 	Delete(echo.Context, uuid.UUID) error
 	Update(echo.Context, *Update) (*sandpiper.Slice, error)
 	Refresh(echo.Context, uuid.UUID) error
 	Lock(echo.Context, uuid.UUID) error
 	Unlock(echo.Context, uuid.UUID) error
+	Rekey(echo.Context, uuid.UUID, bool) (*sandpiper.SliceKeyRotation, error)
+	Rewrap(echo.Context) (int, error)
+	ReplaceMembers(echo.Context, uuid.UUID, []uuid.UUID) (*sandpiper.Slice, error)
 }
 
 // New creates new slice application service
-func New(db *database.DB, sdb Repository, rbac RBAC, sec Securer) *Slice {
-	return &Slice{db: db.DB, sdb: sdb, rbac: rbac, sec: sec}
+func New(db *database.DB, sdb Repository, rbac RBAC, sec Securer, kr *secure.Keyring) *Slice {
+	return &Slice{db: db.DB, sdb: sdb, rbac: rbac, sec: sec, kr: kr}
 }
 
 // Initialize initializes Slice application service with defaults
-func Initialize(db *database.DB, rbac RBAC, sec Securer) *Slice {
-	return New(db, pgsql.NewSlice(), rbac, sec)
+func Initialize(db *database.DB, rbac RBAC, sec Securer, kr *secure.Keyring) *Slice {
+	return New(db, pgsql.NewSlice(), rbac, sec, kr)
 }
 
 // Slice represents slice application service
@@ -46,6 +52,7 @@ type Slice struct {
 	sdb  Repository
 	rbac RBAC
 	sec  Securer
+	kr   *secure.Keyring // for slices encrypted at rest
 }
 
 // Securer represents security interface
@@ -61,11 +68,18 @@ type Repository interface {
 	ViewByName(orm.DB, uuid.UUID, string) (*sandpiper.Slice, error)
 	List(orm.DB, *params.Params, *params.TagQuery, *sandpiper.Scope) ([]sandpiper.Slice, error)
 	Metadata(orm.DB, uuid.UUID) (sandpiper.MetaArray, error)
+	ReleaseMetadata(orm.DB, uuid.UUID, uuid.UUID) (sandpiper.MetaArray, error)
+	SetMetadata(orm.DB, uuid.UUID, sandpiper.MetaMap, bool) error
+	DeleteMetadata(orm.DB, uuid.UUID, string) error
 	Update(orm.DB, *sandpiper.Slice) error
 	Delete(orm.DB, *sandpiper.Slice) error
 	Refresh(orm.DB, uuid.UUID) error
 	Lock(orm.DB, uuid.UUID) error
 	Unlock(orm.DB, uuid.UUID) error
+	Rekey(orm.DB, *secure.Keyring, uuid.UUID, bool) (*sandpiper.SliceKeyRotation, error)
+	Rewrap(orm.DB, *secure.Keyring) (int, error)
+	ReplaceMembers(orm.DB, uuid.UUID, []uuid.UUID) error
+	SliceTypes(orm.DB) ([]sandpiper.SliceType, error)
 }
 
 // RBAC represents role-based-access-control interface
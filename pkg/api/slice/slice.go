@@ -6,12 +6,16 @@
 package slice
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
 	"time"
 
+	"github.com/go-pg/pg/v9"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
@@ -20,14 +24,56 @@ import (
 var (
 	// ErrTagsNotAllowed indicates the slice name is already used
 	ErrTagsNotAllowed = echo.NewHTTPError(http.StatusInternalServerError, "Not authorized for tagged queries")
+	// ErrNoMasterKey indicates an encrypted slice on a server without a master key
+	ErrNoMasterKey = echo.NewHTTPError(http.StatusConflict, "Encryption at rest requires a server master key (MASTER_KEY).")
+	// ErrEncryptedComposite indicates encryption requested for a slice without grains of its own
+	ErrEncryptedComposite = echo.NewHTTPError(http.StatusConflict, "Composite slices are encrypted by their members.")
+	// ErrSliceNotLocked indicates a content change to a slice that could be syncing
+	ErrSliceNotLocked = echo.NewHTTPError(http.StatusConflict, "Slice must be locked before changing metadata.")
+	// ErrNoMetadata indicates a metadata change without any keys
+	ErrNoMetadata = echo.NewHTTPError(http.StatusBadRequest, "No metadata provided.")
+	// ErrInvalidSliceType indicates a slice type missing from the slice-type registry
+	ErrInvalidSliceType = echo.NewHTTPError(http.StatusBadRequest, "Invalid slice-type (not registered).")
 )
 
-// Create creates a new slice to hold data-objects
+// resource identifies slices in the audit trail (metadata and member changes are slice updates)
+const resource = "slice"
+
+// metadata limits
+const (
+	maxMetaKeyLen   = 128
+	maxMetaValueLen = 4096
+)
+
+// metaKeyRE matches valid metadata keys (e.g. "aces.header.VcdbVersionDate")
+var metaKeyRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+// Create creates a new slice to hold data-objects (a data key for an encrypted slice is
+// added with the first grain)
 func (s *Slice) Create(c echo.Context, req sandpiper.Slice) (*sandpiper.Slice, error) {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
-	return s.sdb.Create(s.db, req)
+	if req.Encrypted && !s.kr.Enabled() {
+		return nil, ErrNoMasterKey
+	}
+	if req.Encrypted && req.Composite {
+		return nil, ErrEncryptedComposite
+	}
+	if err := s.checkType(req); err != nil {
+		return nil, err
+	}
+	var slice *sandpiper.Slice
+	err := s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if slice, err = s.sdb.Create(tx, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, slice.ID, nil, slice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slice, nil
 }
 
 // List returns list of slices
@@ -65,12 +111,106 @@ func (s *Slice) ViewByName(c echo.Context, name string) (*sandpiper.Slice, error
 	return s.sdb.ViewByName(s.db, companyID, name)
 }
 
-// Metadata returns an array of metadata records for a slice
+// Metadata returns an array of metadata records for a slice (or for the release the current
+// user's subscription is pinned to)
 func (s *Slice) Metadata(c echo.Context, sliceID uuid.UUID) (sandpiper.MetaArray, error) {
 	// todo: do we care who has access to this?
+	if au := s.rbac.CurrentUser(c); au.CompanyID != uuid.Nil {
+		meta, err := s.sdb.ReleaseMetadata(s.db, au.CompanyID, sliceID)
+		if err != nil || meta != nil {
+			return meta, err
+		}
+	}
 	return s.sdb.Metadata(s.db, sliceID)
 }
 
+// SetMetadata adds (or changes) metadata keys for a locked slice, removing all other keys if
+// replacing. The slice content information is refreshed with the change.
+func (s *Slice) SetMetadata(c echo.Context, id uuid.UUID, meta sandpiper.MetaMap, replace bool) (result sandpiper.MetaArray, err error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if len(meta) == 0 && !replace {
+		return nil, ErrNoMetadata
+	}
+	for k, v := range meta {
+		if err := validateMeta(k, v); err != nil {
+			return nil, err
+		}
+	}
+	before, err := s.enforceLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.SetMetadata(tx, id, meta, replace); err != nil {
+			return err
+		}
+		if err := s.sdb.Refresh(tx, id); err != nil {
+			return err
+		}
+		return s.audit(tx, c, id, before)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.sdb.Metadata(s.db, id)
+}
+
+// DeleteMetadata removes a metadata key from a locked slice and refreshes its content information
+func (s *Slice) DeleteMetadata(c echo.Context, id uuid.UUID, key string) error {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return err
+	}
+	before, err := s.enforceLocked(id)
+	if err != nil {
+		return err
+	}
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.DeleteMetadata(tx, id, key); err != nil {
+			return err
+		}
+		if err := s.sdb.Refresh(tx, id); err != nil {
+			return err
+		}
+		return s.audit(tx, c, id, before)
+	})
+}
+
+// enforceLocked returns a slice if it is locked (so a sync can't start during a content change)
+func (s *Slice) enforceLocked(id uuid.UUID) (*sandpiper.Slice, error) {
+	slice, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if slice.AllowSync {
+		return nil, ErrSliceNotLocked
+	}
+	return slice, nil
+}
+
+// audit records a slice update (with the slice as changed in the transaction)
+func (s *Slice) audit(tx *pg.Tx, c echo.Context, id uuid.UUID, before *sandpiper.Slice) error {
+	after, err := s.sdb.View(tx, id)
+	if err != nil {
+		return err
+	}
+	return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, id, before, after)
+}
+
+// validateMeta checks a metadata key and value
+func validateMeta(key, value string) error {
+	if len(key) > maxMetaKeyLen || !metaKeyRE.MatchString(key) {
+		msg := fmt.Sprintf("Invalid metadata key %q (use up to %d letters, digits, '.', '_', ':' or '-').", key, maxMetaKeyLen)
+		return echo.NewHTTPError(http.StatusBadRequest, msg)
+	}
+	if len(value) > maxMetaValueLen {
+		msg := fmt.Sprintf("Metadata value for %q is too long (maximum %d characters).", key, maxMetaValueLen)
+		return echo.NewHTTPError(http.StatusBadRequest, msg)
+	}
+	return nil
+}
+
 // Update contains slice information used for updating
 type Update struct {
 	ID           uuid.UUID
@@ -96,7 +236,21 @@ func (s *Slice) Update(c echo.Context, r *Update) (*sandpiper.Slice, error) {
 		ContentCount: r.ContentCount,
 		ContentDate:  r.ContentDate,
 	}
-	err := s.sdb.Update(s.db, slice)
+	if slice.SliceType != "" {
+		if err := s.checkType(*slice); err != nil {
+			return nil, err
+		}
+	}
+	before, err := s.sdb.View(s.db, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Update(tx, slice); err != nil {
+			return err
+		}
+		return s.audit(tx, c, r.ID, before)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +266,12 @@ func (s *Slice) Delete(c echo.Context, id uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	return s.sdb.Delete(s.db, slice)
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Delete(tx, slice); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, id, slice, nil)
+	})
 }
 
 // Refresh updates slice content information
@@ -128,7 +287,80 @@ func (s *Slice) Lock(c echo.Context, id uuid.UUID) error {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return err
 	}
-	return s.sdb.Lock(s.db, id)
+	before, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return err
+	}
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Lock(tx, id); err != nil {
+			return err
+		}
+		return s.audit(tx, c, id, before)
+	})
+}
+
+// Rekey turns encryption at rest on (or off) for a slice and re-encrypts its grains with a
+// new data key. Calling it for an encrypted slice rotates the data key.
+func (s *Slice) Rekey(c echo.Context, id uuid.UUID, encrypt bool) (result *sandpiper.SliceKeyRotation, err error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if !s.kr.Enabled() {
+		return nil, ErrNoMasterKey
+	}
+	before, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if encrypt && before.Composite {
+		return nil, ErrEncryptedComposite
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if result, err = s.sdb.Rekey(tx, s.kr, id, encrypt); err != nil {
+			return err
+		}
+		return s.audit(tx, c, id, before)
+	})
+	return result, err
+}
+
+// Rewrap wraps all data keys with the current master key (after a master key rotation)
+func (s *Slice) Rewrap(c echo.Context) (count int, err error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return 0, err
+	}
+	if !s.kr.Enabled() {
+		return 0, ErrNoMasterKey
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		count, err = s.sdb.Rewrap(tx, s.kr)
+		return err
+	})
+	return count, err
+}
+
+// ReplaceMembers sets the member slices of a composite slice and refreshes its content information
+func (s *Slice) ReplaceMembers(c echo.Context, id uuid.UUID, memberIDs []uuid.UUID) (*sandpiper.Slice, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	before, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.ReplaceMembers(tx, id, memberIDs); err != nil {
+			return err
+		}
+		if err := s.sdb.Refresh(tx, id); err != nil {
+			return err
+		}
+		return s.audit(tx, c, id, before)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.sdb.View(s.db, id)
 }
 
 // Unlock allows a sync to start
@@ -136,5 +368,26 @@ func (s *Slice) Unlock(c echo.Context, id uuid.UUID) error {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return err
 	}
-	return s.sdb.Unlock(s.db, id)
+	before, err := s.sdb.View(s.db, id)
+	if err != nil {
+		return err
+	}
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Unlock(tx, id); err != nil {
+			return err
+		}
+		return s.audit(tx, c, id, before)
+	})
+}
+
+// checkType makes sure the slice type is registered
+func (s *Slice) checkType(slice sandpiper.Slice) error {
+	types, err := s.sdb.SliceTypes(s.db)
+	if err != nil {
+		return err
+	}
+	if !slice.Validate(types) {
+		return ErrInvalidSliceType
+	}
+	return nil
 }
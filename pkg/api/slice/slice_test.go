@@ -5,8 +5,10 @@
 package slice_test
 
 import (
-	"github.com/sandpiper-framework/sandpiper/pkg/api/slice"
 	"testing"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slice"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
 )
 
 func TestCreate(t *testing.T) {
@@ -30,7 +32,7 @@ func TestUpdate(t *testing.T) {
 }
 
 func TestInitialize(t *testing.T) {
-	s := slice.Initialize(nil, nil, nil)
+	s := slice.Initialize(&database.DB{}, nil, nil, nil)
 	if s == nil {
 		t.Error("Slice service not initialized")
 	}
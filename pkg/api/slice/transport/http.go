@@ -35,15 +35,21 @@ func NewHTTP(svc slice.Service, er *echo.Group) {
 	sr.DELETE("/:id", h.delete)
 	sr.GET("/name/:name", h.viewByName)
 	sr.GET("/metadata/:id", h.metadata)
+	sr.PUT("/metadata/:id", h.replaceMetadata) // slice must be locked for metadata changes
+	sr.PATCH("/metadata/:id", h.patchMetadata)
+	sr.DELETE("/metadata/:id/:key", h.deleteMetadata)
 	sr.PUT("/lock/:id", h.lock)
 	sr.PUT("/unlock/:id", h.unlock)
+	sr.PUT("/encrypt/:id", h.encrypt) // also rotates the data key of an encrypted slice
+	sr.PUT("/decrypt/:id", h.decrypt)
+	sr.PUT("/rewrap", h.rewrap) // after a master key rotation
+	sr.PUT("/members/:id", h.members)
 }
 
 // Custom errors
 var (
 	// ErrInvalidSliceUUID indicates a malformed uuid
 	ErrInvalidSliceUUID = echo.NewHTTPError(http.StatusBadRequest, "invalid slice uuid")
-	ErrInvalidSliceType = echo.NewHTTPError(http.StatusBadRequest, "invalid slice-type")
 )
 
 // Slice create request
@@ -52,6 +58,8 @@ type createReq struct {
 	Name      string            `json:"name" validate:"required,min=3"`
 	SliceType string            `json:"slice_type" validate:"required"`
 	AllowSync bool              `json:"allow_sync"`
+	Encrypted bool              `json:"encrypted"`
+	Composite bool              `json:"composite"` // members are assigned separately
 	Metadata  sandpiper.MetaMap `json:"metadata"`
 }
 
@@ -74,16 +82,14 @@ func (h *HTTP) create(c echo.Context) error {
 		Name:         r.Name,
 		SliceType:    r.SliceType,
 		AllowSync:    r.AllowSync,
+		Encrypted:    r.Encrypted,
+		Composite:    r.Composite,
 		SyncStatus:   sandpiper.SyncStatusNone,
 		ContentHash:  "",
 		ContentCount: 0,
 		Metadata:     r.Metadata,
 	}
 
-	if !rec.Validate() {
-		return ErrInvalidSliceType
-	}
-
 	result, err := h.svc.Create(c, rec)
 	if err != nil {
 		return err
@@ -159,6 +165,47 @@ func (h *HTTP) metadata(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+func (h *HTTP) replaceMetadata(c echo.Context) error {
+	return h.setMetadata(c, true)
+}
+
+func (h *HTTP) patchMetadata(c echo.Context) error {
+	return h.setMetadata(c, false)
+}
+
+// setMetadata changes slice metadata from a json object of key/value pairs
+func (h *HTTP) setMetadata(c echo.Context, replace bool) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	meta := make(sandpiper.MetaMap)
+	if err := c.Bind(&meta); err != nil {
+		return err
+	}
+
+	result, err := h.svc.SetMetadata(c, id, meta, replace)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) deleteMetadata(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	if err := h.svc.DeleteMetadata(c, id, c.Param("key")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
 // Slice update request
 type updateReq struct {
 	ID           uuid.UUID `json:"-"`
@@ -235,3 +282,59 @@ func (h *HTTP) unlock(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func (h *HTTP) encrypt(c echo.Context) error {
+	return h.rekey(c, true)
+}
+
+func (h *HTTP) decrypt(c echo.Context) error {
+	return h.rekey(c, false)
+}
+
+func (h *HTTP) rekey(c echo.Context, encrypt bool) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	result, err := h.svc.Rekey(c, id, encrypt)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) rewrap(c echo.Context) error {
+	count, err := h.svc.Rewrap(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{"keys": count})
+}
+
+// Composite slice members request
+type membersReq struct {
+	Members []uuid.UUID `json:"members"`
+}
+
+// members replaces the member slices of a composite slice
+func (h *HTTP) members(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSliceUUID
+	}
+
+	req := new(membersReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	result, err := h.svc.ReplaceMembers(c, id, req.Members)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,112 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package slicetype
+
+// slice-type service logger
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slicetype"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// ServiceLogger creates new logger wrapping the slice-type service
+func ServiceLogger(svc slicetype.Service, logger sandpiper.Logger) *LogService {
+	return &LogService{
+		Service: svc,
+		logger:  logger,
+	}
+}
+
+// LogService represents slice-type logging service
+type LogService struct {
+	slicetype.Service
+	logger sandpiper.Logger
+}
+
+const source = "slicetype"
+
+// Create logging
+func (ls *LogService) Create(c echo.Context, req sandpiper.SliceType) (resp *sandpiper.SliceType, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Create slice-type request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Create(c, req)
+}
+
+// List logging
+func (ls *LogService) List(c echo.Context, req *params.Params) (resp []sandpiper.SliceType, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "List slice-type request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.List(c, req)
+}
+
+// View logging
+func (ls *LogService) View(c echo.Context, req string) (resp *sandpiper.SliceType, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "View slice-type request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.View(c, req)
+}
+
+// Update logging
+func (ls *LogService) Update(c echo.Context, req *sandpiper.SliceType) (resp *sandpiper.SliceType, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Update slice-type request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Update(c, req)
+}
+
+// Delete logging
+func (ls *LogService) Delete(c echo.Context, req string) (err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Delete slice-type request", err,
+			map[string]interface{}{
+				"req":  req,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Delete(c, req)
+}
@@ -0,0 +1,131 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package pgsql
+
+// slice-type service database access
+
+import (
+	"net/http"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// Custom errors
+var (
+	ErrAlreadyExists     = echo.NewHTTPError(http.StatusInternalServerError, "Slice type already exists.")
+	ErrSliceTypeNotFound = echo.NewHTTPError(http.StatusNotFound, "Slice type not found.")
+	ErrSliceTypeInUse    = echo.NewHTTPError(http.StatusConflict, "Slice type is used by a slice.")
+)
+
+// SliceType represents the client for slice_types table
+type SliceType struct{}
+
+// NewSliceType returns a new slice-type database instance
+func NewSliceType() *SliceType {
+	return &SliceType{}
+}
+
+// Create creates a new slice type in database (assumes allowed to do this)
+func (s *SliceType) Create(db orm.DB, st sandpiper.SliceType) (*sandpiper.SliceType, error) {
+	found, err := db.Model((*sandpiper.SliceType)(nil)).Where("name = ?", st.Name).Exists()
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return nil, ErrAlreadyExists
+	}
+	if err := db.Insert(&st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// View returns a single slice type by name
+func (s *SliceType) View(db orm.DB, name string) (*sandpiper.SliceType, error) {
+	return Lookup(db, name)
+}
+
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("slice_type", "name", "description", "encoding", "validator",
+		"granulator", "created_at"),
+	Sort: params.Columns("slice_type", "name", "encoding", "created_at"),
+}
+
+// List returns list of all slice types
+func (s *SliceType) List(db orm.DB, p *params.Params) (types []sandpiper.SliceType, err error) {
+	q := db.Model(&types).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "name"); err != nil {
+		return nil, err
+	}
+	p.Paging.Count, err = q.SelectAndCount()
+	if err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// Update replaces the description, encoding and plugins of a slice type
+func (s *SliceType) Update(db orm.DB, st *sandpiper.SliceType) error {
+	res, err := db.Model(st).
+		Column("description", "encoding", "validator", "granulator", "updated_at").
+		WherePK().Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrSliceTypeNotFound
+	}
+	return nil
+}
+
+// Delete removes a slice type by name (if not used by a slice)
+func (s *SliceType) Delete(db orm.DB, st *sandpiper.SliceType) error {
+	used, err := db.Model((*sandpiper.Slice)(nil)).Where("slice_type = ?", st.Name).Exists()
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrSliceTypeInUse
+	}
+	return db.Delete(st)
+}
+
+// Lookup returns a slice type by name (used by other services for its plugins)
+func Lookup(db orm.DB, name string) (*sandpiper.SliceType, error) {
+	st := &sandpiper.SliceType{Name: name}
+	if err := db.Model(st).WherePK().Select(); err != nil {
+		if err == pg.ErrNoRows {
+			return nil, ErrSliceTypeNotFound
+		}
+		return nil, err
+	}
+	return st, nil
+}
+
+// All returns every slice type (used to validate slices)
+func All(db orm.DB) ([]sandpiper.SliceType, error) {
+	var types []sandpiper.SliceType
+	if err := db.Model(&types).Order("name").Select(); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// Ensure adds a slice type (without plugins) if it is not registered, e.g. for a slice synced
+// from a primary server
+func Ensure(db orm.DB, name, description string) error {
+	st := &sandpiper.SliceType{Name: name, Description: description, Encoding: sandpiper.DefaultEncoding}
+	_, err := db.Model(st).OnConflict("DO NOTHING").Insert()
+	return err
+}
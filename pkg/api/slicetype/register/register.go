@@ -0,0 +1,25 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package slicetype
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slicetype"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+
+	tl "github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/logging"
+	tt "github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/transport"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+)
+
+// Register ties the slice-type service to its logger and transport mechanisms
+func Register(db *database.DB, log sandpiper.Logger, v1 *echo.Group, cfg *config.Validation) {
+	svc := slicetype.Initialize(db, rbac.New(db.Settings.ServerRole), cfg, log)
+	ls := tl.ServiceLogger(svc, log)
+	tt.NewHTTP(ls, v1)
+}
@@ -0,0 +1,66 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package slicetype
+
+// slice-type service
+
+import (
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/validate"
+)
+
+// Service represents slice-type application interface
+type Service interface {
+	Create(echo.Context, sandpiper.SliceType) (*sandpiper.SliceType, error)
+	List(echo.Context, *params.Params) ([]sandpiper.SliceType, error)
+	View(echo.Context, string) (*sandpiper.SliceType, error)
+	Update(echo.Context, *sandpiper.SliceType) (*sandpiper.SliceType, error)
+	Delete(echo.Context, string) error
+}
+
+// New creates new slice-type application service
+func New(db *database.DB, sdb Repository, rbac RBAC, val Validator) *SliceType {
+	return &SliceType{db: db.DB, sdb: sdb, rbac: rbac, val: val}
+}
+
+// Initialize initializes slice-type application service with defaults
+func Initialize(db *database.DB, rbac RBAC, cfg *config.Validation, log sandpiper.Logger) *SliceType {
+	return New(db, pgsql.NewSliceType(), rbac, validate.New(cfg, log))
+}
+
+// SliceType represents slice-type application service
+type SliceType struct {
+	db   *pg.DB
+	sdb  Repository
+	rbac RBAC
+	val  Validator
+}
+
+// Validator represents the registered validation plugins
+type Validator interface {
+	Has(string) bool
+}
+
+// Repository represents available resource actions using a repository-abstraction-pattern interface.
+type Repository interface {
+	Create(orm.DB, sandpiper.SliceType) (*sandpiper.SliceType, error)
+	View(orm.DB, string) (*sandpiper.SliceType, error)
+	List(orm.DB, *params.Params) ([]sandpiper.SliceType, error)
+	Update(orm.DB, *sandpiper.SliceType) error
+	Delete(orm.DB, *sandpiper.SliceType) error
+}
+
+// RBAC represents role-based-access-control interface
+type RBAC interface {
+	EnforceRole(echo.Context, sandpiper.AccessLevel) error
+}
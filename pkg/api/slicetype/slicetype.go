@@ -0,0 +1,92 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package slicetype contains services for the slice-types resource (the registry of slice
+// types with their default encoding and validation and granulation plugins).
+package slicetype
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/granulate"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+)
+
+// Custom errors
+var (
+	ErrInvalidEncoding   = echo.NewHTTPError(http.StatusBadRequest, "Invalid encoding (must be raw, b64, z64, a85 or z85).")
+	ErrUnknownValidator  = echo.NewHTTPError(http.StatusBadRequest, "Unknown validator plugin.")
+	ErrUnknownGranulator = echo.NewHTTPError(http.StatusBadRequest, "Unknown granulator plugin.")
+)
+
+// Create adds a new slice type if administrator
+func (s *SliceType) Create(c echo.Context, req sandpiper.SliceType) (*sandpiper.SliceType, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if err := s.checkPlugins(&req); err != nil {
+		return nil, err
+	}
+	return s.sdb.Create(s.db, req)
+}
+
+// List returns list of slice types
+func (s *SliceType) List(c echo.Context, p *params.Params) ([]sandpiper.SliceType, error) {
+	return s.sdb.List(s.db, p)
+}
+
+// View returns a single slice type
+func (s *SliceType) View(c echo.Context, name string) (*sandpiper.SliceType, error) {
+	return s.sdb.View(s.db, name)
+}
+
+// Update replaces the description, encoding and plugins of a slice type (the name cannot change)
+func (s *SliceType) Update(c echo.Context, req *sandpiper.SliceType) (*sandpiper.SliceType, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if err := s.checkPlugins(req); err != nil {
+		return nil, err
+	}
+	if err := s.sdb.Update(s.db, req); err != nil {
+		return nil, err
+	}
+	return s.sdb.View(s.db, req.Name)
+}
+
+// Delete removes a slice type if administrator (and not used by any slices)
+func (s *SliceType) Delete(c echo.Context, name string) error {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return err
+	}
+	st, err := s.sdb.View(s.db, name)
+	if err != nil {
+		return err
+	}
+	return s.sdb.Delete(s.db, st)
+}
+
+// checkPlugins makes sure the encoding and plugins are known (using the default encoding if
+// not provided)
+func (s *SliceType) checkPlugins(st *sandpiper.SliceType) error {
+	if st.Encoding == "" {
+		st.Encoding = sandpiper.DefaultEncoding
+	}
+	if !payload.ValidEncoding(st.Encoding) {
+		return ErrInvalidEncoding
+	}
+	if st.Validator != "" && !s.val.Has(st.Validator) {
+		return ErrUnknownValidator
+	}
+	if st.Granulator != "" {
+		if _, ok := granulate.Lookup(st.Granulator); !ok {
+			return ErrUnknownGranulator
+		}
+	}
+	return nil
+}
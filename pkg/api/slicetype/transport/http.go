@@ -0,0 +1,135 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package transport
+
+// slice-type service routing functions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/slicetype"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+// HTTP represents slice-type http service
+type HTTP struct {
+	svc slicetype.Service
+}
+
+// NewHTTP creates new slice-type http service
+func NewHTTP(svc slicetype.Service, er *echo.Group) {
+	h := HTTP{svc}
+	tr := er.Group("/slicetypes")
+	tr.POST("", h.create)
+	tr.GET("", h.list)
+	tr.GET("/:name", h.view)
+	tr.PUT("/:name", h.update)
+	tr.DELETE("/:name", h.delete)
+}
+
+// Custom errors
+var (
+	// ErrInvalidName indicates a slice-type name that can't be used in a url
+	ErrInvalidName = echo.NewHTTPError(http.StatusBadRequest, "slice-type name can only contain letters, digits, \"-\" and \"_\"")
+)
+
+// Slice-type create request
+type createReq struct {
+	Name        string `json:"name" validate:"required,min=2"`
+	Description string `json:"description"`
+	Encoding    string `json:"encoding"` // default "z64"
+	Validator   string `json:"validator"`
+	Granulator  string `json:"granulator"`
+}
+
+func (h *HTTP) create(c echo.Context) error {
+	r := new(createReq)
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	name, err := cleanName(r.Name)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.Create(c, sandpiper.SliceType{
+		Name:        name,
+		Description: r.Description,
+		Encoding:    r.Encoding,
+		Validator:   r.Validator,
+		Granulator:  r.Granulator,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+func (h *HTTP) list(c echo.Context) error {
+	p, err := params.Parse(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.svc.List(c, p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sandpiper.SliceTypesPaginated{SliceTypes: result, Paging: p.Paging})
+}
+
+func (h *HTTP) view(c echo.Context) error {
+	result, err := h.svc.View(c, c.Param("name"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Slice-type update request (replaces all fields)
+type updateReq struct {
+	Description string `json:"description"`
+	Encoding    string `json:"encoding"`
+	Validator   string `json:"validator"`
+	Granulator  string `json:"granulator"`
+}
+
+func (h *HTTP) update(c echo.Context) error {
+	req := new(updateReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	result, err := h.svc.Update(c, &sandpiper.SliceType{
+		Name:        c.Param("name"),
+		Description: req.Description,
+		Encoding:    req.Encoding,
+		Validator:   req.Validator,
+		Granulator:  req.Granulator,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) delete(c echo.Context) error {
+	if err := h.svc.Delete(c, c.Param("name")); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// cleanName returns a lower-case slice-type name (checking for invalid characters)
+func cleanName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", ErrInvalidName
+		}
+	}
+	return name, nil
+}
@@ -114,3 +114,54 @@ func (ls *LogService) Update(c echo.Context, req *subscription.Update) (resp *sa
 	}(time.Now())
 	return ls.Service.Update(c, req)
 }
+
+// Pin logging
+func (ls *LogService) Pin(c echo.Context, req uuid.UUID, releaseID *uuid.UUID) (resp *sandpiper.Subscription, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Pin subscription request", err,
+			map[string]interface{}{
+				"req":        req,
+				"release_id": releaseID,
+				"resp":       resp,
+				"took":       time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Pin(c, req, releaseID)
+}
+
+// SetTerms logging
+func (ls *LogService) SetTerms(c echo.Context, req uuid.UUID, start, end time.Time) (resp *sandpiper.Subscription, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "SetTerms subscription request", err,
+			map[string]interface{}{
+				"req":        req,
+				"start_date": start,
+				"end_date":   end,
+				"resp":       resp,
+				"took":       time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.SetTerms(c, req, start, end)
+}
+
+// Expiring logging
+func (ls *LogService) Expiring(c echo.Context, req int) (resp []sandpiper.Subscription, err error) {
+	defer func(begin time.Time) {
+		ls.logger.Log(
+			c,
+			source, "Expiring subscription request", err,
+			map[string]interface{}{
+				"req":  req,
+				"resp": resp,
+				"took": time.Since(begin),
+			},
+		)
+	}(time.Now())
+	return ls.Service.Expiring(c, req)
+}
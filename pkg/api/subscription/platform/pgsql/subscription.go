@@ -7,8 +7,10 @@ package pgsql
 // subscription service database access
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
@@ -31,6 +33,7 @@ func NewSubscription() *Subscription {
 var (
 	ErrAlreadyExists      = echo.NewHTTPError(http.StatusInternalServerError, "Subscription name already exists.")
 	ErrMissingQueryParams = echo.NewHTTPError(http.StatusInternalServerError, "no query params supplied")
+	ErrReleaseNotFound    = echo.NewHTTPError(http.StatusNotFound, "Release not found for the subscribed slice.")
 )
 
 // Create creates a new Subscription in database (assumes allowed to do this)
@@ -68,6 +71,14 @@ func (s *Subscription) View(db orm.DB, sub sandpiper.Subscription) (*sandpiper.S
 	return &sub, nil
 }
 
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("subscription", "sub_id", "slice_id", "company_id", "name", "active",
+		"release_id", "rule_id", "start_date", "end_date", "created_at"),
+	Sort: params.Columns("subscription", "name", "active", "start_date", "end_date",
+		"created_at"),
+}
+
 // List returns list of all subscriptions
 func (s *Subscription) List(db orm.DB, sc *sandpiper.Scope, p *params.Params) (subs []sandpiper.Subscription, err error) {
 
@@ -75,8 +86,12 @@ func (s *Subscription) List(db orm.DB, sc *sandpiper.Scope, p *params.Params) (s
 	if sc != nil {
 		q.Where(sc.Condition, sc.ID)
 	}
-	p.AddFilter(q)
-	p.AddSort(q, "name")
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "name"); err != nil {
+		return nil, err
+	}
 	p.Paging.Count, err = q.SelectAndCount()
 	if err != nil {
 		return nil, err
@@ -91,6 +106,72 @@ func (s *Subscription) Update(db orm.DB, sub *sandpiper.Subscription) error {
 	return err
 }
 
+// Pin sets (or clears) the slice release a subscription is pinned to
+func (s *Subscription) Pin(db orm.DB, sub *sandpiper.Subscription) error {
+	if sub.ReleaseID != nil {
+		found, err := db.Model((*sandpiper.SliceRelease)(nil)).
+			Where("id = ?", *sub.ReleaseID).
+			Where("slice_id = ?", sub.SliceID).Exists()
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrReleaseNotFound
+		}
+	}
+	_, err := db.Model(sub).Column("release_id", "updated_at").WherePK().Update()
+	return err
+}
+
+// SetTerms updates the start and end dates (and active flag) of a subscription
+func (s *Subscription) SetTerms(db orm.DB, sub *sandpiper.Subscription) error {
+	_, err := db.Model(sub).Column("start_date", "end_date", "active", "updated_at").WherePK().Update()
+	return err
+}
+
+// Expiring returns active subscriptions ending before a time (soonest first), limited by scope
+func (s *Subscription) Expiring(db orm.DB, sc *sandpiper.Scope, until time.Time) (subs []sandpiper.Subscription, err error) {
+	q := queryAll(db, &subs).
+		Where("subscription.active").
+		Where("subscription.end_date IS NOT NULL").
+		Where("subscription.end_date < ?", until).
+		Order("subscription.end_date")
+	if sc != nil {
+		q.Where(sc.Condition, sc.ID)
+	}
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Expire deactivates active subscriptions past their end date, logging each in the activity table
+func (s *Subscription) Expire(db orm.DB) ([]sandpiper.Subscription, error) {
+	var subs []sandpiper.Subscription
+
+	_, err := db.Model(&subs).
+		Set("active = false").
+		Set("updated_at = now()").
+		Where("active").
+		Where("end_date <= now()").
+		Returning("*").Update()
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		activity := sandpiper.Activity{
+			CompanyID: sub.CompanyID,
+			SubID:     sub.SubID,
+			Success:   true,
+			Message:   "deactivated by expiry (ended " + sub.EndDate.Format(time.RFC3339) + ")",
+		}
+		if err := db.Insert(&activity); err != nil {
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
 // Delete removes the subscription by primary key
 func (s *Subscription) Delete(db orm.DB, sub *sandpiper.Subscription) error {
 	return db.Delete(sub)
@@ -115,6 +196,20 @@ func checkDuplicate(db orm.DB, name string) error {
 	}
 }
 
+// UniqueName returns a subscription name that isn't taken yet, adding the start of the new
+// subscription's id if needed (for subscriptions named automatically, e.g. "Company Slice")
+func UniqueName(db orm.DB, subID uuid.UUID, name string) (string, error) {
+	found, err := db.Model((*sandpiper.Subscription)(nil)).
+		Where("lower(name) = ?", strings.ToLower(name)).Exists()
+	if err != nil {
+		return "", err
+	}
+	if found {
+		name = fmt.Sprintf("%s (%s)", name, subID.String()[:8])
+	}
+	return name, nil
+}
+
 // queryAll returns a query for all subscriptions (including company and slice)
 func queryAll(db orm.DB, subs *[]sandpiper.Subscription) *orm.Query {
 	return db.Model(subs).Relation("Company").Relation("Slice")
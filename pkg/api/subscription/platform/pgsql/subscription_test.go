@@ -6,6 +6,12 @@ package pgsql_test
 
 import (
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/subscription/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/mock/mockdb"
 )
 
 func TestCreate(t *testing.T) {
@@ -27,3 +33,16 @@ func TestList(t *testing.T) {
 func TestDelete(t *testing.T) {
 
 }
+
+func TestUniqueName(t *testing.T) {
+	subID := uuid.MustParse("1234abcd-0000-0000-0000-000000000000")
+
+	name, err := pgsql.UniqueName(&mockdb.DB{}, subID, "Acme Brakes")
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Brakes", name)
+
+	taken := (&mockdb.DB{}).On(`FROM "subscriptions"`, mockdb.Row{})
+	name, err = pgsql.UniqueName(taken, subID, "Acme Brakes")
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Brakes (1234abcd)", name)
+}
@@ -5,6 +5,8 @@
 package subscription
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/api/subscription"
@@ -17,8 +19,9 @@ import (
 )
 
 // Register ties the subscription service to its logger and transport mechanisms
-func Register(db *database.DB, sec subscription.Securer, log sandpiper.Logger, v1 *echo.Group) {
+func Register(db *database.DB, sec subscription.Securer, log sandpiper.Logger, v1 *echo.Group, expiry time.Duration) {
 	svc := subscription.Initialize(db, rbac.New(db.Settings.ServerRole), sec)
+	svc.StartExpiry(expiry, log) // deactivate expired subscriptions in the background
 	ls := sl.ServiceLogger(svc, log)
 	st.NewHTTP(ls, v1)
 }
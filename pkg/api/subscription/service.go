@@ -7,6 +7,8 @@ package subscription
 // subscription service
 
 import (
+	"time"
+
 	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
 	"github.com/google/uuid"
@@ -25,6 +27,9 @@ type Service interface {
 	View(echo.Context, sandpiper.Subscription) (*sandpiper.Subscription, error)
 	Delete(echo.Context, uuid.UUID) error
 	Update(echo.Context, *Update) (*sandpiper.Subscription, error)
+	Pin(echo.Context, uuid.UUID, *uuid.UUID) (*sandpiper.Subscription, error)
+	SetTerms(echo.Context, uuid.UUID, time.Time, time.Time) (*sandpiper.Subscription, error)
+	Expiring(echo.Context, int) ([]sandpiper.Subscription, error)
 }
 
 // New creates new company application service
@@ -56,6 +61,10 @@ type Repository interface {
 	View(orm.DB, sandpiper.Subscription) (*sandpiper.Subscription, error)
 	List(orm.DB, *sandpiper.Scope, *params.Params) ([]sandpiper.Subscription, error)
 	Update(orm.DB, *sandpiper.Subscription) error
+	Pin(orm.DB, *sandpiper.Subscription) error
+	SetTerms(orm.DB, *sandpiper.Subscription) error
+	Expiring(orm.DB, *sandpiper.Scope, time.Time) ([]sandpiper.Subscription, error)
+	Expire(orm.DB) ([]sandpiper.Subscription, error)
 	Delete(orm.DB, *sandpiper.Subscription) error
 }
 
@@ -6,19 +6,48 @@
 package subscription
 
 import (
+	"net/http"
+	"time"
+
+	"github.com/go-pg/pg/v9"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
+// Custom errors
+var (
+	ErrInvalidTerms = echo.NewHTTPError(http.StatusBadRequest, "Subscription end date must be after its start date.")
+)
+
+// resource identifies subscriptions in the audit trail
+const resource = "subscription"
+
+// defaultExpiringDays is used for the "expiring soon" list if days are not provided
+const defaultExpiringDays = 30
+
 // Create adds a new subscription if administrator
 func (s *Subscription) Create(c echo.Context, req sandpiper.Subscription) (*sandpiper.Subscription, error) {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
-	return s.sdb.Create(s.db, req)
+	if err := validateTerms(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+	var sub *sandpiper.Subscription
+	err := s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if sub, err = s.sdb.Create(tx, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, sub.SubID, nil, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
 }
 
 // List returns list of subscriptions that you can view
@@ -53,7 +82,12 @@ func (s *Subscription) Delete(c echo.Context, id uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	return s.sdb.Delete(s.db, subscription)
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Delete(tx, subscription); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, id, subscription, nil)
+	})
 }
 
 // Update contains subscription field request used for updating
@@ -79,9 +113,129 @@ func (s *Subscription) Update(c echo.Context, r *Update) (*sandpiper.Subscriptio
 		Description: r.Description,
 		Active:      r.Active,
 	}
-	err := s.sdb.Update(s.db, &sub)
+	before, err := s.sdb.View(s.db, sub)
 	if err != nil {
 		return nil, err
 	}
-	return s.sdb.View(s.db, sub)
+	var after *sandpiper.Subscription
+	err = s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if err := s.sdb.Update(tx, &sub); err != nil {
+			return err
+		}
+		if after, err = s.sdb.View(tx, sub); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, r.SubID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Pin sets the slice release a subscription receives (or tracks the latest content if the
+// release is nil). Allows subscribers to adopt new releases on their own schedule.
+func (s *Subscription) Pin(c echo.Context, subID uuid.UUID, releaseID *uuid.UUID) (*sandpiper.Subscription, error) {
+	sub, err := s.sdb.View(s.db, sandpiper.Subscription{SubID: subID})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rbac.EnforceCompany(c, sub.CompanyID); err != nil {
+		return nil, err
+	}
+	before := *sub
+	sub.ReleaseID = releaseID
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Pin(tx, sub); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, subID, &before, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// SetTerms changes the start and end dates of a subscription (if administrator). A subscription
+// deactivated by expiry is reactivated if renewed (i.e. the new end date has not passed).
+func (s *Subscription) SetTerms(c echo.Context, subID uuid.UUID, start, end time.Time) (*sandpiper.Subscription, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
+		return nil, err
+	}
+	if err := validateTerms(start, end); err != nil {
+		return nil, err
+	}
+	sub, err := s.sdb.View(s.db, sandpiper.Subscription{SubID: subID})
+	if err != nil {
+		return nil, err
+	}
+	before := *sub
+	now := time.Now()
+	renewed := !sub.Active && sub.Expired(now)
+	sub.StartDate = start
+	sub.EndDate = end
+	if renewed && !sub.Expired(now) {
+		sub.Active = true
+	}
+	err = s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.SetTerms(tx, sub); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, subID, &before, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Expiring returns active subscriptions ending within a number of days (if company admin)
+func (s *Subscription) Expiring(c echo.Context, days int) ([]sandpiper.Subscription, error) {
+	if err := s.rbac.EnforceRole(c, sandpiper.CompanyAdminRole); err != nil {
+		return nil, err
+	}
+	q, err := s.rbac.EnforceScope(c)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = defaultExpiringDays
+	}
+	return s.sdb.Expiring(s.db, q, time.Now().AddDate(0, 0, days))
+}
+
+// Expire deactivates subscriptions past their end date (logged in the activity table)
+func (s *Subscription) Expire() ([]sandpiper.Subscription, error) {
+	return s.sdb.Expire(s.db)
+}
+
+// StartExpiry runs Expire in the background now and then at each interval (logging any
+// subscriptions expired and any errors)
+func (s *Subscription) StartExpiry(interval time.Duration, logger sandpiper.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			begin := time.Now()
+			subs, err := s.Expire()
+			if err != nil || len(subs) > 0 {
+				logger.Log(nil, "subscription", "Expire subscriptions", err,
+					map[string]interface{}{
+						"expired": len(subs),
+						"took":    time.Since(begin),
+					},
+				)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// validateTerms makes sure an end date (if any) is after a start date (if any)
+func validateTerms(start, end time.Time) error {
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return ErrInvalidTerms
+	}
+	return nil
 }
@@ -8,6 +8,8 @@ package transport
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -31,10 +33,14 @@ func NewHTTP(svc subscription.Service, er *echo.Group) {
 
 	er.POST("/subs", h.create)
 	er.GET("/subs", h.list)
+	er.GET("/subs/expiring", h.expiring) // ?days=<n> (active subscriptions ending soon)
 	er.GET("/subs/:id", h.view)
 	er.GET("/subs/name/:name", h.viewByName)
 	er.PUT("/subs/:id", h.update) // not a PATCH, body must include *all* fields
 	er.DELETE("/subs/:id", h.delete)
+	er.PUT("/subs/pin/:id", h.pin) // receive a slice release instead of the latest content
+	er.PUT("/subs/unpin/:id", h.unpin)
+	er.PUT("/subs/terms/:id", h.terms) // change start and end dates (renew)
 }
 
 // Custom errors
@@ -43,6 +49,7 @@ var (
 	ErrInvalidSliceUUID        = echo.NewHTTPError(http.StatusBadRequest, "malformed slice uuid")
 	ErrInvalidSubscriptionUUID = echo.NewHTTPError(http.StatusBadRequest, "invalid subscription uuid")
 	ErrMissingSubscriptionName = echo.NewHTTPError(http.StatusBadRequest, "missing required subscription name")
+	ErrInvalidDays             = echo.NewHTTPError(http.StatusBadRequest, "invalid number of days")
 )
 
 // Subscription create request
@@ -53,6 +60,8 @@ type createReq struct {
 	Name        string    `json:"name" validate:"required,min=3"`
 	Description string    `json:"description"`
 	Active      bool      `json:"active"`
+	StartDate   time.Time `json:"start_date"` // optional
+	EndDate     time.Time `json:"end_date"`   // optional
 }
 
 func (r createReq) id() uuid.UUID {
@@ -75,6 +84,8 @@ func (h *HTTP) create(c echo.Context) error {
 		Name:        r.Name,
 		Description: r.Description,
 		Active:      r.Active,
+		StartDate:   r.StartDate,
+		EndDate:     r.EndDate,
 	})
 	if err != nil {
 		return err
@@ -198,3 +209,74 @@ func (h *HTTP) delete(c echo.Context) error {
 	}
 	return c.NoContent(http.StatusOK)
 }
+
+// Subscription pin request
+type pinReq struct {
+	ReleaseID uuid.UUID `json:"release_id" validate:"required"`
+}
+
+func (h *HTTP) pin(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSubscriptionUUID
+	}
+	req := new(pinReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	result, err := h.svc.Pin(c, id, &req.ReleaseID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) unpin(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSubscriptionUUID
+	}
+	result, err := h.svc.Pin(c, id, nil)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *HTTP) expiring(c echo.Context) error {
+	var days int
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return ErrInvalidDays
+		}
+		days = n
+	}
+	result, err := h.svc.Expiring(c, days)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Subscription terms request (zero dates are open-ended)
+type termsReq struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+func (h *HTTP) terms(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return ErrInvalidSubscriptionUUID
+	}
+	req := new(termsReq)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	result, err := h.svc.SetTerms(c, id, req.StartDate, req.EndDate)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,36 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sync
+
+// sync metrics (by primary company id)
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/metrics"
+)
+
+var (
+	syncRuns = metrics.Default.Counter("sandpiper_sync_runs_total",
+		"Sync runs started by primary.", "primary")
+	syncFailures = metrics.Default.Counter("sandpiper_sync_failures_total",
+		"Sync runs that failed by primary.", "primary")
+	syncDuration = metrics.Default.Histogram("sandpiper_sync_duration_seconds",
+		"Sync run duration by primary.", []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600}, "primary")
+	syncGrains = metrics.Default.Counter("sandpiper_sync_grains_transferred_total",
+		"Grains received from a primary.", "primary")
+)
+
+// observeSync records a finished sync run
+func observeSync(primaryID uuid.UUID, took time.Duration, err error) {
+	primary := primaryID.String()
+	syncRuns.With(primary).Inc()
+	syncDuration.With(primary).Observe(took.Seconds())
+	if err != nil {
+		syncFailures.With(primary).Inc()
+	}
+}
@@ -18,8 +18,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	relsvc "github.com/sandpiper-framework/sandpiper/pkg/api/release/platform/pgsql"
+	searchsvc "github.com/sandpiper-framework/sandpiper/pkg/api/search/platform/pgsql"
 	slicesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slice/platform/pgsql"
+	slicetypesvc "github.com/sandpiper-framework/sandpiper/pkg/api/slicetype/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 )
 
 // Custom errors
@@ -29,15 +33,17 @@ var (
 )
 
 // Sync represents the client for sync table
-type Sync struct{}
+type Sync struct {
+	kr *secure.Keyring // for slices encrypted at rest
+}
 
 // NewSync returns a new sync instance
-func NewSync() *Sync {
-	return &Sync{}
+func NewSync(kr *secure.Keyring) *Sync {
+	return &Sync{kr: kr}
 }
 
 // LogActivity adds a sync log entry to the activity table
-func (s *Sync) LogActivity(db orm.DB, companyID, subID uuid.UUID, msg string, d time.Duration, err error) error {
+func (s *Sync) LogActivity(db orm.DB, companyID, subID, sessionID uuid.UUID, msg string, d time.Duration, err error) error {
 	var errMsg string
 	if err != nil {
 		errMsg = fmt.Sprintf("%v", err)
@@ -45,6 +51,7 @@ func (s *Sync) LogActivity(db orm.DB, companyID, subID uuid.UUID, msg string, d
 	activity := sandpiper.Activity{
 		CompanyID: companyID,
 		SubID:     subID,
+		SessionID: sessionID,
 		Success:   err == nil,
 		Message:   msg,
 		Error:     errMsg,
@@ -68,7 +75,8 @@ func (s *Sync) Primary(db orm.DB, id uuid.UUID) (*sandpiper.Company, error) {
 }
 
 // Subscriptions returns list of all local subscriptions (with slice but not metadata) for a company
-// without pagination
+// without pagination. Composite slices include their member slices and slices of subscriptions
+// pinned to a release have the release's content information.
 func (s *Sync) Subscriptions(db orm.DB, companyID uuid.UUID) ([]sandpiper.Subscription, error) {
 	var subs []sandpiper.Subscription
 
@@ -76,6 +84,25 @@ func (s *Sync) Subscriptions(db orm.DB, companyID uuid.UUID) ([]sandpiper.Subscr
 	if err != nil {
 		return nil, err
 	}
+	for _, sub := range subs {
+		if sub.Slice == nil {
+			continue
+		}
+		if sub.Slice.Composite {
+			if sub.Slice.Members, err = slicesvc.NewSlice().Members(db, sub.Slice.ID); err != nil {
+				return nil, err
+			}
+		}
+		if sub.ReleaseID != nil {
+			rel := &sandpiper.SliceRelease{ID: *sub.ReleaseID}
+			if err := db.Model(rel).WherePK().Select(); err != nil {
+				return nil, err
+			}
+			sub.Slice.ContentHash = rel.ContentHash
+			sub.Slice.ContentCount = rel.ContentCount
+			sub.Slice.ContentDate = rel.ContentDate
+		}
+	}
 	return subs, nil
 }
 
@@ -112,8 +139,19 @@ func (s *Sync) DeactivateSubscription(db orm.DB, subID uuid.UUID) error {
 	return nil
 }
 
-// AddSlice creates a new Slice in the database (without metadata)
+// UpdateTerms changes the start and end dates (and active flag) of a local subscription
+func (s *Sync) UpdateTerms(db orm.DB, sub *sandpiper.Subscription) error {
+	_, err := db.Model(sub).Column("start_date", "end_date", "active").WherePK().Update()
+	return err
+}
+
+// AddSlice creates a new Slice in the database (without metadata). A slice encrypted at rest on
+// the primary is also encrypted here (requiring our own master key). A slice type we don't have
+// is registered (without plugins).
 func (s *Sync) AddSlice(db orm.DB, slice *sandpiper.Slice) error {
+	if err := slicetypesvc.Ensure(db, slice.SliceType, "added by sync"); err != nil {
+		return err
+	}
 	// make sure name is unique on our side too
 	if err := checkDupSliceName(db, slice.Name); err != nil {
 		if err != ErrAlreadyExists {
@@ -127,6 +165,25 @@ func (s *Sync) AddSlice(db orm.DB, slice *sandpiper.Slice) error {
 	return nil
 }
 
+// Slice returns a local slice by id (or nil if not found)
+func (s *Sync) Slice(db orm.DB, sliceID uuid.UUID) (*sandpiper.Slice, error) {
+	slice := &sandpiper.Slice{ID: sliceID}
+	err := db.Model(slice).WherePK().Select()
+	switch err {
+	case pg.ErrNoRows:
+		return nil, nil
+	case nil:
+		return slice, nil
+	default:
+		return nil, err
+	}
+}
+
+// ReplaceMembers sets the member slices of a local composite slice
+func (s *Sync) ReplaceMembers(db orm.DB, compositeID uuid.UUID, memberIDs []uuid.UUID) error {
+	return slicesvc.NewSlice().ReplaceMembers(db, compositeID, memberIDs)
+}
+
 // RefreshSlice updates the content fields and checks against source slice to make
 // sure the sync agrees
 func (s *Sync) RefreshSlice(db orm.DB, slice *sandpiper.Slice) error {
@@ -148,6 +205,9 @@ func (s *Sync) RefreshSlice(db orm.DB, slice *sandpiper.Slice) error {
 	if err != nil {
 		return err
 	}
+	if err := slicesvc.CountBytes(db, slice.ID); err != nil {
+		return err
+	}
 
 	// see if the sync worked (hash values match, etc.)
 	if slice.ContentHash != hash || slice.ContentCount != count {
@@ -167,12 +227,13 @@ func (s *Sync) SliceMetadata(db orm.DB, sliceID uuid.UUID) (sandpiper.MetaArray,
 	return meta, nil
 }
 
-// SliceAccess checks if a slice is included in a company's subscriptions.
+// SliceAccess checks if a slice is included in a company's subscriptions (directly or as the
+// member of a subscribed composite slice).
 func (s *Sync) SliceAccess(db orm.DB, companyID uuid.UUID, sliceID uuid.UUID) error {
-	sub := new(sandpiper.Subscription)
-	err := db.Model(sub).Column("sub_id").
-		Where("slice_id = ?", sliceID).
-		Where("company_id = ?", companyID).
+	slice := new(sandpiper.Slice)
+	err := db.Model(slice).Column("id").
+		Where("id = ?", sliceID).
+		Where("id IN (?)", slicesvc.AccessibleSlices(db, companyID)).
 		Select()
 	switch err {
 	case pg.ErrNoRows:
@@ -223,24 +284,56 @@ func (s *Sync) ReplaceSliceMetadata(db orm.DB, sliceID uuid.UUID, source sandpip
 func (s *Sync) Grains(db orm.DB, sliceID uuid.UUID, briefFlag bool) ([]sandpiper.Grain, error) {
 	var grains []sandpiper.Grain
 
-	// columns to select
-	cols := "grain.id"
-	if !briefFlag {
-		cols = cols + ", slice_id, grain_key, source, encoding, grain.created_at, length(payload) AS payload_len"
+	// brief is only the ids (otherwise all columns)
+	q := db.Model(&grains).Where("slice_id = ?", sliceID)
+	if briefFlag {
+		q = q.Column("grain.id")
 	}
-	err := db.Model(&grains).Where("slice_id = ?", sliceID).Select()
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+
+	// decrypt payloads encrypted at rest
+	c := slicesvc.NewCrypter(db, s.kr)
+	for i := range grains {
+		if err := c.Open(&grains[i]); err != nil {
+			return nil, err
+		}
+	}
+	return grains, nil
+}
+
+// PinnedRelease returns the release a company's subscription to a slice is pinned to (or nil)
+func (s *Sync) PinnedRelease(db orm.DB, companyID, sliceID uuid.UUID) (*sandpiper.SliceRelease, error) {
+	return relsvc.PinnedRelease(db, companyID, sliceID)
+}
+
+// ReleaseGrains returns a list of grains for a slice release (with brief or all fields)
+func (s *Sync) ReleaseGrains(db orm.DB, rel *sandpiper.SliceRelease, briefFlag bool) ([]sandpiper.Grain, error) {
+	grains, err := relsvc.Grains(db, rel, briefFlag)
 	if err != nil {
 		return nil, err
 	}
+
+	// decrypt payloads encrypted at rest
+	c := slicesvc.NewCrypter(db, s.kr)
+	for i := range grains {
+		if err := c.Open(&grains[i]); err != nil {
+			return nil, err
+		}
+	}
 	return grains, nil
 }
 
-// AddGrain adds a grain locally
+// AddGrain adds a grain locally (encrypted at rest if our slice is encrypted)
 func (s *Sync) AddGrain(db orm.DB, grain *sandpiper.Grain) error {
+	if err := slicesvc.NewCrypter(db, s.kr).Seal(grain); err != nil {
+		return err
+	}
 	if err := db.Insert(grain); err != nil {
 		return err
 	}
-	return nil
+	return searchsvc.Index(db, grain)
 }
 
 // DeleteGrains removes all provided grain ids
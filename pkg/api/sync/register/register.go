@@ -10,16 +10,17 @@ import (
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/rbac"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 
 	sl "github.com/sandpiper-framework/sandpiper/pkg/api/sync/logging"
 	st "github.com/sandpiper-framework/sandpiper/pkg/api/sync/transport"
 )
 
 // Register ties the sync service to its logger and transport mechanisms
-func Register(db *database.DB, sec sync.Securer, log sandpiper.Logger, v1 *echo.Group) {
+func Register(db *database.DB, sec sync.Securer, log sandpiper.Logger, v1 *echo.Group, kr *secure.Keyring) {
 	rba := rbac.New(db.Settings.ServerRole)
 	rba.ServerID = db.Settings.ServerID
-	svc := sync.Initialize(db, rba, sec)
+	svc := sync.Initialize(db, rba, sec, kr)
 	ls := sl.ServiceLogger(svc, log)
 	st.NewHTTP(ls, v1)
 }
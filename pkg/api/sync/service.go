@@ -16,6 +16,7 @@ import (
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 )
 
 // Service represents sync application interface
@@ -32,8 +33,8 @@ func New(db *database.DB, sdb Repository, rbac RBAC, sec Securer) *Sync {
 }
 
 // Initialize initializes Sync application service with defaults
-func Initialize(db *database.DB, rbac RBAC, sec Securer) *Sync {
-	return New(db, pgsql.NewSync(), rbac, sec)
+func Initialize(db *database.DB, rbac RBAC, sec Securer, kr *secure.Keyring) *Sync {
+	return New(db, pgsql.NewSync(kr), rbac, sec)
 }
 
 // Sync represents sync application service
@@ -54,16 +55,21 @@ type Securer interface {
 // Repository represents available resource actions using a repository-abstraction-pattern interface.
 type Repository interface {
 	Primary(orm.DB, uuid.UUID) (*sandpiper.Company, error)
-	LogActivity(orm.DB, uuid.UUID, uuid.UUID, string, time.Duration, error) error
+	LogActivity(orm.DB, uuid.UUID, uuid.UUID, uuid.UUID, string, time.Duration, error) error
 	Subscriptions(orm.DB, uuid.UUID) ([]sandpiper.Subscription, error)
 	AddSubscription(orm.DB, sandpiper.Subscription) error
 	DeactivateSubscription(orm.DB, uuid.UUID) error
+	UpdateTerms(orm.DB, *sandpiper.Subscription) error
 	SliceAccess(orm.DB, uuid.UUID, uuid.UUID) error
 	AddSlice(orm.DB, *sandpiper.Slice) error
+	Slice(orm.DB, uuid.UUID) (*sandpiper.Slice, error)
+	ReplaceMembers(orm.DB, uuid.UUID, []uuid.UUID) error
 	RefreshSlice(orm.DB, *sandpiper.Slice) error
 	SliceMetadata(orm.DB, uuid.UUID) (sandpiper.MetaArray, error)
 	ReplaceSliceMetadata(orm.DB, uuid.UUID, sandpiper.MetaArray) error
 	Grains(orm.DB, uuid.UUID, bool) ([]sandpiper.Grain, error)
+	PinnedRelease(orm.DB, uuid.UUID, uuid.UUID) (*sandpiper.SliceRelease, error)
+	ReleaseGrains(orm.DB, *sandpiper.SliceRelease, bool) ([]sandpiper.Grain, error)
 	AddGrain(orm.DB, *sandpiper.Grain) error
 	DeleteGrains(orm.DB, []uuid.UUID) error
 	BeginSyncUpdate(orm.DB, uuid.UUID) error
@@ -18,6 +18,10 @@ package sync
   that subscription, but changes are not propagated to the Primary. So, all of this means that
   the Primary controls what can be synced, but the Secondary can turn the sync off.
 
+  Subscription start and end dates are enforced by the Primary. A subscription that has not
+  started is not sent, and an expired one is sent as inactive. Changed dates are copied to the
+  Secondary, which reactivates a subscription that expired locally if it was renewed.
+
   The sync process will also observe the "active" company flag (on both sides) and the "allow_sync"
   slice is being updated flag (on the Primary).
 */
@@ -32,6 +36,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/middleware/session"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 )
 
@@ -39,14 +44,20 @@ import (
 
 type subsArray []sandpiper.Subscription
 
-// Start sends a sync request to a primary sandpiper server from our secondary server
+// Start sends a sync request to a primary sandpiper server from our secondary server. A new
+// session id is sent with every request to the primary and saved with the activity (and log
+// entries) on both servers, so the whole sync can be found with one query.
 func (s *Sync) Start(c echo.Context, primaryID uuid.UUID) (err error) {
 	var p *sandpiper.Company
 
+	sessionID := uuid.New()
+	session.Set(c, sessionID)
+
 	// log activity even if early exit
 	defer func(begin time.Time) {
+		observeSync(primaryID, time.Since(begin), err)
 		msg := fmt.Sprintf("Syncing \"%s\" (%s)", p.Name, p.SyncAddr)
-		if e := s.sdb.LogActivity(s.db, primaryID, uuid.Nil, msg, time.Since(begin), err); e != nil {
+		if e := s.sdb.LogActivity(s.db, primaryID, uuid.Nil, sessionID, msg, time.Since(begin), err); e != nil {
 			err = fmt.Errorf("%w; LogActivity Error: %v", err, e)
 		}
 	}(time.Now())
@@ -65,7 +76,7 @@ func (s *Sync) Start(c echo.Context, primaryID uuid.UUID) (err error) {
 		return err
 	}
 	// connect to the primary server using their api-key (saving token)
-	s.api, err = s.connect(p.SyncAddr, p.SyncAPIKey)
+	s.api, err = s.connect(p.SyncAddr, p.SyncAPIKey, sessionID)
 	if err != nil {
 		return err
 	}
@@ -86,7 +97,7 @@ func (s *Sync) Start(c echo.Context, primaryID uuid.UUID) (err error) {
 	return nil
 }
 
-func (s *Sync) connect(addr, key string) (*client.Client, error) {
+func (s *Sync) connect(addr, key string, sessionID uuid.UUID) (*client.Client, error) {
 	server, err := url.ParseRequestURI(addr)
 	if err != nil {
 		return nil, err
@@ -94,7 +105,7 @@ func (s *Sync) connect(addr, key string) (*client.Client, error) {
 	if key == "" {
 		return nil, errors.New("api-key is missing")
 	}
-	api, err := client.SyncLogin(server, key, false) // nowhere to get a debug flag
+	api, err := client.SyncLogin(server, key, sessionID, false) // nowhere to get a debug flag
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +130,8 @@ func (s *Sync) syncSubscriptions(locals, prims subsArray, primaryID uuid.UUID) (
 			// add this subscription (and its slice) locally
 			local = remote.SemiDeepCopy()
 			local.CompanyID = primaryID  // change to our frame of reference for the add
+			local.ReleaseID = nil        // releases are only on the primary
+			local.RuleID = nil           // as are subscription rules
 			local.Slice.ContentHash = "" // force a re-sync
 			if err := s.sdb.AddSlice(s.db, local.Slice); err != nil {
 				return err
@@ -134,10 +147,24 @@ func (s *Sync) syncSubscriptions(locals, prims subsArray, primaryID uuid.UUID) (
 				}
 				local.Active = false
 			}
+			// keep the subscription terms in step (reactivating if renewed after expiring here)
+			if !local.StartDate.Equal(remote.StartDate) || !local.EndDate.Equal(remote.EndDate) {
+				renewed := remote.Active && !local.Active && local.Expired(time.Now())
+				local.StartDate, local.EndDate = remote.StartDate, remote.EndDate
+				local.Active = local.Active || renewed
+				if err := s.sdb.UpdateTerms(s.db, &local); err != nil {
+					return err
+				}
+			}
 		}
 		if local.Active {
-			// sync the grains for a slice
-			if err := s.syncSlice(primaryID, local.SubID, local.Slice, remote.Slice); err != nil {
+			// sync the grains for a slice (or for each member of a composite slice)
+			if remote.Slice.Composite {
+				err = s.syncComposite(primaryID, local.SubID, local.Slice, remote.Slice)
+			} else {
+				err = s.syncSlice(primaryID, local.SubID, local.Slice, remote.Slice)
+			}
+			if err != nil {
 				return err
 			}
 		}
@@ -154,7 +181,7 @@ func (s *Sync) syncSlice(primaryID, subID uuid.UUID, localSlice, remoteSlice *sa
 		duration := time.Since(begin)
 		msg := "Slice \"" + localSlice.Name + "\""
 		if err != nil {
-			if e := s.sdb.LogActivity(s.db, primaryID, subID, msg, duration, err); e != nil {
+			if e := s.sdb.LogActivity(s.db, primaryID, subID, s.api.Session(), msg, duration, err); e != nil {
 				err = fmt.Errorf("%w; LogActivity Error: %v", err, e)
 			}
 		}
@@ -210,6 +237,7 @@ func (s *Sync) syncSlice(primaryID, subID uuid.UUID, localSlice, remoteSlice *sa
 		if err := s.sdb.AddGrain(s.db, grain); err != nil {
 			return err
 		}
+		syncGrains.With(primaryID.String()).Inc()
 	}
 
 	// replace local slice metadata with remote's
@@ -226,6 +254,38 @@ func (s *Sync) syncSlice(primaryID, subID uuid.UUID, localSlice, remoteSlice *sa
 	return err
 }
 
+// syncComposite syncs each member slice of a composite slice (adding new members locally) and
+// then the composite itself, which has no grains of its own (but whose hash covers its members)
+func (s *Sync) syncComposite(primaryID, subID uuid.UUID, localSlice, remoteSlice *sandpiper.Slice) error {
+	ids := make([]uuid.UUID, 0, len(remoteSlice.Members))
+	for i := range remoteSlice.Members {
+		remote := &remoteSlice.Members[i]
+		local, err := s.sdb.Slice(s.db, remote.ID)
+		if err != nil {
+			return err
+		}
+		if local == nil {
+			// add the member slice locally (metadata is added by its sync)
+			m := *remote
+			m.ContentHash = "" // force a re-sync
+			local = &m
+			if err := s.sdb.AddSlice(s.db, local); err != nil {
+				return err
+			}
+		}
+		if err := s.syncSlice(primaryID, subID, local, remote); err != nil {
+			return err
+		}
+		ids = append(ids, remote.ID)
+	}
+
+	// membership must match before the composite's hash can be verified
+	if err := s.sdb.ReplaceMembers(s.db, remoteSlice.ID, ids); err != nil {
+		return err
+	}
+	return s.syncSlice(primaryID, subID, localSlice, remoteSlice)
+}
+
 // slicesMatch checks if a slice has changed and so needs to be updated
 func slicesMatch(remoteSlice, localSlice *sandpiper.Slice) bool {
 	// we can safely use the previous hash saved for comparison because we performed a deep
@@ -267,10 +327,28 @@ func (s *Sync) Subscriptions(c echo.Context) ([]sandpiper.Subscription, error) {
 		return nil, err
 	}
 	companyID := s.rbac.CurrentUser(c).CompanyID
-	return s.sdb.Subscriptions(s.db, companyID)
+	subs, err := s.sdb.Subscriptions(s.db, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// enforce subscription terms (leaving out those not started and reporting expired as inactive)
+	now := time.Now()
+	terms := subs[:0]
+	for _, sub := range subs {
+		if !sub.Started(now) {
+			continue
+		}
+		if sub.Expired(now) {
+			sub.Active = false
+		}
+		terms = append(terms, sub)
+	}
+	return terms, nil
 }
 
-// Grains returns all grains for a slice without pagination (with option to limit fields returned)
+// Grains returns all grains for a slice without pagination (with option to limit fields returned).
+// A subscription pinned to a release receives the release's grains instead of the latest content.
 // Too bad we need to check company access to this slice again, but this is a public endpoint
 // with no state beyond the user token. At least it uses a unique key for the check.
 // Websockets should allow a more efficient approach.
@@ -285,6 +363,13 @@ func (s *Sync) Grains(c echo.Context, sliceID uuid.UUID, briefFlag bool) ([]sand
 	if err := s.sdb.SliceAccess(s.db, companyID, sliceID); err != nil {
 		return nil, err
 	}
+	rel, err := s.sdb.PinnedRelease(s.db, companyID, sliceID)
+	if err != nil {
+		return nil, err
+	}
+	if rel != nil {
+		return s.sdb.ReleaseGrains(s.db, rel, briefFlag)
+	}
 	return s.sdb.Grains(s.db, sliceID, briefFlag)
 }
 
@@ -31,6 +31,7 @@ var (
 	ErrAlreadyExists     = echo.NewHTTPError(http.StatusInternalServerError, "Tag name already exists.")
 	ErrTagDoesNotExist   = echo.NewHTTPError(http.StatusInternalServerError, "Cannot assign a tag ID that does not exist.")
 	ErrSliceDoesNotExist = echo.NewHTTPError(http.StatusInternalServerError, "Cannot assign to a slice ID that does not exist.")
+	ErrTagInUse          = echo.NewHTTPError(http.StatusConflict, "Tag is used by a subscription rule.")
 )
 
 // Create creates a new Tag in database (assumes allowed to do this)
@@ -56,12 +57,22 @@ func (s *Tag) View(db orm.DB, id int) (*sandpiper.Tag, error) {
 	return tag, nil
 }
 
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("tag", "id", "name", "description", "created_at"),
+	Sort:   params.Columns("tag", "id", "name", "created_at"),
+}
+
 // List returns list of all tags
 func (s *Tag) List(db orm.DB, p *params.Params) (tags []sandpiper.Tag, err error) {
 
 	q := db.Model(&tags).Limit(p.Paging.PageSize).Offset(p.Paging.Offset())
-	p.AddFilter(q)
-	p.AddSort(q, "name")
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "name"); err != nil {
+		return nil, err
+	}
 	p.Paging.Count, err = q.SelectAndCount()
 	if err != nil {
 		return nil, err
@@ -76,8 +87,15 @@ func (s *Tag) Update(db orm.DB, sub *sandpiper.Tag) error {
 	return err
 }
 
-// Delete removes the tag by primary key
+// Delete removes the tag by primary key (if not used by a subscription rule)
 func (s *Tag) Delete(db orm.DB, sub *sandpiper.Tag) error {
+	used, err := db.Model((*sandpiper.SubscriptionRule)(nil)).Where("tag_id = ?", sub.ID).Exists()
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrTagInUse
+	}
 	return db.Delete(sub)
 }
 
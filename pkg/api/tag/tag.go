@@ -6,13 +6,24 @@
 package tag
 
 import (
+	"strconv"
+
+	"github.com/go-pg/pg/v9"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
+	rulesvc "github.com/sandpiper-framework/sandpiper/pkg/api/rule/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
+// audit trail resources (a slice tag is the link between a tag and a slice)
+const (
+	resource    = "tag"
+	resourceUse = "slice_tag"
+)
+
 // See "Toxi" solution in this article:
 // http://howto.philippkeller.com/2005/04/24/Tags-Database-schemas/
 
@@ -21,7 +32,17 @@ func (s *Tag) Create(c echo.Context, req sandpiper.Tag) (*sandpiper.Tag, error)
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return nil, err
 	}
-	return s.sdb.Create(s.db, req)
+	var tag *sandpiper.Tag
+	err := s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if tag, err = s.sdb.Create(tx, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, tag.ID, nil, tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
 }
 
 // List returns list of tags that you can view
@@ -46,7 +67,12 @@ func (s *Tag) Delete(c echo.Context, id int) error {
 	if err != nil {
 		return err
 	}
-	return s.sdb.Delete(s.db, tag)
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Delete(tx, tag); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, id, tag, nil)
+	})
 }
 
 // Update contains tag field request used for updating
@@ -62,30 +88,82 @@ func (s *Tag) Update(c echo.Context, r *Update) (*sandpiper.Tag, error) {
 		return nil, err
 	}
 
+	before, err := s.sdb.View(s.db, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	tag := sandpiper.Tag{
 		ID:          r.ID,
 		Name:        r.Name,
 		Description: r.Description,
 	}
 
-	if err := s.sdb.Update(s.db, &tag); err != nil {
+	var after *sandpiper.Tag
+	err = s.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if err := s.sdb.Update(tx, &tag); err != nil {
+			return err
+		}
+		if after, err = s.sdb.View(tx, r.ID); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, r.ID, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
-	return s.sdb.View(s.db, r.ID)
+	return after, nil
 }
 
-// Assign adds a tag assignment to a slice
+// Assign adds a tag assignment to a slice (creating any subscriptions wanted by subscription rules)
 func (s *Tag) Assign(c echo.Context, tagID int, sliceID uuid.UUID) error {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return err
 	}
-	return s.sdb.Assign(s.db, tagID, sliceID)
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Assign(tx, tagID, sliceID); err != nil {
+			return err
+		}
+		effect, err := rulesvc.Apply(tx, uuid.Nil, sliceID)
+		if err != nil {
+			return err
+		}
+		if err := rulesvc.Audit(tx, c, effect); err != nil {
+			return err
+		}
+		link := sliceTag{TagID: tagID, SliceID: sliceID}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resourceUse, link.id(), nil, link)
+	})
 }
 
-// Remove deletes a tag assignment from a slice
+// Remove deletes a tag assignment from a slice (retiring any subscriptions no longer wanted by
+// subscription rules)
 func (s *Tag) Remove(c echo.Context, tagID int, sliceID uuid.UUID) error {
 	if err := s.rbac.EnforceRole(c, sandpiper.AdminRole); err != nil {
 		return err
 	}
-	return s.sdb.Remove(s.db, tagID, sliceID)
+	return s.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := s.sdb.Remove(tx, tagID, sliceID); err != nil {
+			return err
+		}
+		effect, err := rulesvc.Apply(tx, uuid.Nil, sliceID)
+		if err != nil {
+			return err
+		}
+		if err := rulesvc.Audit(tx, c, effect); err != nil {
+			return err
+		}
+		link := sliceTag{TagID: tagID, SliceID: sliceID}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resourceUse, link.id(), link, nil)
+	})
+}
+
+// sliceTag is the audit snapshot of a tag assigned to a slice
+type sliceTag struct {
+	TagID   int       `json:"tag_id"`
+	SliceID uuid.UUID `json:"slice_id"`
+}
+
+func (t sliceTag) id() string {
+	return strconv.Itoa(t.TagID) + "/" + t.SliceID.String()
 }
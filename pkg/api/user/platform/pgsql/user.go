@@ -63,6 +63,14 @@ func (u *User) Update(db orm.DB, user *sandpiper.User) error {
 	return err
 }
 
+// listFields are the fields a list can be filtered and sorted by
+var listFields = params.Fields{
+	Filter: params.Columns("user", "id", "first_name", "last_name", "username", "email", "phone",
+		"active", "last_login", "role", "company_id", "created_at", "updated_at"),
+	Sort: params.Columns("user", "id", "first_name", "last_name", "username", "email", "active",
+		"last_login", "role", "created_at"),
+}
+
 // List returns all users retrievable by the current user, depending on role
 func (u *User) List(db orm.DB, p *params.Params, sc *sandpiper.Scope) (users []sandpiper.User, err error) {
 
@@ -70,8 +78,12 @@ func (u *User) List(db orm.DB, p *params.Params, sc *sandpiper.Scope) (users []s
 	if sc != nil {
 		q.Where(sc.Condition, sc.ID)
 	}
-	p.AddFilter(q)
-	p.AddSort(q, "username")
+	if err := p.AddFilter(q, listFields); err != nil {
+		return nil, err
+	}
+	if err := p.AddSort(q, listFields, "username"); err != nil {
+		return nil, err
+	}
 	p.Paging.Count, err = q.SelectAndCount()
 	if err != nil {
 		return nil, err
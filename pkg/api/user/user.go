@@ -6,20 +6,38 @@
 package user
 
 import (
+	"github.com/go-pg/pg/v9"
 	"github.com/labstack/echo/v4"
 
+	auditsvc "github.com/sandpiper-framework/sandpiper/pkg/api/audit/platform/pgsql"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 )
 
+// resource identifies users in the audit trail
+const resource = "user"
+
+// apiKeyResource identifies sync api keys (by company id) in the audit trail
+const apiKeyResource = "sync_api_key"
+
 // Create creates a new user account
 func (u *User) Create(c echo.Context, req sandpiper.User) (*sandpiper.User, error) {
 	if err := u.rbac.AccountCreate(c, req.Role, req.CompanyID); err != nil {
 		return nil, err
 	}
 	req.Password = u.sec.Hash(req.Password)
-	return u.sdb.Create(u.db, req)
+	var user *sandpiper.User
+	err := u.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if user, err = u.sdb.Create(tx, req); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, resource, user.ID, nil, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 // List returns list of users
@@ -48,7 +66,12 @@ func (u *User) Delete(c echo.Context, id int) error {
 	if err := u.rbac.IsLowerRole(c, user.Role); err != nil {
 		return err
 	}
-	return u.sdb.Delete(u.db, user)
+	return u.db.RunInTransaction(func(tx *pg.Tx) error {
+		if err := u.sdb.Delete(tx, user); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditDelete, resource, user.ID, user, nil)
+	})
 }
 
 // Update contains user's information used for updating
@@ -66,18 +89,31 @@ func (u *User) Update(c echo.Context, r *Update) (*sandpiper.User, error) {
 	if err := u.rbac.EnforceUser(c, r.ID); err != nil {
 		return nil, err
 	}
-
-	if err := u.sdb.Update(u.db, &sandpiper.User{
-		ID:        r.ID,
-		FirstName: r.FirstName,
-		LastName:  r.LastName,
-		Email:     r.Email,
-		Phone:     r.Phone,
-	}); err != nil {
+	before, err := u.sdb.View(u.db, r.ID)
+	if err != nil {
 		return nil, err
 	}
 
-	return u.sdb.View(u.db, r.ID)
+	var after *sandpiper.User
+	err = u.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if err := u.sdb.Update(tx, &sandpiper.User{
+			ID:        r.ID,
+			FirstName: r.FirstName,
+			LastName:  r.LastName,
+			Email:     r.Email,
+			Phone:     r.Phone,
+		}); err != nil {
+			return err
+		}
+		if after, err = u.sdb.View(tx, r.ID); err != nil {
+			return err
+		}
+		return auditsvc.Record(tx, c, sandpiper.AuditUpdate, resource, r.ID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
 }
 
 // CreateAPIKey creates a sync user for a company (if necessary) and generates an apikey
@@ -90,22 +126,29 @@ func (u *User) CreateAPIKey(c echo.Context) (*sandpiper.APIKey, error) {
 	if err := u.rbac.EnforceRole(c, sandpiper.CompanyAdminRole); err != nil {
 		return nil, err
 	}
-	// get the company's sync user (or create one)
-	companyID := u.rbac.CurrentUser(c).CompanyID
-	usr, err := u.sdb.CompanySyncUser(u.db, companyID)
+	// generate a new plain-text password for the sync_user
+	pw, err := u.sec.RandomPassword(26)
 	if err != nil {
 		return nil, err
 	}
 
-	// generate a new plain-text password for the sync_user and update the user record
-	pw, err := u.sec.RandomPassword(26)
+	// get the company's sync user (or create one) and update the user record
+	companyID := u.rbac.CurrentUser(c).CompanyID
+	var usr *sandpiper.User
+	err = u.db.RunInTransaction(func(tx *pg.Tx) (err error) {
+		if usr, err = u.sdb.CompanySyncUser(tx, companyID); err != nil {
+			return err
+		}
+		usr.ChangePassword(u.sec.Hash(pw))
+		if err := u.sdb.UpdateSyncUser(tx, usr); err != nil {
+			return err
+		}
+		// each key replaces the last (the old one stops working)
+		return auditsvc.Record(tx, c, sandpiper.AuditCreate, apiKeyResource, companyID, nil, usr)
+	})
 	if err != nil {
 		return nil, err
 	}
-	usr.ChangePassword(u.sec.Hash(pw))
-	if err := u.sdb.UpdateSyncUser(u.db, usr); err != nil {
-		return nil, err
-	}
 
 	// encrypt these credentials in an api_key
 	creds := &secure.Credentials{
@@ -6,40 +6,76 @@
 package handlers
 
 import (
-	"github.com/labstack/echo/v4"
 	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	reqsvc "github.com/sandpiper-framework/sandpiper/pkg/api/request/platform/pgsql"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 )
 
-// SignupValues defines the signup form fields that can be returned
+// SignupValues defines the signup form fields that can be returned (validated when bound, since
+// the form's own checks are easily bypassed)
 type SignupValues struct {
-	Name     string
-	Email    string
-	Company  string
-	ServerID string
-	Kind     string
+	Name     string `form:"name" validate:"required"`
+	Email    string `form:"email" validate:"required,email"`
+	Company  string `form:"company" validate:"required"`
+	ServerID string `form:"serverid" validate:"required,uuid"`
+	Kind     string `form:"kind" validate:"omitempty,oneof=1 2 3 4"`
+	Message  string `form:"message"`
+}
+
+// signupKinds maps the form's kind options to the saved value
+var signupKinds = map[string]string{
+	"1": "distributor",
+	"2": "retailer",
+	"3": "catalog",
+	"4": "other",
 }
 
-// Signup handler
-func Signup(c echo.Context) error {
+// Signup handler (saves a subscription request for review by an administrator)
+func Signup(db *database.DB) echo.HandlerFunc {
+	return func(c echo.Context) error {
 
-	vars := echo.Map{ // todo: replace this with config data
-		"company": "Better Brakes",
-		"terms":   "http://betterbrakes.com/terms",
-	}
+		vars := echo.Map{ // todo: replace this with config data
+			"company": "Better Brakes",
+			"terms":   "http://betterbrakes.com/terms",
+		}
 
-	// GET
-	if c.Request().Method == http.MethodGet {
-		// render signup page
-		return c.Render(http.StatusOK, "signup.html", vars)
-	}
+		// GET
+		if c.Request().Method == http.MethodGet {
+			// render signup page
+			return c.Render(http.StatusOK, "signup.html", vars)
+		}
 
-	// POST
-	result := new(SignupValues)
-	if err := c.Bind(result); err != nil {
-		return err
+		// POST
+		if db.Settings.ServerRole != sandpiper.PrimaryServer {
+			return echo.ErrForbidden
+		}
+		result := new(SignupValues)
+		if err := c.Bind(result); err != nil {
+			return err
+		}
+		serverID, err := uuid.Parse(result.ServerID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid sandpiper server id")
+		}
+		_, err = reqsvc.NewRequest().Create(db.DB, &sandpiper.SubscriptionRequest{
+			ID:          uuid.New(),
+			CompanyName: result.Company,
+			ContactName: result.Name,
+			Email:       result.Email,
+			ServerID:    serverID,
+			Kind:        signupKinds[result.Kind],
+			Message:     result.Message,
+		})
+		if err != nil {
+			return err
+		}
+		// display an Acknowledgment
+		vars["thankyou"] = true
+		return c.Render(http.StatusOK, "signup.html", vars)
 	}
-	// todo: do something with signupValues (maybe email or save to table)
-	// display an Acknowledgment
-	vars["thankyou"] = true
-	return c.Render(http.StatusOK, "signup.html", vars)
 }
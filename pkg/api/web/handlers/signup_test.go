@@ -0,0 +1,61 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/api/web/handlers"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/server"
+)
+
+func TestSignupValidation(t *testing.T) {
+	valid := url.Values{
+		"name":     {"Jane Doe"},
+		"email":    {"jane@acme.com"},
+		"company":  {"Acme"},
+		"serverid": {"10000000-0000-0000-0000-000000000000"},
+		"kind":     {"1"},
+	}
+	cases := []struct {
+		name  string
+		field string
+		value string
+	}{
+		{name: "Missing name", field: "name", value: ""},
+		{name: "Missing company", field: "company", value: ""},
+		{name: "Missing email", field: "email", value: ""},
+		{name: "Malformed email", field: "email", value: "jane"},
+		{name: "Malformed server id", field: "serverid", value: "not-a-uuid"},
+		{name: "Unknown kind", field: "kind", value: "9"},
+	}
+	// invalid signups are refused before anything is saved (so no database connection is needed)
+	db := &database.DB{Settings: &sandpiper.Setting{ServerRole: sandpiper.PrimaryServer}}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			for k, v := range valid {
+				form[k] = v
+			}
+			form.Set(tt.field, tt.value)
+
+			e := server.New()
+			e.POST("/signup", handlers.Signup(db))
+			req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
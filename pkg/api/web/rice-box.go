@@ -25,7 +25,7 @@ func init() {
 		Filename:    "signup.html",
 		FileModTime: time.Unix(1597853620, 0),
 
-		Content: string("<!DOCTYPE html>\r\n<html lang=\"en\">\r\n  <head>\r\n    <meta charset=\"utf-8\" />\r\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\" />\r\n    <title>Request Sandpiper Access ({{.company}})</title>\r\n    <link rel=\"icon\" type=\"image/png\" href=\"/static/img/favicon-32x32.png\" sizes=\"32x32\" />\r\n    <link rel=\"icon\" type=\"image/png\" href=\"/static/img/favicon-16x16.png\" sizes=\"16x16\" />\r\n    <link rel=\"stylesheet\" href=\"/static/css/style.css\" />\r\n  </head>\r\n  <div class=\"register-page\">\r\n  {{ if .thankyou }}\r\n    <p class=\"reverse\">Your access request was delivered. Thank you!</p>\r\n  {{else}}\r\n    <a href=\"https://sandpiperframework.org\"><img src=\"/static/img/logo.svg\" alt=\"logo\" class=\"logo\"/></a>\r\n    <div class=\"form\">\r\n      <form class=\"login-form\" method=\"POST\">\r\n        <input type=\"text\" name=\"name\" placeholder=\"name\" required />\r\n        <input type=\"text\" name=\"email\" placeholder=\"email\" required pattern=\"[a-z0-9._%+-]+@[a-z0-9.-]+\\.[a-z]{2,}$\" title=\"Invalid email address\" />\r\n        <input type=\"text\" name=\"company\" placeholder=\"company\" required />\r\n        <input type=\"text\" name=\"serverid\" placeholder=\"sandpiper server id\" required pattern=\"[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}\" title=\"Invalid uuid format (8-4-4-4-12)\" />\r\n        <select id=\"kind\" name=\"kind\">\r\n          <option value=\"1\">Distributor</option>\r\n          <option value=\"2\">Retailer</option>\r\n          <option value=\"3\">Electronic Catalog</option>\r\n          <option value=\"4\">Other</option>\r\n        </select>\r\n        <button type=\"submit\">register</button>\r\n        <p class=\"message\"><a href=\"{{.terms}}\">Terms & Conditions</a></p>\r\n      </form>\r\n    </div>\r\n  {{end}}\r\n  </div>\r\n</html>\r\n"),
+		Content: string("<!DOCTYPE html>\r\n<html lang=\"en\">\r\n  <head>\r\n    <meta charset=\"utf-8\" />\r\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\" />\r\n    <title>Request Sandpiper Access ({{.company}})</title>\r\n    <link rel=\"icon\" type=\"image/png\" href=\"/static/img/favicon-32x32.png\" sizes=\"32x32\" />\r\n    <link rel=\"icon\" type=\"image/png\" href=\"/static/img/favicon-16x16.png\" sizes=\"16x16\" />\r\n    <link rel=\"stylesheet\" href=\"/static/css/style.css\" />\r\n  </head>\r\n  <div class=\"register-page\">\r\n  {{ if .thankyou }}\r\n    <p class=\"reverse\">Your access request was delivered. Thank you!</p>\r\n  {{else}}\r\n    <a href=\"https://sandpiperframework.org\"><img src=\"/static/img/logo.svg\" alt=\"logo\" class=\"logo\"/></a>\r\n    <div class=\"form\">\r\n      <form class=\"login-form\" method=\"POST\">\r\n        <input type=\"text\" name=\"name\" placeholder=\"name\" required />\r\n        <input type=\"text\" name=\"email\" placeholder=\"email\" required pattern=\"[a-z0-9._%+-]+@[a-z0-9.-]+\\.[a-z]{2,}$\" title=\"Invalid email address\" />\r\n        <input type=\"text\" name=\"company\" placeholder=\"company\" required />\r\n        <input type=\"text\" name=\"serverid\" placeholder=\"sandpiper server id\" required pattern=\"[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}\" title=\"Invalid uuid format (8-4-4-4-12)\" />\r\n        <select id=\"kind\" name=\"kind\">\r\n          <option value=\"1\">Distributor</option>\r\n          <option value=\"2\">Retailer</option>\r\n          <option value=\"3\">Electronic Catalog</option>\r\n          <option value=\"4\">Other</option>\r\n        </select>\r\n        <textarea name=\"message\" placeholder=\"slices (product lines) wanted\" rows=\"3\"></textarea>\r\n        <button type=\"submit\">register</button>\r\n        <p class=\"message\"><a href=\"{{.terms}}\">Terms & Conditions</a></p>\r\n      </form>\r\n    </div>\r\n  {{end}}\r\n  </div>\r\n</html>\r\n"),
 	}
 
 	// define dirs
@@ -75,7 +75,7 @@ func init() {
 		Filename:    "css/style.css",
 		FileModTime: time.Unix(1597853756, 0),
 
-		Content: string("@import url(https://fonts.googleapis.com/css?family=Roboto:300);\r\n\r\n.register-page {\r\n  width: 360px;\r\n  padding: 8% 0 0;\r\n  margin: auto;\r\n}\r\n.form {\r\n  position: relative;\r\n  z-index: 1;\r\n  background: #FFFFFF;\r\n  max-width: 360px;\r\n  margin: 0 auto 100px;\r\n  padding: 45px 45px 25px 45px;\r\n  text-align: center;\r\n  box-shadow: 0 0 20px 0 rgba(0, 0, 0, 0.2), 0 5px 5px 0 rgba(0, 0, 0, 0.24);\r\n}\r\n.form input, select {\r\n  font-family: \"Roboto\", sans-serif;\r\n  outline: 0;\r\n  background: #f2f2f2;\r\n  width: 100%;\r\n  border: 0;\r\n  margin: 0 0 15px;\r\n  padding: 15px;\r\n  box-sizing: border-box;\r\n  font-size: 14px;\r\n}\r\n.form button {\r\n  font-family: \"Roboto\", sans-serif;\r\n  font-weight: bold;\r\n  font-size: 14px;\r\n  color: #FFFFFF;\r\n  background: rgb(61, 72, 122);\r\n  text-transform: uppercase;\r\n  outline: 0;\r\n  width: 100%;\r\n  border: 0;\r\n  padding: 15px;\r\n  cursor: pointer;\r\n}\r\n.form button:hover,.form button:active,.form button:focus {\r\n  background: #43A047;\r\n}\r\n.form .message {\r\n  margin: 15px 0 0;\r\n  color: #b3b3b3;\r\n  font-size: 12px;\r\n}\r\n.form .message a {\r\n  color: #b3b3b3;\r\n  text-decoration: none;\r\n}\r\n.container {\r\n  position: relative;\r\n  z-index: 1;\r\n  max-width: 300px;\r\n  margin: 0 auto;\r\n}\r\n.container:before, .container:after {\r\n  content: \"\";\r\n  display: block;\r\n  clear: both;\r\n}\r\n.container .info {\r\n  margin: 50px auto;\r\n  text-align: center;\r\n}\r\n.container .info h1 {\r\n  margin: 0 0 15px;\r\n  padding: 0;\r\n  font-size: 36px;\r\n  font-weight: 300;\r\n  color: #1a1a1a;\r\n}\r\n.container .info span {\r\n  color: #4d4d4d;\r\n  font-size: 12px;\r\n}\r\n.container .info span a {\r\n  color: #000000;\r\n  text-decoration: none;\r\n}\r\n.container .info span .fa {\r\n  color: #EF3B3A;\r\n}\r\n.reverse {\r\n  text-align: center;\r\n  font-size: 36px;\r\n  font-weight: 300;\r\n  color: white;\r\n}\r\nbody {\r\n  background: rgb(58, 78, 168); /* fallback for old browsers */\r\n  background: -webkit-linear-gradient(right, #060b22, rgb(58, 78, 168));\r\n  background: -moz-linear-gradient(right, #060b22, rgb(58, 78, 168));\r\n  background: -o-linear-gradient(right, #060b22, rgb(58, 78, 168));\r\n  background: linear-gradient(to left, #060b22, rgb(58, 78, 168));\r\n  font-family: \"Roboto\", sans-serif;\r\n  -webkit-font-smoothing: antialiased;\r\n  -moz-osx-font-smoothing: grayscale;      \r\n}\r\n.logo {\r\n  display: block;\r\n  margin-left: auto;\r\n  margin-right: auto;\r\n  width: 75%;\r\n  margin-bottom: 20px;\r\n}"),
+		Content: string("@import url(https://fonts.googleapis.com/css?family=Roboto:300);\r\n\r\n.register-page {\r\n  width: 360px;\r\n  padding: 8% 0 0;\r\n  margin: auto;\r\n}\r\n.form {\r\n  position: relative;\r\n  z-index: 1;\r\n  background: #FFFFFF;\r\n  max-width: 360px;\r\n  margin: 0 auto 100px;\r\n  padding: 45px 45px 25px 45px;\r\n  text-align: center;\r\n  box-shadow: 0 0 20px 0 rgba(0, 0, 0, 0.2), 0 5px 5px 0 rgba(0, 0, 0, 0.24);\r\n}\r\n.form input, select, textarea {\r\n  font-family: \"Roboto\", sans-serif;\r\n  outline: 0;\r\n  background: #f2f2f2;\r\n  width: 100%;\r\n  border: 0;\r\n  margin: 0 0 15px;\r\n  padding: 15px;\r\n  box-sizing: border-box;\r\n  font-size: 14px;\r\n}\r\n.form button {\r\n  font-family: \"Roboto\", sans-serif;\r\n  font-weight: bold;\r\n  font-size: 14px;\r\n  color: #FFFFFF;\r\n  background: rgb(61, 72, 122);\r\n  text-transform: uppercase;\r\n  outline: 0;\r\n  width: 100%;\r\n  border: 0;\r\n  padding: 15px;\r\n  cursor: pointer;\r\n}\r\n.form button:hover,.form button:active,.form button:focus {\r\n  background: #43A047;\r\n}\r\n.form .message {\r\n  margin: 15px 0 0;\r\n  color: #b3b3b3;\r\n  font-size: 12px;\r\n}\r\n.form .message a {\r\n  color: #b3b3b3;\r\n  text-decoration: none;\r\n}\r\n.container {\r\n  position: relative;\r\n  z-index: 1;\r\n  max-width: 300px;\r\n  margin: 0 auto;\r\n}\r\n.container:before, .container:after {\r\n  content: \"\";\r\n  display: block;\r\n  clear: both;\r\n}\r\n.container .info {\r\n  margin: 50px auto;\r\n  text-align: center;\r\n}\r\n.container .info h1 {\r\n  margin: 0 0 15px;\r\n  padding: 0;\r\n  font-size: 36px;\r\n  font-weight: 300;\r\n  color: #1a1a1a;\r\n}\r\n.container .info span {\r\n  color: #4d4d4d;\r\n  font-size: 12px;\r\n}\r\n.container .info span a {\r\n  color: #000000;\r\n  text-decoration: none;\r\n}\r\n.container .info span .fa {\r\n  color: #EF3B3A;\r\n}\r\n.reverse {\r\n  text-align: center;\r\n  font-size: 36px;\r\n  font-weight: 300;\r\n  color: white;\r\n}\r\nbody {\r\n  background: rgb(58, 78, 168); /* fallback for old browsers */\r\n  background: -webkit-linear-gradient(right, #060b22, rgb(58, 78, 168));\r\n  background: -moz-linear-gradient(right, #060b22, rgb(58, 78, 168));\r\n  background: -o-linear-gradient(right, #060b22, rgb(58, 78, 168));\r\n  background: linear-gradient(to left, #060b22, rgb(58, 78, 168));\r\n  font-family: \"Roboto\", sans-serif;\r\n  -webkit-font-smoothing: antialiased;\r\n  -moz-osx-font-smoothing: grayscale;      \r\n}\r\n.logo {\r\n  display: block;\r\n  margin-left: auto;\r\n  margin-right: auto;\r\n  width: 75%;\r\n  margin-bottom: 20px;\r\n}"),
 	}
 	filea := &embedded.EmbeddedFile{
 		Filename:    "img/favicon-16x16.png",
@@ -15,10 +15,11 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/api/web/handlers"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/database"
 )
 
 // FileServer serves static files and templates from embedded files in `rice-box.go`
-func FileServer(srv *echo.Echo) {
+func FileServer(srv *echo.Echo, db *database.DB) {
 	// set view engine (for templates)
 	viewConfig := goview.DefaultConfig
 	// viewConfig.DisableCache = true // auto reload template file for debug.
@@ -35,8 +36,9 @@ func FileServer(srv *echo.Echo) {
 	srv.POST("/", handlers.Login)
 
 	// signup page
-	srv.GET("/signup", handlers.Signup)
-	srv.POST("/signup", handlers.Signup)
+	signup := handlers.Signup(db)
+	srv.GET("/signup", signup)
+	srv.POST("/signup", signup)
 
 	// download page
 	srv.GET("/download", handlers.Download)
@@ -57,11 +57,14 @@ var Commands = []*args.Command{
 		/* sandpiper add \
 		   --slice "aap-brake-pads"  \ # argument is a slice name
 		   --noprompt                \ # don't prompt before over-writing
+		   --bulk                    \ # file contains newline-delimited json grains
+		   --replace                 \ # with --bulk, remove existing slice grains first
+		   --related "aap-copy"      \ # other item-level slices filled from the same file (repeatable)
 		   acme_brakes_full_2019-12-12.xml # file to add (accessed via c.Args().Get(0))
 		*/
 		Name:      "add",
-		Usage:     "add a file-based grain from a local file",
-		ArgsUsage: "<unzipped-file-to-add>",
+		Usage:     "add a file-based grain from a local file (or many grains with --bulk or a directory)",
+		ArgsUsage: "<unzipped-file-to-add | asset-directory>",
 		Action:    command.Add,
 		Flags: []args.Flag{
 			&args.StringFlag{
@@ -74,6 +77,18 @@ var Commands = []*args.Command{
 				Name:  "noprompt",
 				Usage: "do not prompt before over-writing a grain (default is to prompt)",
 			},
+			&args.BoolFlag{
+				Name:  "bulk",
+				Usage: "import a newline-delimited json file of grains (added to existing slice grains)",
+			},
+			&args.BoolFlag{
+				Name:  "replace",
+				Usage: "with --bulk, remove all existing slice grains before the import (default: false)",
+			},
+			&args.StringSliceFlag{
+				Name:  "related",
+				Usage: "other item-level slice_id or slice_name to fill from the same file (e.g. pies-marketcopy)",
+			},
 		},
 	},
 	{
@@ -102,10 +117,11 @@ var Commands = []*args.Command{
 	},
 	{
 		/* sandpiper list \
-		   --slice "aap-slice"  # slice_id or slice_name
+		   --slice "aap-slice"  \ # slice_id or slice_name
+		   --prefix "bb-"         # optional grain key conditions (--prefix, --match, --from, --to)
 		*/
 		Name:      "list",
-		Usage:     "list slices (if no slice provided) or file-based grains by slice_id or slice_name",
+		Usage:     "list slices (if no slice provided) or grains by slice_id or slice_name",
 		ArgsUsage: " ", // don't show that we accept arguments
 		Action:    command.List,
 		Flags: []args.Flag{
@@ -120,6 +136,77 @@ var Commands = []*args.Command{
 				Usage:    "provide full listings",
 				Required: false,
 			},
+			&args.StringFlag{
+				Name:  "prefix",
+				Usage: "only grain keys starting with this `text`",
+			},
+			&args.StringFlag{
+				Name:  "match",
+				Usage: "only grain keys matching a glob `pattern` (* is any characters, ? is one character)",
+			},
+			&args.StringFlag{
+				Name:  "from",
+				Usage: "only grain keys from this `key` (inclusive)",
+			},
+			&args.StringFlag{
+				Name:  "to",
+				Usage: "only grain keys before this `key` (exclusive)",
+			},
+		},
+	},
+	{
+		/* sandpiper search \
+		   --slice "aap-slice"  \ # an optional slice_id or slice_name
+		   --reindex            \ # rebuild the search index (admin) instead of searching
+		   bb-100 front           # words to find (all must match)
+		*/
+		Name:      "search",
+		Usage:     "find grains by words in their key, source file name or payload",
+		ArgsUsage: "<words-to-find>",
+		Action:    command.Search,
+		Flags: []args.Flag{
+			&args.StringFlag{
+				Name:     "slice",
+				Aliases:  []string{"s"},
+				Usage:    "limit to a slice_id (uuid) or slice_name (case-insensitive)",
+				Required: false,
+			},
+			&args.BoolFlag{
+				Name:  "reindex",
+				Usage: "rebuild the search index from grain payloads (admin only, e.g. after an upgrade)",
+			},
+		},
+	},
+	{
+		/* sandpiper meta \
+		   --slice "aap-slice"              \ # required slice_id or slice_name
+		   --set "aces.version=4.2"         \ # add or change a key (repeatable)
+		   --delete "aces.header.Company"   \ # remove a key (repeatable)
+		   --replace                          # remove all keys not provided with --set
+		*/
+		Name:      "meta",
+		Usage:     "list or change slice metadata (the slice is locked during a change)",
+		ArgsUsage: " ", // no arguments
+		Action:    command.Meta,
+		Flags: []args.Flag{
+			&args.StringFlag{
+				Name:     "slice",
+				Aliases:  []string{"s"},
+				Usage:    "either a slice_id (uuid) or slice_name (case-insensitive)",
+				Required: true,
+			},
+			&args.StringSliceFlag{
+				Name:  "set",
+				Usage: "add or change a metadata `key=value`",
+			},
+			&args.StringSliceFlag{
+				Name:  "delete",
+				Usage: "remove a metadata `key`",
+			},
+			&args.BoolFlag{
+				Name:  "replace",
+				Usage: "remove all existing keys not provided with --set",
+			},
 		},
 	},
 	{
@@ -152,6 +239,50 @@ var Commands = []*args.Command{
 			},
 		},
 	},
+	{
+		/* sandpiper activity report \
+		   --from 2020-01-01     \ # optional first day (default is 30 days before --to)
+		   --to 2020-01-31       \ # optional last day (default is today)
+		   --format csv          \ # csv (default) or json
+		   --section daily       \ # optional csv table (daily, never-synced or last-syncs)
+		   --output report.csv     # optional file (default is stdout)
+		*/
+		Name:  "activity",
+		Usage: "report on sync activity",
+		Subcommands: []*args.Command{
+			{
+				Name:      "report",
+				Usage:     "export sync success rates by company and day, slices never synced and the last sync of each subscription",
+				ArgsUsage: " ", // no arguments
+				Action:    command.ActivityReport,
+				Flags: []args.Flag{
+					&args.StringFlag{
+						Name:  "from",
+						Usage: "first `date` of the daily report (YYYY-MM-DD)",
+					},
+					&args.StringFlag{
+						Name:  "to",
+						Usage: "last `date` of the daily report (YYYY-MM-DD)",
+					},
+					&args.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "output `format` (csv or json)",
+						Value:   "csv",
+					},
+					&args.StringFlag{
+						Name:  "section",
+						Usage: "limit csv output to one `table` (daily, never-synced or last-syncs)",
+					},
+					&args.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "write the report to `FILE` (instead of stdout)",
+					},
+				},
+			},
+		},
+	},
 	{
 		/* sandpiper init
 		 */
@@ -0,0 +1,163 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package command
+
+// sandpiper activity report command
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	args "github.com/urfave/cli/v2"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// report sections (csv output can be limited to one)
+const (
+	sectionDaily       = "daily"
+	sectionNeverSynced = "never-synced"
+	sectionLastSyncs   = "last-syncs"
+)
+
+type reportParams struct {
+	addr     *url.URL // our sandpiper server
+	user     string
+	password string
+	from     string // optional YYYY-MM-DD
+	to       string // optional YYYY-MM-DD
+	format   string // "csv" or "json"
+	section  string // optional (empty means all)
+	output   string // optional file name (empty means stdout)
+	debug    bool
+}
+
+// ActivityReport exports the sync activity summary as csv or json
+func ActivityReport(c *args.Context) error {
+	p, err := getReportParams(c)
+	if err != nil {
+		return err
+	}
+
+	// Login to the api server (saving token)
+	api, err := client.Login(p.addr, p.user, p.password, p.debug)
+	if err != nil {
+		return err
+	}
+
+	rpt, err := api.ActivityReport(p.from, p.to)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if p.output != "" {
+		f, err := os.Create(p.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if p.format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rpt)
+	}
+	return writeReportCSV(w, rpt, p.section)
+}
+
+// writeReportCSV writes each requested section as a table (with a header row), separating
+// sections with an empty line
+func writeReportCSV(w io.Writer, rpt *sandpiper.ActivityReport, section string) error {
+	cw := csv.NewWriter(w)
+	first := true
+	table := func(name string, rows [][]string) {
+		if section != "" && section != name {
+			return
+		}
+		if !first {
+			_ = cw.Write(nil) // blank line between sections
+		}
+		first = false
+		_ = cw.WriteAll(rows)
+	}
+
+	daily := [][]string{{"day", "company_id", "company_name", "syncs", "successes", "success_rate", "avg_duration_seconds"}}
+	for _, d := range rpt.Daily {
+		daily = append(daily, []string{
+			d.Day.Format("2006-01-02"), d.CompanyID.String(), d.CompanyName,
+			strconv.Itoa(d.Syncs), strconv.Itoa(d.Successes),
+			strconv.FormatFloat(d.SuccessRate, 'f', 3, 64),
+			strconv.FormatFloat(d.AvgDuration.Seconds(), 'f', 3, 64),
+		})
+	}
+	table(sectionDaily, daily)
+
+	never := [][]string{{"slice_id", "slice_name", "subscriptions"}}
+	for _, s := range rpt.NeverSynced {
+		never = append(never, []string{s.SliceID.String(), s.SliceName, strconv.Itoa(s.Subscriptions)})
+	}
+	table(sectionNeverSynced, never)
+
+	last := [][]string{{"sub_id", "sub_name", "company_id", "company_name", "slice_id", "slice_name", "active", "last_attempt", "last_success"}}
+	for _, s := range rpt.LastSyncs {
+		last = append(last, []string{
+			s.SubID.String(), s.SubName, s.CompanyID.String(), s.CompanyName, s.SliceID.String(), s.SliceName,
+			strconv.FormatBool(s.Active), formatTime(s.LastAttempt), formatTime(s.LastSuccess),
+		})
+	}
+	table(sectionLastSyncs, last)
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatTime returns an RFC3339 timestamp (or empty if missing)
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func getReportParams(c *args.Context) (*reportParams, error) {
+	// get sandpiper global params from config file and args
+	g, err := GetGlobalParams(c)
+	if err != nil {
+		return nil, err
+	}
+
+	format := c.String("format")
+	if format != "csv" && format != "json" {
+		return nil, fmt.Errorf("invalid format \"%s\" (use csv or json)", format)
+	}
+	section := c.String("section")
+	switch section {
+	case "", sectionDaily, sectionNeverSynced, sectionLastSyncs:
+	default:
+		return nil, fmt.Errorf("invalid section \"%s\" (use %s, %s or %s)", section, sectionDaily, sectionNeverSynced, sectionLastSyncs)
+	}
+
+	return &reportParams{
+		addr:     g.addr,
+		user:     g.user,
+		password: g.password,
+		from:     c.String("from"),
+		to:       c.String("to"),
+		format:   format,
+		section:  section,
+		output:   c.String("output"),
+		debug:    g.debug,
+	}, nil
+}
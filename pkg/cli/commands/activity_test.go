@@ -0,0 +1,66 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+func TestWriteReportCSV(t *testing.T) {
+	companyID := uuid.MustParse("10000000-0000-0000-0000-000000000001")
+	sliceID := uuid.MustParse("20000000-0000-0000-0000-000000000002")
+	subID := uuid.MustParse("30000000-0000-0000-0000-000000000003")
+	synced := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rpt := &sandpiper.ActivityReport{
+		Daily: []sandpiper.ActivityDay{
+			{CompanyID: companyID, CompanyName: "Acme, Inc", Day: synced.Truncate(24 * time.Hour),
+				Syncs: 4, Successes: 3, SuccessRate: 0.75, AvgDuration: 1500 * time.Millisecond},
+		},
+		NeverSynced: []sandpiper.UnsyncedSlice{{SliceID: sliceID, SliceName: "brakes", Subscriptions: 2}},
+		LastSyncs: []sandpiper.LastSync{
+			{SubID: subID, SubName: "acme-brakes", CompanyID: companyID, CompanyName: "Acme, Inc",
+				SliceID: sliceID, SliceName: "brakes", Active: true, LastAttempt: &synced},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		section string
+		want    string
+	}{
+		{
+			name:    "Daily only",
+			section: sectionDaily,
+			want: "day,company_id,company_name,syncs,successes,success_rate,avg_duration_seconds\n" +
+				"2020-01-02,10000000-0000-0000-0000-000000000001,\"Acme, Inc\",4,3,0.750,1.500\n",
+		},
+		{
+			name: "All sections",
+			want: "day,company_id,company_name,syncs,successes,success_rate,avg_duration_seconds\n" +
+				"2020-01-02,10000000-0000-0000-0000-000000000001,\"Acme, Inc\",4,3,0.750,1.500\n" +
+				"\n" +
+				"slice_id,slice_name,subscriptions\n" +
+				"20000000-0000-0000-0000-000000000002,brakes,2\n" +
+				"\n" +
+				"sub_id,sub_name,company_id,company_name,slice_id,slice_name,active,last_attempt,last_success\n" +
+				"30000000-0000-0000-0000-000000000003,acme-brakes,10000000-0000-0000-0000-000000000001,\"Acme, Inc\"," +
+				"20000000-0000-0000-0000-000000000002,brakes,true,2020-01-02T03:04:05Z,\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			assert.Nil(t, writeReportCSV(&b, rpt, test.section))
+			assert.Equal(t, test.want, b.String())
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
 
 	"github.com/google/uuid"
@@ -29,6 +30,9 @@ type addParams struct {
 	sliceID  uuid.UUID
 	fileName string
 	prompt   bool
+	bulk     bool     // file contains newline-delimited json grains
+	replace  bool     // remove existing grains before a bulk import
+	related  []string // other item-level slices filled from the same file
 	debug    bool
 }
 
@@ -52,18 +56,45 @@ func Add(c *args.Context) error {
 		return errors.New("must be a \"primary\" server for `add` command")
 	}
 
-	// make sure we have a slice_id to work with
+	// make sure we have a slice to work with
+	var slice *sandpiper.Slice
 	if p.sliceID == uuid.Nil {
-		// lookup sliceID by name
-		slice, err := api.SliceByName(p.slice)
-		if err != nil {
-			return err
-		}
-		p.sliceID = slice.ID
+		// lookup slice by name
+		slice, err = api.SliceByName(p.slice)
+	} else {
+		slice, err = api.SliceByID(p.sliceID)
+	}
+	if err != nil {
+		return err
 	}
+	p.sliceID = slice.ID
 
-	// encode supplied file for grain's payload
-	data, err := payload.FromFile(p.fileName, L1Encoding)
+	if p.bulk {
+		return addBulk(api, p, slice)
+	}
+
+	// a directory is published as one grain per file
+	if info, err := os.Stat(p.fileName); err == nil && info.IsDir() {
+		return addAssets(api, p, slice)
+	}
+
+	// the slice type decides how the file is added
+	st, err := api.SliceType(slice.SliceType)
+	if err != nil {
+		return err
+	}
+
+	// item-level slices (e.g. "aces-items") are split into grains by the server
+	if st.Granulator != "" {
+		return addGranulated(api, p, slice)
+	}
+
+	// encode supplied file for grain's payload (using the slice type's encoding)
+	encoding := st.Encoding
+	if encoding == "" {
+		encoding = L1Encoding
+	}
+	data, err := payload.FromFile(p.fileName, encoding)
 	if err != nil {
 		return err
 	}
@@ -85,7 +116,7 @@ func Add(c *args.Context) error {
 		SliceID:    &p.sliceID,
 		Key:        sandpiper.L1GrainKey,
 		Source:     filepath.Base(p.fileName),
-		Encoding:   L1Encoding,
+		Encoding:   encoding,
 		PayloadLen: len(data), // not persisted (just for the log)
 		Payload:    data,
 	}
@@ -108,6 +139,66 @@ func Add(c *args.Context) error {
 	return nil
 }
 
+// addBulk imports a newline-delimited json file of grains (one grain per line) in one
+// transaction, optionally replacing any existing grains in the slice (after a prompt).
+func addBulk(api *client.Client, p *addParams, slice *sandpiper.Slice) error {
+	replaceFlag := p.replace
+	if replaceFlag && slice.ContentCount > 0 && p.prompt {
+		fmt.Printf("slice \"%s\" already contains %d grains (which will be removed)\n", slice.Name, slice.ContentCount)
+		if !AllowOverwrite() {
+			return errors.New("grains could not be added without overwrite")
+		}
+	}
+
+	file, err := os.Open(p.fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// the import is done in a transaction that also updates slice content information
+	result, err := api.ImportGrains(p.sliceID, replaceFlag, file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("slice \"%s\": %d grains added\n", slice.Name, result.Added)
+	return nil
+}
+
+// addGranulated sends a document (e.g. an ACES xml file) to be split into item-level grains. Only
+// changed items are added or removed (in a single transaction), so there is no need to prompt.
+func addGranulated(api *client.Client, p *addParams, slice *sandpiper.Slice) error {
+	// other slices to fill from the same document (e.g. PIES marketing copy and price sheets)
+	related := make([]uuid.UUID, 0, len(p.related))
+	for _, name := range p.related {
+		id, err := uuid.Parse(name)
+		if err != nil {
+			s, err := api.SliceByName(name)
+			if err != nil {
+				return err
+			}
+			id = s.ID
+		}
+		related = append(related, id)
+	}
+
+	file, err := os.Open(p.fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	results, err := api.GranulateFile(slice.ID, related, filepath.Base(p.fileName), file)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Printf("%s slice %s: %d items (%d added, %d deleted, %d unchanged)\n",
+			r.SliceType, r.SliceID, r.Items, r.Added, r.Deleted, r.Unchanged)
+	}
+	return nil
+}
+
 func getAddParams(c *args.Context) (*addParams, error) {
 	// check for required file argument
 	if c.NArg() != 1 {
@@ -131,6 +222,9 @@ func getAddParams(c *args.Context) (*addParams, error) {
 		sliceID:  sliceID,
 		fileName: c.Args().Get(0),
 		prompt:   !c.Bool("noprompt"), // avoid double negative
+		bulk:     c.Bool("bulk"),
+		replace:  c.Bool("replace"),
+		related:  c.StringSlice("related"),
 		debug:    g.debug,
 	}, nil
 }
@@ -0,0 +1,246 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package command
+
+// sandpiper add (and pull) for "asset-files" slices (a directory tree with one grain per file)
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/validate"
+)
+
+// AssetSliceType is the only slice type that holds a directory of files
+const AssetSliceType = "asset-files"
+
+// assetFile is a local file to publish (keyed by lowercase relative path)
+type assetFile struct {
+	path string // local file path
+	rel  string // relative path using forward slashes (saved as the grain source)
+}
+
+// assetFiles returns all files in a directory tree by grain key (ignoring hidden files and folders)
+func assetFiles(root string) (map[string]assetFile, error) {
+	files := make(map[string]assetFile)
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != root && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		key := strings.ToLower(rel) // grain keys are always lowercase
+		if other, ok := files[key]; ok {
+			return fmt.Errorf("file names \"%s\" and \"%s\" differ only by case", other.rel, rel)
+		}
+		files[key] = assetFile{path: p, rel: rel}
+		return nil
+	})
+	return files, err
+}
+
+// assetEncoding avoids compressing file types that are already compressed
+func assetEncoding(mimeType string) string {
+	switch {
+	case mimeType == "image/svg+xml": // text-based
+		return L1Encoding
+	case strings.HasPrefix(mimeType, "image/"),
+		strings.HasPrefix(mimeType, "video/"),
+		strings.HasPrefix(mimeType, "audio/"),
+		mimeType == "application/pdf",
+		mimeType == "application/zip",
+		mimeType == "application/gzip":
+		return "b64"
+	}
+	return L1Encoding
+}
+
+// newAssetGrain reads and encodes a file for an asset grain
+func newAssetGrain(slice *sandpiper.Slice, key string, f assetFile) (*sandpiper.Grain, string, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, "", err
+	}
+	mimeType := validate.MimeType(validate.Content{Key: key, Source: f.rel, Data: b})
+	enc := assetEncoding(mimeType)
+	data, err := payload.Encode(bytes.NewReader(b), enc)
+	if err != nil {
+		return nil, "", err
+	}
+	return &sandpiper.Grain{
+		SliceID:    &slice.ID,
+		Key:        key,
+		Source:     f.rel,
+		Encoding:   enc,
+		PayloadLen: len(data),
+		Payload:    data,
+	}, mimeType, nil
+}
+
+// addAssets publishes a directory to an "asset-files" slice. Only new or changed files (by hash)
+// are sent and grains for files no longer in the directory are removed. The slice is locked,
+// refreshed and unlocked once for the whole batch.
+func addAssets(api *client.Client, p *addParams, slice *sandpiper.Slice) error {
+	if slice.SliceType != AssetSliceType {
+		return fmt.Errorf("a directory can only be added to an \"%s\" slice", AssetSliceType)
+	}
+
+	files, err := assetFiles(p.fileName)
+	if err != nil {
+		return err
+	}
+	digests, err := api.GrainDigests(slice.ID)
+	if err != nil {
+		return err
+	}
+
+	// grains for files no longer in the directory
+	current := make(map[string]sandpiper.GrainDigest, len(digests))
+	var removed []sandpiper.GrainDigest
+	for _, d := range digests {
+		current[d.Key] = d
+		if _, ok := files[d.Key]; !ok {
+			removed = append(removed, d)
+		}
+	}
+
+	// process files in a predictable order
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// new or changed files (encoded one at a time when sent, so only keys are kept here)
+	var added, changed []string
+	for _, key := range keys {
+		d, ok := current[key]
+		if !ok {
+			added = append(added, key)
+			continue
+		}
+		grain, _, err := newAssetGrain(slice, key, files[key])
+		if err != nil {
+			return err
+		}
+		if fmt.Sprintf("%x", md5.Sum([]byte(grain.Payload))) != d.Hash {
+			changed = append(changed, key)
+		}
+	}
+
+	fmt.Printf("slice \"%s\": %d files (%d new, %d changed, %d removed)\n",
+		slice.Name, len(files), len(added), len(changed), len(removed))
+	if len(added)+len(changed)+len(removed) == 0 {
+		return nil
+	}
+	if p.prompt && len(changed)+len(removed) > 0 && !AllowOverwrite() {
+		return errors.New("assets could not be added without overwrite")
+	}
+
+	if err := api.LockSlice(slice.ID); err != nil {
+		return err
+	}
+
+	for _, d := range removed {
+		if err := api.DeleteGrain(d.ID); err != nil {
+			return err
+		}
+	}
+
+	send := func(key string) error {
+		grain, mimeType, err := newAssetGrain(slice, key, files[key])
+		if err != nil {
+			return err
+		}
+		if d, ok := current[key]; ok {
+			if err := api.DeleteGrain(d.ID); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("  %s (%s)\n", grain.Source, mimeType)
+		return api.AddGrain(grain)
+	}
+	for _, key := range append(changed, added...) {
+		if err := send(key); err != nil {
+			return err
+		}
+	}
+
+	// finally, update slice content information and allow syncing
+	if err := api.RefreshSlice(slice.ID); err != nil {
+		return err
+	}
+	return api.UnlockSlice(slice.ID)
+}
+
+// pullAssets recreates an "asset-files" slice directory tree (using each grain's source path)
+func (cmd *pullCmd) pullAssets(slice *sandpiper.Slice) error {
+	digests, err := cmd.api.GrainDigests(slice.ID)
+	if err != nil {
+		return err
+	}
+
+	// default output to current directory if none provided
+	basePath := cmd.basePath
+	if basePath == "" {
+		basePath = "."
+	}
+	root := filepath.Join(basePath, slice.Name)
+
+	for _, d := range digests {
+		rel := d.Source
+		if rel == "" {
+			rel = d.Key
+		}
+		// never write outside of the slice folder
+		rel = path.Clean("/" + rel)[1:]
+		if rel == "" {
+			return fmt.Errorf("grain \"%s\" has an invalid source path", d.Key)
+		}
+		fileName := filepath.Join(root, filepath.FromSlash(rel))
+
+		grain, err := cmd.api.Grain(d.ID)
+		if err != nil {
+			return err
+		}
+		data, err := grain.Payload.Decode(grain.Encoding)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Saving: %s ...\n", fileName)
+		if err := os.MkdirAll(filepath.Dir(fileName), folderPerm); err != nil {
+			return fmt.Errorf("unable to create directory \"%s\"", filepath.Dir(fileName))
+		}
+		if err := ioutil.WriteFile(fileName, []byte(data), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
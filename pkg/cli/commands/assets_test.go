@@ -0,0 +1,79 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestAssetFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	write := func(rel string) {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("BB-100.jpg")
+	write("pads/BB-200.png")
+	write("pads/specs/bb-200.pdf")
+	write(".DS_Store")
+	write(".git/config")
+
+	files, err := assetFiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	want := []string{"bb-100.jpg", "pads/bb-200.png", "pads/specs/bb-200.pdf"}
+	if len(keys) != len(want) {
+		t.Fatalf("got keys %v want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("got key \"%s\" want \"%s\"", keys[i], want[i])
+		}
+	}
+	if got := files["pads/bb-200.png"].rel; got != "pads/BB-200.png" {
+		t.Errorf("got source \"%s\" want \"pads/BB-200.png\"", got)
+	}
+
+	// keys must be unique ignoring case
+	write("bb-100.JPG")
+	if _, err := assetFiles(root); err == nil {
+		t.Error("expected an error for file names differing only by case")
+	}
+}
+
+func TestAssetEncoding(t *testing.T) {
+	tests := map[string]string{
+		"image/jpeg":      "b64",
+		"application/pdf": "b64",
+		"image/svg+xml":   L1Encoding,
+		"text/plain":      L1Encoding,
+	}
+	for mimeType, want := range tests {
+		if got := assetEncoding(mimeType); got != want {
+			t.Errorf("assetEncoding(\"%s\") = \"%s\" want \"%s\"", mimeType, got, want)
+		}
+	}
+}
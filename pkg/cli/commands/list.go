@@ -10,12 +10,14 @@ package command
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/google/uuid"
 	args "github.com/urfave/cli/v2"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
 type listParams struct {
@@ -24,6 +26,7 @@ type listParams struct {
 	password string
 	slice    string // optional (empty means show slices)
 	sliceID  uuid.UUID
+	keys     *params.KeyQuery // optional grain key conditions
 	full     bool
 	debug    bool
 }
@@ -67,20 +70,25 @@ func List(c *args.Context) error {
 		}
 		p.sliceID = slice.ID
 	}
-	// return a list of paginated grains for the slice-id
-	// todo: add pagination logic
-	result, err := api.ListGrains(p.sliceID, p.full)
-	if err != nil {
-		return err
-	}
-	for _, grain := range result.Grains {
-		if p.full {
-			printGrainFull(&grain)
-		} else {
-			printGrainBrief(&grain)
+	// return all grains for the slice-id (one page at a time ordered by grain key)
+	p.keys.Keyset = true
+	for {
+		result, err := api.ListGrainKeys(p.sliceID, p.keys, p.full)
+		if err != nil {
+			return err
+		}
+		for _, grain := range result.Grains {
+			if p.full {
+				printGrainFull(&grain)
+			} else {
+				printGrainBrief(&grain)
+			}
+		}
+		if result.Next == "" {
+			return nil
 		}
+		p.keys.After = result.Next
 	}
-	return nil
 }
 
 func getListParams(c *args.Context) (*listParams, error) {
@@ -101,6 +109,12 @@ func getListParams(c *args.Context) (*listParams, error) {
 		slice:    slice,
 		sliceID:  sliceID,
 		debug:    g.debug,
+		keys: &params.KeyQuery{
+			Prefix: strings.ToLower(c.String("prefix")),
+			Match:  strings.ToLower(c.String("match")),
+			From:   strings.ToLower(c.String("from")),
+			To:     strings.ToLower(c.String("to")),
+		},
 	}, nil
 }
 
@@ -0,0 +1,126 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package command
+
+// sandpiper meta command
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	args "github.com/urfave/cli/v2"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+type metaParams struct {
+	addr     *url.URL // our sandpiper server
+	user     string
+	password string
+	slice    string
+	sliceID  uuid.UUID
+	set      sandpiper.MetaMap // keys to add or change
+	deletes  []string          // keys to remove
+	replace  bool              // remove keys not in set
+	debug    bool
+}
+
+// changes checks if the command changes metadata (rather than just listing it)
+func (p *metaParams) changes() bool {
+	return len(p.set) > 0 || len(p.deletes) > 0 || p.replace
+}
+
+// Meta lists or changes the metadata of a slice (locking the slice during the change)
+func Meta(c *args.Context) error {
+	p, err := getMetaParams(c)
+	if err != nil {
+		return err
+	}
+
+	// Login to the api server (saving token)
+	api, err := client.Login(p.addr, p.user, p.password, p.debug)
+	if err != nil {
+		return err
+	}
+
+	if p.sliceID == uuid.Nil {
+		// use provided slice-name to get the slice-id
+		slice, err := api.SliceByName(p.slice)
+		if err != nil {
+			return err
+		}
+		p.sliceID = slice.ID
+	}
+
+	meta, err := api.SliceMetaData(p.sliceID)
+	if p.changes() && err == nil {
+		meta, err = changeMeta(api, p)
+	}
+	if err != nil {
+		return err
+	}
+	for _, m := range meta {
+		fmt.Printf("%s = %s\n", m.Key, m.Value)
+	}
+	return nil
+}
+
+// changeMeta makes the metadata changes with the slice locked (so a sync can't start)
+func changeMeta(api *client.Client, p *metaParams) (meta sandpiper.MetaArray, err error) {
+	if err := api.LockSlice(p.sliceID); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if e := api.UnlockSlice(p.sliceID); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	for _, key := range p.deletes {
+		if err := api.DeleteSliceMetadata(p.sliceID, key); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.set) > 0 || p.replace {
+		return api.SetSliceMetadata(p.sliceID, p.set, p.replace)
+	}
+	return api.SliceMetaData(p.sliceID)
+}
+
+func getMetaParams(c *args.Context) (*metaParams, error) {
+	// get sandpiper global params from config file and args
+	g, err := GetGlobalParams(c)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(sandpiper.MetaMap)
+	for _, kv := range c.StringSlice("set") {
+		i := strings.Index(kv, "=")
+		if i < 1 {
+			return nil, errors.New("metadata must be provided as key=value (" + kv + ")")
+		}
+		set[strings.TrimSpace(kv[:i])] = kv[i+1:]
+	}
+
+	slice := c.String("slice")
+	sliceID, _ := uuid.Parse(slice)
+
+	return &metaParams{
+		addr:     g.addr,
+		user:     g.user,
+		password: g.password,
+		slice:    slice,
+		sliceID:  sliceID,
+		set:      set,
+		deletes:  c.StringSlice("delete"),
+		replace:  c.Bool("replace"),
+		debug:    g.debug,
+	}, nil
+}
@@ -82,6 +82,12 @@ func (cmd *pullCmd) allSlices() error {
 		return err
 	}
 	for _, slice := range result.Slices {
+		if slice.SliceType == AssetSliceType {
+			if err := cmd.pullAssets(&slice); err != nil {
+				return err
+			}
+			continue
+		}
 		grain, err := cmd.api.GetLevel1Grain(slice.ID)
 		if err != nil {
 			return err
@@ -114,6 +120,9 @@ func (cmd *pullCmd) oneSlice() error {
 			return err
 		}
 	}
+	if slice.SliceType == AssetSliceType {
+		return cmd.pullAssets(slice)
+	}
 	grain, err := cmd.api.GetLevel1Grain(slice.ID)
 	if err != nil {
 		return err
@@ -0,0 +1,101 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package command
+
+// sandpiper search command
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	args "github.com/urfave/cli/v2"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/client"
+)
+
+type searchParams struct {
+	addr     *url.URL // our sandpiper server
+	user     string
+	password string
+	text     string
+	slice    string // optional (empty means all subscribed slices)
+	sliceID  uuid.UUID
+	reindex  bool // rebuild the search index instead
+	debug    bool
+}
+
+// Search lists grains matching words in their key, source file name or payload (or rebuilds the
+// search index with --reindex)
+func Search(c *args.Context) error {
+	p, err := getSearchParams(c)
+	if err != nil {
+		return err
+	}
+
+	// Login to the api server (saving token)
+	api, err := client.Login(p.addr, p.user, p.password, p.debug)
+	if err != nil {
+		return err
+	}
+
+	if p.slice != "" && p.sliceID == uuid.Nil {
+		// use provided slice-name to get the slice-id
+		slice, err := api.SliceByName(p.slice)
+		if err != nil {
+			return err
+		}
+		p.sliceID = slice.ID
+	}
+
+	if p.reindex {
+		result, err := api.Reindex(p.sliceID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d grains reindexed\n", result.Grains)
+		return nil
+	}
+
+	result, err := api.Search(p.text, p.sliceID)
+	if err != nil {
+		return err
+	}
+	for _, r := range result.Results {
+		fmt.Printf("%s %s/%s \"%s\" (%.3f)\n", r.GrainID.String(), r.SliceName, r.GrainKey, r.Source, r.Rank)
+	}
+	if result.Paging != nil && result.Paging.Count > len(result.Results) {
+		fmt.Printf("(showing %d of %d matches)\n", len(result.Results), result.Paging.Count)
+	}
+	return nil
+}
+
+func getSearchParams(c *args.Context) (*searchParams, error) {
+	if c.NArg() == 0 && !c.Bool("reindex") {
+		return nil, errors.New("missing search text")
+	}
+
+	// get sandpiper global params from config file and args
+	g, err := GetGlobalParams(c)
+	if err != nil {
+		return nil, err
+	}
+
+	slice := c.String("slice")
+	sliceID, _ := uuid.Parse(slice)
+
+	return &searchParams{
+		addr:     g.addr,
+		user:     g.user,
+		password: g.password,
+		text:     strings.Join(c.Args().Slice(), " "),
+		slice:    slice,
+		sliceID:  sliceID,
+		reindex:  c.Bool("reindex"),
+		debug:    g.debug,
+	}, nil
+}
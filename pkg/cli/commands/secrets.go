@@ -26,12 +26,16 @@ func Secrets(c *args.Context) error {
 	if err != nil {
 		return err
 	}
+	master, err := APISecret() // same form as the api key secret
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("\n# ENVIRONMENT VARIABLES (remove double quotes from Docker .env files):\n\n")
-	fmt.Printf("export APIKEY_SECRET=\"%s\"\nexport JWT_SECRET=\"%s\"\n", api, jwt)
+	fmt.Printf("export APIKEY_SECRET=\"%s\"\nexport JWT_SECRET=\"%s\"\nexport MASTER_KEY=\"%s\"\n", api, jwt, master)
 
 	fmt.Printf("\n# CONFIG FILE (YAML) ENTRIES:\n")
-	fmt.Printf("\napi_key_secret: %s\nsecret: %s\n\n", api, jwt)
+	fmt.Printf("\napi_key_secret: %s\nsecret: %s\nmaster_key: %s\n\n", api, jwt, master)
 
 	return nil
 }
@@ -0,0 +1,34 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package client
+
+import (
+	"net/url"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// ActivityReport returns the sync activity summary for a period (YYYY-MM-DD dates, where empty
+// values use the server's default of the last 30 days)
+func (c *Client) ActivityReport(from, to string) (*sandpiper.ActivityReport, error) {
+	q := url.Values{}
+	if from != "" {
+		q.Set("from", from)
+	}
+	if to != "" {
+		q.Set("to", to)
+	}
+	path := "/activity/report"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	rpt := new(sandpiper.ActivityReport)
+	_, err = c.do(req, rpt)
+	return rpt, err
+}
@@ -9,12 +9,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
 )
@@ -29,6 +32,7 @@ type Client struct {
 	auth       *sandpiper.AuthToken
 	server     *sandpiper.Server
 	httpClient *http.Client // client used to send and receive http requests.
+	session    uuid.UUID    // sync session sent with every request (if not nil)
 	debug      bool
 }
 
@@ -64,15 +68,22 @@ func Login(addr *url.URL, user, password string, debug bool) (*Client, error) {
 	return c, nil
 }
 
-// SyncLogin to the sandpiper api server using api-key (saving token in the client struct)
-func SyncLogin(addr *url.URL, key string, debug bool) (*Client, error) {
+// SyncLogin to the sandpiper api server using api-key (saving token in the client struct). Every
+// request (including the login) carries the sync session id so the primary can correlate them.
+func SyncLogin(addr *url.URL, key string, session uuid.UUID, debug bool) (*Client, error) {
 	c := New(addr, debug)
+	c.session = session
 	if err := c.login(secure.Credentials{SyncAPIKey: key}); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// Session returns the sync session id sent with each request
+func (c *Client) Session() uuid.UUID {
+	return c.session
+}
+
 // ServerRole returns the current server role
 func (c *Client) ServerRole() string {
 	return c.server.Role
@@ -131,6 +142,9 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 	if c.auth.Token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.auth.Token)
 	}
+	if c.session != uuid.Nil {
+		req.Header.Set(sandpiper.SyncSessionHeader, c.session.String())
+	}
 	return req, nil
 }
 
@@ -192,7 +206,7 @@ func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 	return resp, err
 }
 
-func toReader(v interface{}) *bytes.Reader {
+func toReader(v interface{}) io.Reader {
 	switch t := v.(type) {
 	case []byte:
 		return bytes.NewReader(t)
@@ -200,6 +214,8 @@ func toReader(v interface{}) *bytes.Reader {
 		return bytes.NewReader([]byte(t))
 	case *bytes.Reader:
 		return t
+	case io.Reader:
+		return t // streamed request body (e.g. a bulk import)
 	case nil:
 		return bytes.NewReader(nil)
 	default:
@@ -8,10 +8,13 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 
 	"github.com/google/uuid"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
 )
 
 // GrainExists will return basic information about a grain if it exists
@@ -59,6 +62,38 @@ func (c *Client) ListGrains(sliceID uuid.UUID, fullFlag bool) (*sandpiper.Grains
 	return &results, err
 }
 
+// ListGrainKeys returns a page of grains for the supplied slice using a grain key query (ordered
+// by key). Use the returned "Next" key as the query's "After" key to get the next page (with keyset
+// pagination enabled).
+func (c *Client) ListGrainKeys(sliceID uuid.UUID, kq *params.KeyQuery, fullFlag bool) (*sandpiper.GrainsPaginated, error) {
+	var results sandpiper.GrainsPaginated
+
+	q := kq.Values()
+	if fullFlag {
+		q.Set("payload", "yes")
+	}
+	path := "/grains/slice/" + sliceID.String() + "?" + q.Encode()
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(req, &results)
+	return &results, err
+}
+
+// GrainDigests returns every grain in a slice (without payload) with an md5 hash of its payload
+func (c *Client) GrainDigests(sliceID uuid.UUID) ([]sandpiper.GrainDigest, error) {
+	var results []sandpiper.GrainDigest
+
+	path := "/grains/slice/" + sliceID.String() + "/digests"
+	req, err := c.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(req, &results)
+	return results, err
+}
+
 // AddGrain adds a grain to a slice (without overwrite)
 func (c *Client) AddGrain(grain *sandpiper.Grain) error {
 	body, err := json.Marshal(grain)
@@ -73,6 +108,43 @@ func (c *Client) AddGrain(grain *sandpiper.Grain) error {
 	return err
 }
 
+// ImportGrains adds grains to a slice from a newline-delimited json stream (one grain per line)
+// in a single transaction, optionally replacing all existing grains in the slice
+func (c *Client) ImportGrains(sliceID uuid.UUID, replaceFlag bool, ndjson io.Reader) (*sandpiper.GrainImport, error) {
+	path := "/grains/slice/" + sliceID.String()
+	if replaceFlag {
+		path = path + "?replace=yes"
+	}
+	req, err := c.newRequest("POST", path, ndjson)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	result := new(sandpiper.GrainImport)
+	_, err = c.do(req, result)
+	return result, err
+}
+
+// GranulateFile splits a document into item-level grains for the supplied slice (according to the
+// slice type). Related slices receive their own items from the same document (e.g. PIES MarketCopy).
+// Only changed items are added or removed.
+func (c *Client) GranulateFile(sliceID uuid.UUID, related []uuid.UUID, source string, doc io.Reader) ([]sandpiper.Granulation, error) {
+	q := url.Values{}
+	q.Set("source", source)
+	for _, id := range related {
+		q.Add("related", id.String())
+	}
+	path := "/grains/slice/" + sliceID.String() + "/granulate?" + q.Encode()
+	req, err := c.newRequest("POST", path, doc)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	var results []sandpiper.Granulation
+	_, err = c.do(req, &results)
+	return results, err
+}
+
 // DeleteGrain deletes a grain by primary key
 func (c *Client) DeleteGrain(grainID uuid.UUID) error {
 	path := fmt.Sprintf("/grains/%s", grainID.String())
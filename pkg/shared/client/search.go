@@ -0,0 +1,47 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package client
+
+import (
+	"net/url"
+
+	"github.com/google/uuid"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Search returns grains (without payload) matching all words of the text in grain keys,
+// sources or payloads. The search is limited to one slice if sliceID is provided.
+func (c *Client) Search(text string, sliceID uuid.UUID) (*sandpiper.SearchPaginated, error) {
+	var results sandpiper.SearchPaginated
+
+	q := url.Values{}
+	q.Set("q", text)
+	if sliceID != uuid.Nil {
+		q.Set("slice", sliceID.String())
+	}
+	req, err := c.newRequest("GET", "/search?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(req, &results)
+	return &results, err
+}
+
+// Reindex rebuilds the search documents of all grains (or the grains in a slice) from their
+// decoded payloads (admin only)
+func (c *Client) Reindex(sliceID uuid.UUID) (*sandpiper.SearchReindex, error) {
+	path := "/search/reindex"
+	if sliceID != uuid.Nil {
+		path += "?slice=" + sliceID.String()
+	}
+	req, err := c.newRequest("POST", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := new(sandpiper.SearchReindex)
+	_, err = c.do(req, result)
+	return result, err
+}
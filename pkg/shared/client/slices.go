@@ -6,7 +6,9 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/google/uuid"
 
@@ -37,6 +39,17 @@ func (c *Client) SliceByID(sliceID uuid.UUID) (*sandpiper.Slice, error) {
 	return slice, err
 }
 
+// SliceType returns a registered slice type by name (with its encoding and plugins)
+func (c *Client) SliceType(name string) (*sandpiper.SliceType, error) {
+	req, err := c.newRequest("GET", "/slicetypes/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	st := new(sandpiper.SliceType)
+	_, err = c.do(req, st)
+	return st, err
+}
+
 // ListSlices returns a list of all slices
 func (c *Client) ListSlices() (*sandpiper.SlicesPaginated, error) {
 	var results sandpiper.SlicesPaginated
@@ -82,3 +95,36 @@ func (c *Client) UnlockSlice(sliceID uuid.UUID) error {
 	_, err = c.do(req, nil)
 	return err
 }
+
+// SetSliceMetadata adds (or changes) metadata keys for a locked slice, removing all other keys
+// if replacing. Returns the resulting slice metadata.
+func (c *Client) SetSliceMetadata(sliceID uuid.UUID, meta sandpiper.MetaMap, replace bool) (sandpiper.MetaArray, error) {
+	var results sandpiper.MetaArray
+
+	body, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	method := "PATCH"
+	if replace {
+		method = "PUT"
+	}
+	path := fmt.Sprintf("/slices/metadata/%s", sliceID.String())
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.do(req, &results)
+	return results, err
+}
+
+// DeleteSliceMetadata removes a metadata key from a locked slice
+func (c *Client) DeleteSliceMetadata(sliceID uuid.UUID, key string) error {
+	path := fmt.Sprintf("/slices/metadata/%s/%s", sliceID.String(), url.PathEscape(key))
+	req, err := c.newRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, nil)
+	return err
+}
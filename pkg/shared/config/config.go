@@ -9,8 +9,10 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -65,11 +67,16 @@ func Save(c *Configuration, filename string) error {
 
 // Configuration defines available config sections with pointers to their structs
 type Configuration struct {
-	DB      *Database    `yaml:"database,omitempty"`
-	Server  *Server      `yaml:"server,omitempty"`
-	JWT     *JWT         `yaml:"jwt,omitempty"`
-	App     *Application `yaml:"application,omitempty"`
-	Command *Command     `yaml:"command,omitempty"`
+	DB         *Database    `yaml:"database,omitempty"`
+	Server     *Server      `yaml:"server,omitempty"`
+	JWT        *JWT         `yaml:"jwt,omitempty"`
+	App        *Application `yaml:"application,omitempty"`
+	Validation *Validation  `yaml:"validation,omitempty"`
+	Encryption *Encryption  `yaml:"encryption,omitempty"`
+	Retention  *Retention   `yaml:"retention,omitempty"`
+	Health     *Health      `yaml:"health,omitempty"`
+	Alerting   *Alerting    `yaml:"alerting,omitempty"`
+	Command    *Command     `yaml:"command,omitempty"`
 }
 
 // Database structure holds settings for database configuration
@@ -127,6 +134,16 @@ type Server struct {
 	WriteTimeout int    `yaml:"write_timeout_seconds,omitempty"`
 	MaxSyncProcs int    `yaml:"sync_pool,omitempty"`
 	APIKeySecret string `yaml:"api_key_secret,omitempty"`
+	ExpiryCheck  int    `yaml:"expiry_check_minutes,omitempty"` // how often to deactivate expired subscriptions
+	MetricsToken string `yaml:"metrics_token,omitempty"`        // bearer token for /metrics (disabled if empty)
+}
+
+// ExpiryInterval returns how often expired subscriptions are deactivated (default hourly)
+func (s *Server) ExpiryInterval() time.Duration {
+	if s.ExpiryCheck <= 0 {
+		return time.Hour
+	}
+	return time.Duration(s.ExpiryCheck) * time.Minute
 }
 
 // APIKeySecretCode allows overriding the config value with APIKEY_SECRET environment variable
@@ -134,6 +151,11 @@ func (s *Server) APIKeySecretCode() string {
 	return env("APIKEY_SECRET", s.APIKeySecret)
 }
 
+// MetricsTokenCode allows overriding the config value with METRICS_TOKEN environment variable
+func (s *Server) MetricsTokenCode() string {
+	return env("METRICS_TOKEN", s.MetricsToken)
+}
+
 // JWT holds data necessary for JWT configuration
 type JWT struct {
 	Secret           string `yaml:"secret,omitempty"`
@@ -155,6 +177,166 @@ type Application struct {
 	ServiceLogging bool `yaml:"service_logging,omitempty"`
 }
 
+// Validation holds optional settings for grain payload validation (by slice type)
+type Validation struct {
+	Schemas    map[string]string `yaml:"schemas,omitempty"`      // xsd schema file by validator (e.g. "aces-file")
+	XMLLint    string            `yaml:"xmllint,omitempty"`      // executable used for xsd validation
+	MaxAssetMB int               `yaml:"max_asset_mb,omitempty"` // largest asset-files payload
+	AssetTypes []string          `yaml:"asset_types,omitempty"`  // allowed mime types (e.g. "image/*")
+}
+
+// Encryption holds the master keys used for encryption of slice payloads at rest
+type Encryption struct {
+	MasterKey   string   `yaml:"master_key,omitempty"`   // base64 AES-256 key that wraps slice data keys
+	RetiredKeys []string `yaml:"retired_keys,omitempty"` // previous master keys (until data keys are re-wrapped)
+}
+
+// MasterKeys allows overriding the config master key with the MASTER_KEY environment variable
+// (and allows a missing encryption section)
+func (e *Encryption) MasterKeys() (master string, retired []string) {
+	if e == nil {
+		return env("MASTER_KEY", ""), nil
+	}
+	return env("MASTER_KEY", e.MasterKey), e.RetiredKeys
+}
+
+// Retention holds the purge policies for tables that grow forever (rows older than the days kept
+// are archived to compressed files and then deleted)
+type Retention struct {
+	Days          map[string]int `yaml:"days,omitempty"`           // days to keep by table (others are kept forever)
+	TombstoneDays int            `yaml:"tombstone_days,omitempty"` // days to keep deleted grain tombstones (default 90)
+	ArchiveDir    string         `yaml:"archive_dir,omitempty"`    // where purged rows are saved (default "archive")
+	CheckHours    int            `yaml:"check_hours,omitempty"`    // how often to purge (default 24)
+}
+
+// tombstoneTable records deleted grains (always purged, unlike the tables in Days)
+const tombstoneTable = "grain_tombstones"
+
+// Policies returns the days to keep by table, including the grain tombstones (an explicit
+// "days: grain_tombstones:" takes precedence over tombstone_days)
+func (r *Retention) Policies() map[string]int {
+	days := map[string]int{tombstoneTable: 90}
+	if r == nil {
+		return days
+	}
+	if r.TombstoneDays > 0 {
+		days[tombstoneTable] = r.TombstoneDays
+	}
+	for table, n := range r.Days {
+		days[table] = n
+	}
+	return days
+}
+
+// PurgeInterval returns how often old rows are purged (default daily)
+func (r *Retention) PurgeInterval() time.Duration {
+	if r == nil || r.CheckHours <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(r.CheckHours) * time.Hour
+}
+
+// ArchivePath returns where purged rows are saved (default "archive" in the working directory)
+func (r *Retention) ArchivePath() string {
+	if r == nil || r.ArchiveDir == "" {
+		return "archive"
+	}
+	return r.ArchiveDir
+}
+
+// Health holds the thresholds used by the readiness check
+type Health struct {
+	MinFreeMB    int `yaml:"min_free_mb,omitempty"`    // free disk space required where the server writes files (default 500)
+	SyncMaxHours int `yaml:"sync_max_hours,omitempty"` // oldest successful sync allowed (per primary) on a secondary (default 48)
+}
+
+// MinFreeBytes returns the free disk space required to be ready
+func (h *Health) MinFreeBytes() uint64 {
+	if h == nil || h.MinFreeMB <= 0 {
+		return 500 << 20
+	}
+	return uint64(h.MinFreeMB) << 20
+}
+
+// SyncMaxAge returns the age of the last successful sync with a primary before a secondary is not ready
+func (h *Health) SyncMaxAge() time.Duration {
+	if h == nil || h.SyncMaxHours <= 0 {
+		return 48 * time.Hour
+	}
+	return time.Duration(h.SyncMaxHours) * time.Hour
+}
+
+// Alerting holds the alert rule thresholds and the mail server used to notify recipients (alerts
+// are only checked if this section is configured)
+type Alerting struct {
+	CheckMinutes        int   `yaml:"check_minutes,omitempty"`        // how often to check the rules (default 15)
+	ConsecutiveFailures int   `yaml:"consecutive_failures,omitempty"` // failed syncs in a row (default 3)
+	StaleDays           int   `yaml:"stale_days,omitempty"`           // days without a good sync (default 7)
+	ExpiringDays        int   `yaml:"expiring_days,omitempty"`        // days before a subscription ends (default 14)
+	RepeatHours         int   `yaml:"repeat_hours,omitempty"`         // re-send open alerts (default 0 is once)
+	SMTP                *SMTP `yaml:"smtp,omitempty"`                 // required for email recipients
+}
+
+// CheckInterval returns how often the alert rules are checked (default every 15 minutes)
+func (a *Alerting) CheckInterval() time.Duration {
+	if a.CheckMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(a.CheckMinutes) * time.Minute
+}
+
+// Failures returns the failed syncs in a row for the consecutive failures rule (default 3)
+func (a *Alerting) Failures() int {
+	if a.ConsecutiveFailures <= 0 {
+		return 3
+	}
+	return a.ConsecutiveFailures
+}
+
+// StalePeriod returns how long a subscribed slice can go without a good sync (default 7 days)
+func (a *Alerting) StalePeriod() time.Duration {
+	if a.StaleDays <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(a.StaleDays) * 24 * time.Hour
+}
+
+// ExpiringPeriod returns how long before its end date a subscription alert opens (default 14 days)
+func (a *Alerting) ExpiringPeriod() time.Duration {
+	if a.ExpiringDays <= 0 {
+		return 14 * 24 * time.Hour
+	}
+	return time.Duration(a.ExpiringDays) * 24 * time.Hour
+}
+
+// RepeatInterval returns how often an open alert is re-sent (zero for never)
+func (a *Alerting) RepeatInterval() time.Duration {
+	return time.Duration(a.RepeatHours) * time.Hour
+}
+
+// SMTP holds the mail server used for email alerts
+type SMTP struct {
+	Host     string `yaml:"host,omitempty"`
+	Port     string `yaml:"port,omitempty"` // default 587
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	From     string `yaml:"from,omitempty"`
+}
+
+// Addr returns the host:port of the mail server
+func (s *SMTP) Addr() string {
+	port := s.Port
+	if port == "" {
+		port = "587"
+	}
+	return net.JoinHostPort(s.Host, port)
+}
+
+// PasswordCode allows overriding the config value with SMTP_PASSWORD environment variable
+func (s *SMTP) PasswordCode() string {
+	return env("SMTP_PASSWORD", s.Password)
+}
+
 // Command holds configuration options for the `sandpiper` command
 type Command struct {
 	URL          string `yaml:"url,omitempty"`
@@ -60,6 +60,11 @@ func TestLoadConfig(t *testing.T) {
 				App: &config.Application{
 					MinPasswordStr: 3,
 				},
+				Retention: &config.Retention{
+					Days:       map[string]int{"activity": 90},
+					ArchiveDir: "/tmp/archive",
+					CheckHours: 12,
+				},
 			},
 		},
 	}
@@ -71,3 +76,31 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRetentionPolicies(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  *config.Retention
+		want map[string]int
+	}{
+		{
+			name: "Tombstones kept 90 days by default",
+			want: map[string]int{"grain_tombstones": 90},
+		},
+		{
+			name: "Tombstone days",
+			cfg:  &config.Retention{Days: map[string]int{"activity": 365}, TombstoneDays: 30},
+			want: map[string]int{"activity": 365, "grain_tombstones": 30},
+		},
+		{
+			name: "Days take precedence",
+			cfg:  &config.Retention{Days: map[string]int{"grain_tombstones": 7}, TombstoneDays: 30},
+			want: map[string]int{"grain_tombstones": 7},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.Policies())
+		})
+	}
+}
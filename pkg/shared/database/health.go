@@ -0,0 +1,129 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package database
+
+// database readiness checks (connectivity, pool, schema, settings and sync age)
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/health"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Health registers the database readiness checks. A secondary server also checks the age of
+// its last successful sync with each primary (a warning if older than `maxSyncAge`).
+func (db *DB) Health(h *health.Health, maxSyncAge time.Duration) {
+	h.Add("database", db.checkConnection())
+	h.Add("schema", db.checkSchema)
+	h.Add("settings", db.checkSettings)
+	if db.Settings.ServerRole == sandpiper.SecondaryServer {
+		h.Add("sync", db.checkSyncAge(maxSyncAge))
+	}
+}
+
+// checkConnection runs a trivial query and reports the connection pool, warning if the pool is
+// exhausted or waits for a connection timed out since the previous check
+func (db *DB) checkConnection() health.CheckFunc {
+	var lastTimeouts uint32
+
+	return func(ctx context.Context) health.Check {
+		if _, err := db.ExecContext(ctx, "SELECT 1"); err != nil {
+			return health.Failed(err.Error(), nil)
+		}
+		stats := db.PoolStats()
+		size := db.Options().PoolSize
+		details := map[string]interface{}{
+			"pool_size":   size,
+			"connections": stats.TotalConns,
+			"idle":        stats.IdleConns,
+			"timeouts":    stats.Timeouts,
+		}
+		prev := atomic.SwapUint32(&lastTimeouts, stats.Timeouts)
+		switch {
+		case stats.Timeouts > prev:
+			return health.Warned(fmt.Sprintf("%d pool timeouts since last check", stats.Timeouts-prev), details)
+		case int(stats.TotalConns) >= size && stats.IdleConns == 0:
+			return health.Warned("connection pool exhausted", details)
+		}
+		return health.Passed(details)
+	}
+}
+
+// checkSchema compares the migration version of the database with the current schema
+func (db *DB) checkSchema(ctx context.Context) health.Check {
+	var ver float64
+	_, err := db.QueryOneContext(ctx, pg.Scan(&ver), "SELECT coalesce(max(version), 0) FROM darwin_migrations")
+	if err != nil {
+		return health.Failed(err.Error(), nil)
+	}
+	want := SchemaVersion()
+	details := map[string]interface{}{"version": ver, "want": want}
+	if ver != want {
+		return health.Failed(fmt.Sprintf("database is v%.2f but the server requires v%.2f", ver, want), details)
+	}
+	return health.Passed(details)
+}
+
+// checkSettings makes sure the settings row exists (created when the database is initialized)
+func (db *DB) checkSettings(ctx context.Context) health.Check {
+	n, err := db.ModelContext(ctx, (*sandpiper.Setting)(nil)).Count()
+	if err != nil {
+		return health.Failed(err.Error(), nil)
+	}
+	if n == 0 {
+		return health.Failed("missing db settings: database not initialized", nil)
+	}
+	return health.Passed(nil)
+}
+
+// checkSyncAge reports the last successful sync with each active primary
+func (db *DB) checkSyncAge(maxAge time.Duration) health.CheckFunc {
+	return func(ctx context.Context) health.Check {
+		var rows []struct {
+			ID          string
+			Name        string
+			LastSuccess time.Time
+		}
+		// each completed sync logs one activity row without a subscription (see sync.Start)
+		_, err := db.QueryContext(ctx, &rows, `
+			SELECT c.id, c.name, max(a.created_at) AS last_success
+			FROM companies c LEFT JOIN activity a
+				ON a.company_id = c.id AND a.sub_id IS NULL AND a.success
+			WHERE c.id <> ? AND c.sync_addr <> '' AND c.active
+			GROUP BY c.id, c.name ORDER BY c.name`, db.Settings.ServerID)
+		if err != nil {
+			return health.Failed(err.Error(), nil)
+		}
+
+		now := time.Now()
+		var stale []string
+		primaries := make([]map[string]interface{}, len(rows))
+		for i, row := range rows {
+			p := map[string]interface{}{"id": row.ID, "name": row.Name}
+			if row.LastSuccess.IsZero() {
+				stale = append(stale, row.Name)
+			} else {
+				age := now.Sub(row.LastSuccess)
+				p["last_success"] = row.LastSuccess
+				p["age_seconds"] = int64(age.Seconds())
+				if age > maxAge {
+					stale = append(stale, row.Name)
+				}
+			}
+			primaries[i] = p
+		}
+		details := map[string]interface{}{"max_age_seconds": int64(maxAge.Seconds()), "primaries": primaries}
+		if len(stale) > 0 {
+			return health.Warned(fmt.Sprintf("no successful sync within %v: %v", maxAge, stale), details)
+		}
+		return health.Passed(details)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package database
+
+// database metrics (connection pool, queries and storage)
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/metrics"
+)
+
+// metricsHook times every query
+type metricsHook struct {
+	queries  *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+// BeforeQuery is an unused stub (the event already has a start time).
+func (h metricsHook) BeforeQuery(ctx context.Context, _ *pg.QueryEvent) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterQuery records the query and its duration.
+func (h metricsHook) AfterQuery(_ context.Context, q *pg.QueryEvent) error {
+	status := "ok"
+	if q.Err != nil && q.Err != pg.ErrNoRows {
+		status = "error"
+	}
+	h.queries.With(status).Inc()
+	h.duration.With().Observe(time.Since(q.StartTime).Seconds())
+	return nil
+}
+
+// Metrics registers database metrics with a registry (call once per registry)
+func (db *DB) Metrics(r *metrics.Registry) {
+	db.AddQueryHook(metricsHook{
+		queries:  r.Counter("sandpiper_db_queries_total", "Database queries by status (ok or error).", "status"),
+		duration: r.Histogram("sandpiper_db_query_duration_seconds", "Database query latency.", metrics.DefBuckets),
+	})
+
+	pool := func(value func(*pg.PoolStats) uint32) metrics.CollectFunc {
+		return func() ([]metrics.Sample, error) {
+			return []metrics.Sample{{Value: float64(value(db.PoolStats()))}}, nil
+		}
+	}
+	r.CounterFunc("sandpiper_db_pool_hits_total", "Times a free connection was found in the pool.",
+		pool(func(s *pg.PoolStats) uint32 { return s.Hits }))
+	r.CounterFunc("sandpiper_db_pool_misses_total", "Times a free connection was not found in the pool.",
+		pool(func(s *pg.PoolStats) uint32 { return s.Misses }))
+	r.CounterFunc("sandpiper_db_pool_timeouts_total", "Times a wait for a pool connection timed out.",
+		pool(func(s *pg.PoolStats) uint32 { return s.Timeouts }))
+	r.GaugeFunc("sandpiper_db_pool_connections", "Connections in the pool.",
+		pool(func(s *pg.PoolStats) uint32 { return s.TotalConns }))
+	r.GaugeFunc("sandpiper_db_pool_idle_connections", "Idle connections in the pool.",
+		pool(func(s *pg.PoolStats) uint32 { return s.IdleConns }))
+	r.CounterFunc("sandpiper_db_pool_stale_connections_total", "Stale connections removed from the pool.",
+		pool(func(s *pg.PoolStats) uint32 { return s.StaleConns }))
+
+	r.GaugeFunc("sandpiper_grain_bytes", "Grain payload bytes stored by slice.", db.grainBytes, "slice_id", "slice_name")
+}
+
+// grainBytes returns the stored (compressed) payload size of each slice (counted when the slice
+// is refreshed, so this doesn't read the grains)
+func (db *DB) grainBytes() ([]metrics.Sample, error) {
+	var rows []struct {
+		ID    string
+		Name  string
+		Bytes float64
+	}
+	_, err := db.Query(&rows, `SELECT id, name, content_bytes AS bytes FROM slices`)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]metrics.Sample, len(rows))
+	for i, row := range rows {
+		samples[i] = metrics.Sample{Labels: []string{row.ID, row.Name}, Value: row.Bytes}
+	}
+	return samples, nil
+}
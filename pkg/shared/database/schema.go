@@ -181,7 +181,234 @@ func defineSchema() []darwin.Migration {
 		ADD CONSTRAINT sync_user_fk FOREIGN KEY (sync_user_id) REFERENCES "users" ON DELETE RESTRICT;`
 	) // v1 release
 	var (
-	// this is a placeholder to show our change pattern of one release per var(...) making code-folding easier.
+		tblGrainSearchV2 = `
+		CREATE TABLE IF NOT EXISTS "grain_search" (
+			"grain_id" uuid PRIMARY KEY REFERENCES "grains" ON DELETE CASCADE,
+			"slice_id" uuid REFERENCES "slices" ON DELETE CASCADE,
+			"document" tsvector NOT NULL  /* grain_key (weight a), source (weight b) and payload text */
+		);`
+
+		idxGrainSearchV2 = `
+		CREATE INDEX ON grain_search USING GIN (document);
+		CREATE INDEX ON grain_search (slice_id);`
+
+		popGrainSearchV2 = `
+		INSERT INTO grain_search (grain_id, slice_id, document)
+		SELECT id, slice_id,
+			setweight(to_tsvector('simple', grain_key), 'A') ||
+			setweight(to_tsvector('simple', coalesce(source, '')), 'B') ||
+			CASE WHEN encoding = 'raw' AND length(payload) < 500000  /* only plain text in sql (then "search --reindex") */
+				THEN to_tsvector('simple', payload) ELSE ''::tsvector END
+		FROM grains
+		ON CONFLICT (grain_id) DO NOTHING;`
+
+		tblSliceKeysV2 = `
+		CREATE TABLE IF NOT EXISTS "slice_keys" (
+			"id"         serial PRIMARY KEY,
+			"slice_id"   uuid REFERENCES "slices" ON DELETE CASCADE,
+			"data_key"   text NOT NULL,  /* wrapped by the server master key */
+			"active"     boolean NOT NULL,
+			"created_at" timestamp
+		);
+		CREATE UNIQUE INDEX ON slice_keys (slice_id) WHERE active;  /* one active key per slice */`
+
+		altEncryptionV2 = `
+		ALTER TABLE slices ADD COLUMN "encrypted" boolean NOT NULL DEFAULT false;
+		ALTER TABLE grains ADD COLUMN "key_id" int REFERENCES "slice_keys" ON DELETE RESTRICT;`
+
+		idxGrainsV2 = `
+		CREATE INDEX ON grains (slice_id, grain_key text_pattern_ops);  /* grain_key prefix (like) queries */`
+
+		tblSliceMembersV2 = `
+		ALTER TABLE slices ADD COLUMN "composite" boolean NOT NULL DEFAULT false;
+		CREATE TABLE IF NOT EXISTS "slice_members" (
+			"composite_id" uuid REFERENCES "slices" ON DELETE CASCADE,
+			"member_id"    uuid REFERENCES "slices" ON DELETE RESTRICT,  /* remove from composites first */
+			PRIMARY KEY ("composite_id", "member_id")
+		);
+		CREATE INDEX ON slice_members (member_id);`
+
+		tblSliceReleasesV2 = `
+		CREATE TABLE IF NOT EXISTS "slice_releases" (
+			"id"            uuid PRIMARY KEY,
+			"slice_id"      uuid NOT NULL REFERENCES "slices" ON DELETE CASCADE,
+			"version"       text NOT NULL,
+			"description"   text,
+			"content_hash"  text,
+			"content_count" integer,
+			"content_date"  timestamp,
+			"metadata"      jsonb,
+			"created_at"    timestamp,
+			UNIQUE ("slice_id", "version"),
+			UNIQUE ("id", "slice_id")  /* for the subscriptions foreign key */
+		);
+		CREATE TABLE IF NOT EXISTS "release_grains" (  /* frozen copy of the slice grains */
+			"release_id" uuid REFERENCES "slice_releases" ON DELETE CASCADE,
+			"grain_id"   uuid,
+			"grain_key"  text NOT NULL,
+			"encoding"   encoding_enum,
+			"payload"    text,
+			"source"     text,
+			"key_id"     int REFERENCES "slice_keys" ON DELETE RESTRICT,
+			"created_at" timestamp,
+			PRIMARY KEY ("release_id", "grain_id")
+		);
+		CREATE INDEX ON release_grains (grain_id);
+		ALTER TABLE subscriptions ADD COLUMN "release_id" uuid;  /* null tracks the latest content */
+		ALTER TABLE subscriptions ADD FOREIGN KEY ("release_id", "slice_id") REFERENCES "slice_releases" ("id", "slice_id");`
+
+		tblSubscriptionRulesV2 = `
+		CREATE TABLE IF NOT EXISTS "subscription_rules" (
+			"id"          uuid PRIMARY KEY,
+			"company_id"  uuid NOT NULL REFERENCES "companies" ON DELETE CASCADE,
+			"tag_id"      int NOT NULL REFERENCES "tags" ON DELETE RESTRICT,  /* remove rules before the tag */
+			"description" text,
+			"active"      boolean NOT NULL DEFAULT false,
+			"created_at"  timestamp,
+			"updated_at"  timestamp,
+			UNIQUE ("company_id", "tag_id")
+		);
+		CREATE INDEX ON subscription_rules (tag_id);
+		ALTER TABLE subscriptions ADD COLUMN "rule_id" uuid REFERENCES "subscription_rules" ON DELETE SET NULL;  /* created by a rule */`
+
+		altSubscriptionTermsV2 = `
+		ALTER TABLE subscriptions ADD COLUMN "start_date" timestamp;  /* null is open-ended */
+		ALTER TABLE subscriptions ADD COLUMN "end_date" timestamp;
+		CREATE INDEX ON subscriptions (end_date) WHERE end_date IS NOT NULL;`
+
+		tblSubscriptionRequestsV2 = `
+		CREATE TABLE IF NOT EXISTS "subscription_requests" (
+			"id"            uuid PRIMARY KEY,
+			"company_id"    uuid REFERENCES "companies" ON DELETE CASCADE,  /* existing (or approved) company */
+			"company_name"  text NOT NULL,
+			"contact_name"  text,
+			"email"         text,
+			"server_id"     uuid,  /* requesting secondary server */
+			"sync_addr"     text,
+			"kind"          text,
+			"slice_ids"     uuid[],
+			"message"       text,
+			"status"        text NOT NULL CHECK (status IN ('pending', 'approved', 'rejected')),
+			"decided_by"    int REFERENCES "users" ON DELETE SET NULL,
+			"decided_at"    timestamp,
+			"decision_note" text,
+			"created_at"    timestamp,
+			"updated_at"    timestamp
+		);
+		CREATE INDEX ON subscription_requests (status);`
+
+		tblSliceTypesV2 = `
+		CREATE TABLE IF NOT EXISTS "slice_types" (
+			"name"        text PRIMARY KEY,
+			"description" text,
+			"encoding"    encoding_enum NOT NULL DEFAULT 'z64',  /* default grain encoding */
+			"validator"   text,  /* plugin names (see validate and granulate packages) */
+			"granulator"  text,
+			"created_at"  timestamp,
+			"updated_at"  timestamp
+		);
+		INSERT INTO slice_types (name, description, encoding, validator, granulator, created_at, updated_at) VALUES
+			('aces-file', 'complete aces xml file', 'z64', 'aces-file', NULL, now(), now()),
+			('aces-items', 'aces applications and assets', 'raw', 'aces-items', 'aces-items', now(), now()),
+			('asset-files', 'digital asset files', 'z64', 'asset-files', NULL, now(), now()),
+			('pies-file', 'complete pies xml file', 'z64', 'pies-file', NULL, now(), now()),
+			('pies-items', 'pies items', 'raw', 'pies-items', 'pies-items', now(), now()),
+			('pies-marketcopy', 'pies marketing copy', 'raw', 'pies-marketcopy', 'pies-marketcopy', now(), now()),
+			('pies-pricesheet', 'pies price sheets', 'raw', 'pies-pricesheet', 'pies-pricesheet', now(), now()),
+			('partspro-file', 'partspro file', 'z64', NULL, NULL, now(), now());
+		ALTER TABLE slices ALTER COLUMN slice_type TYPE text;
+		ALTER TABLE slices ADD FOREIGN KEY (slice_type) REFERENCES slice_types ON UPDATE CASCADE;
+		DROP TYPE slice_type_enum;`
+
+		tblPurgeRunsV2 = `
+		CREATE TABLE IF NOT EXISTS "purge_runs" (
+			"id"           serial PRIMARY KEY,
+			"target"       text NOT NULL,  /* table purged */
+			"cutoff"       timestamp NOT NULL,
+			"archived"     integer NOT NULL DEFAULT 0,
+			"deleted"      integer NOT NULL DEFAULT 0,
+			"archive_file" text,
+			"error"        text,
+			"started_at"   timestamp,
+			"finished_at"  timestamp
+		);
+		CREATE INDEX ON purge_runs (target, started_at);`
+
+		tblAuditLogV2 = `
+		CREATE TABLE IF NOT EXISTS "audit_log" (
+			"id"          serial PRIMARY KEY,
+			"user_id"     int,            /* actor (no foreign key so entries outlive users) */
+			"username"    text,
+			"company_id"  uuid,
+			"action"      text NOT NULL,  /* create, update or delete */
+			"resource"    text NOT NULL,  /* e.g. company, subscription */
+			"resource_id" text,
+			"before"      jsonb,
+			"after"       jsonb,
+			"source_ip"   text,
+			"created_at"  timestamp
+		);
+		CREATE INDEX ON audit_log (created_at);
+		CREATE INDEX ON audit_log (resource, resource_id);
+		CREATE INDEX ON audit_log (user_id);`
+
+		altActivitySessionV2 = `
+		ALTER TABLE activity ADD COLUMN "session_id" uuid;  /* sync session (same id on both servers) */
+		CREATE INDEX ON activity (session_id) WHERE session_id IS NOT NULL;`
+
+		tblAlertRecipientsV2 = `
+		CREATE TABLE IF NOT EXISTS "alert_recipients" (
+			"id"          uuid PRIMARY KEY,
+			"company_id"  uuid NOT NULL REFERENCES "companies" ON DELETE CASCADE,
+			"channel"     text NOT NULL,  /* email or webhook */
+			"address"     text NOT NULL,  /* email address or webhook url */
+			"rules"       text[],         /* empty for all rules */
+			"active"      boolean NOT NULL DEFAULT true,
+			"created_at"  timestamp
+		);
+		CREATE INDEX ON alert_recipients (company_id);`
+
+		tblAlertsV2 = `
+		CREATE TABLE IF NOT EXISTS "alerts" (
+			"id"              serial PRIMARY KEY,
+			"rule"            text NOT NULL,
+			"alert_key"       text NOT NULL,  /* what the alert is about (e.g. a subscription id) */
+			"company_id"      uuid,           /* no foreign key so alerts outlive companies */
+			"title"           text,
+			"detail"          text,
+			"opened_at"       timestamp,
+			"notified_at"     timestamp,
+			"resolved_at"     timestamp,      /* null while open */
+			"delivery_error"  text
+		);
+		CREATE UNIQUE INDEX ON alerts (rule, alert_key) WHERE resolved_at IS NULL;
+		CREATE INDEX ON alerts (opened_at);`
+
+		altSubscriptionRuleRetiredV2 = `
+		ALTER TABLE subscriptions ADD COLUMN "rule_retired" boolean NOT NULL DEFAULT false;  /* deactivated by a rule (so a rule may reactivate it) */`
+
+		altSliceContentBytesV2 = `
+		ALTER TABLE slices ADD COLUMN "content_bytes" bigint NOT NULL DEFAULT 0;  /* stored grain payload size (kept by refresh) */
+		UPDATE slices s SET content_bytes = (SELECT coalesce(sum(pg_column_size(g.payload)), 0) FROM grains g WHERE g.slice_id = s.id);`
+
+		tblGrainTombstonesV2 = `
+		CREATE TABLE IF NOT EXISTS "grain_tombstones" (  /* deleted grains (purged by the retention policy) */
+			"id"          serial PRIMARY KEY,
+			"grain_id"    uuid NOT NULL,
+			"slice_id"    uuid,           /* no foreign key so tombstones outlive slices */
+			"grain_key"   text NOT NULL,
+			"deleted_at"  timestamp NOT NULL DEFAULT now()
+		);
+		CREATE INDEX ON grain_tombstones (slice_id, deleted_at);
+		CREATE INDEX ON grain_tombstones (deleted_at);
+		CREATE OR REPLACE FUNCTION grain_tombstone() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO grain_tombstones (grain_id, slice_id, grain_key) VALUES (OLD.id, OLD.slice_id, OLD.grain_key);
+			RETURN OLD;
+		END;
+		$$ LANGUAGE plpgsql;
+		CREATE TRIGGER grain_tombstone AFTER DELETE ON grains
+			FOR EACH ROW EXECUTE PROCEDURE grain_tombstone();  /* every delete path (including a slice's cascade) */`
 	) // v2 release
 
 	// minify simplifies the script to keep certain changes (spaces, tabs, case and comments) from creating a new checksum
@@ -223,6 +450,26 @@ func defineSchema() []darwin.Migration {
 		{Version: 1.13, Description: "Create Table 'users'", Script: minify(tblUsersV1)},
 		{Version: 1.14, Description: "Create Table 'settings'", Script: minify(tblSettingsV1)},
 		{Version: 1.15, Description: "Add Foreign Key 'sync_user_fk'", Script: minify(altCompaniesV1)},
+		{Version: 2.01, Description: "Create Table 'grain_search'", Script: minify(tblGrainSearchV2)},
+		{Version: 2.02, Description: "Create Indexes on 'grain_search'", Script: minify(idxGrainSearchV2)},
+		{Version: 2.03, Description: "Populate 'grain_search' from existing grains", Script: minify(popGrainSearchV2)},
+		{Version: 2.04, Description: "Create Table 'slice_keys'", Script: minify(tblSliceKeysV2)},
+		{Version: 2.05, Description: "Add encryption columns to 'slices' and 'grains'", Script: minify(altEncryptionV2)},
+		{Version: 2.06, Description: "Create Indexes on 'grains'", Script: minify(idxGrainsV2)},
+		{Version: 2.07, Description: "Create Table 'slice_members'", Script: minify(tblSliceMembersV2)},
+		{Version: 2.08, Description: "Create Table 'slice_releases'", Script: minify(tblSliceReleasesV2)},
+		{Version: 2.09, Description: "Create Table 'subscription_rules'", Script: minify(tblSubscriptionRulesV2)},
+		{Version: 2.10, Description: "Add term columns to 'subscriptions'", Script: minify(altSubscriptionTermsV2)},
+		{Version: 2.11, Description: "Create Table 'subscription_requests'", Script: minify(tblSubscriptionRequestsV2)},
+		{Version: 2.12, Description: "Create Table 'slice_types' (replacing slice_type_enum)", Script: minify(tblSliceTypesV2)},
+		{Version: 2.13, Description: "Create Table 'purge_runs'", Script: minify(tblPurgeRunsV2)},
+		{Version: 2.14, Description: "Create Table 'audit_log'", Script: minify(tblAuditLogV2)},
+		{Version: 2.15, Description: "Add session column to 'activity'", Script: minify(altActivitySessionV2)},
+		{Version: 2.16, Description: "Create Table 'alert_recipients'", Script: minify(tblAlertRecipientsV2)},
+		{Version: 2.17, Description: "Create Table 'alerts'", Script: minify(tblAlertsV2)},
+		{Version: 2.18, Description: "Add rule_retired column to 'subscriptions'", Script: minify(altSubscriptionRuleRetiredV2)},
+		{Version: 2.19, Description: "Add content_bytes column to 'slices'", Script: minify(altSliceContentBytesV2)},
+		{Version: 2.20, Description: "Create Table 'grain_tombstones'", Script: minify(tblGrainTombstonesV2)},
 	}
 }
 
@@ -301,6 +548,12 @@ func changes(v1, v2 float64) string {
 	return fmt.Sprintf("DB Version: %.2f", v1)
 }
 
+// SchemaVersion returns the latest schema version (that Migrate brings a database up to)
+func SchemaVersion() float64 {
+	schema := defineSchema()
+	return schema[len(schema)-1].Version
+}
+
 // Schema returns the current schema definitions as a string for display
 func Schema() string {
 	var b strings.Builder
@@ -0,0 +1,95 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package granulate
+
+// ACES (Aftermarket Catalog Exchange Standard) granulation
+
+/* An ACES document looks like this (abbreviated):
+ *
+ *   <ACES version="4.2">
+ *     <Header> <Company>...</Company> <TransferDate>...</TransferDate> ... </Header>
+ *     <App action="A" id="1"> ... </App>
+ *     <Asset action="A" id="1"> ... </Asset>
+ *     <DigitalAsset> <DigitalFileInformation AssetName="..." action="A"> ... </DigitalFileInformation> </DigitalAsset>
+ *     <Footer> <RecordCount>...</RecordCount> </Footer>
+ *   </ACES>
+ *
+ * Grain keys are "app:<id>", "asset:<id>" and "file:<AssetName>".
+ */
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+const (
+	acesMetaPrefix = "aces."
+	acesItems      = "aces-items"
+)
+
+// ACES granulates an ACES xml document into one item per App, Asset and DigitalFileInformation.
+// The document version, header and footer are returned as metadata.
+func ACES(r io.Reader, fn func(Item) error) (sandpiper.MetaMap, error) {
+	meta := make(sandpiper.MetaMap)
+	keys := make(map[string]bool)
+
+	// emit creates an item from an element using its identifying attribute
+	emit := func(d *document, t xml.StartElement, prefix, attr string) error {
+		var e element
+		if err := d.DecodeElement(&e, &t); err != nil {
+			return &FormatError{Err: err}
+		}
+		id := strings.TrimSpace(e.attr(attr))
+		if id == "" {
+			return formatError("<%s> element is missing the \"%s\" attribute", t.Name.Local, attr)
+		}
+		key := prefix + id
+		if keys[key] {
+			return formatError("duplicate <%s> %s \"%s\"", t.Name.Local, attr, id)
+		}
+		keys[key] = true
+		return fn(Item{SliceType: acesItems, Key: key, Data: d.raw(t)})
+	}
+
+	// addSection saves a simple element's fields as metadata
+	addSection := func(d *document, t xml.StartElement, prefix string) error {
+		var s section
+		if err := d.DecodeElement(&s, &t); err != nil {
+			return &FormatError{Err: err}
+		}
+		s.addTo(meta, prefix)
+		return nil
+	}
+
+	root, err := walk(r, "ACES", func(d *document, t xml.StartElement) error {
+		switch t.Name.Local {
+		case "Header":
+			return addSection(d, t, acesMetaPrefix+"header.")
+		case "Footer":
+			return addSection(d, t, acesMetaPrefix+"footer.")
+		case "App":
+			return emit(d, t, "app:", "id")
+		case "Asset":
+			return emit(d, t, "asset:", "id")
+		case "DigitalFileInformation":
+			return emit(d, t, "file:", "AssetName")
+		case "DigitalAsset":
+			// container only, so process its children (on the next token)
+			return nil
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if v := (&element{Attrs: root.Attr}).attr("version"); v != "" {
+		meta[acesMetaPrefix+"version"] = v
+	}
+	return meta, nil
+}
@@ -0,0 +1,169 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package granulate_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/granulate"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+const acesDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<ACES version="4.2">
+	<Header>
+		<Company>Better Brakes</Company>
+		<TransferDate>2020-05-01</TransferDate>
+	</Header>
+	<App action="A" id="1"><BaseVehicle id="5911"/><Qty>1</Qty><Part>BB-100</Part></App>
+	<App action="A" id="2"><BaseVehicle id="5912"/><Note>Front &amp; Rear</Note><Part>BB-200</Part></App>
+	<Asset action="A" id="7"><AssetName>bb100.jpg</AssetName></Asset>
+	<DigitalAsset>
+		<DigitalFileInformation AssetName="bb100.jpg" action="A"><FileName>bb100.jpg</FileName></DigitalFileInformation>
+	</DigitalAsset>
+	<Footer>
+		<RecordCount>2</RecordCount>
+	</Footer>
+</ACES>`
+
+func TestACES(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      string
+		wantKeys []string
+		wantData map[string]string
+		wantMeta sandpiper.MetaMap
+		wantErr  bool
+	}{
+		{
+			name:     "Complete document",
+			doc:      acesDoc,
+			wantKeys: []string{"app:1", "app:2", "asset:7", "file:bb100.jpg"},
+			wantData: map[string]string{
+				"app:1": `<App action="A" id="1"><BaseVehicle id="5911"/><Qty>1</Qty><Part>BB-100</Part></App>`,
+				"app:2": `<App action="A" id="2"><BaseVehicle id="5912"/><Note>Front &amp; Rear</Note><Part>BB-200</Part></App>`,
+			},
+			wantMeta: sandpiper.MetaMap{
+				"aces.version":             "4.2",
+				"aces.header.Company":      "Better Brakes",
+				"aces.header.TransferDate": "2020-05-01",
+				"aces.footer.RecordCount":  "2",
+			},
+		},
+		{
+			name:    "Wrong root element",
+			doc:     `<PIES><Items/></PIES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Missing app id",
+			doc:     `<ACES><App action="A"><Part>1</Part></App></ACES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate app id",
+			doc:     `<ACES><App id="1"/><App id="1"/></ACES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Malformed xml",
+			doc:     `<ACES><App id="1"></ACES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Empty document",
+			doc:     ``,
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			data := make(map[string]string)
+
+			meta, err := granulate.ACES(strings.NewReader(tt.doc), func(item granulate.Item) error {
+				keys = append(keys, item.Key)
+				data[item.Key] = string(item.Data)
+				return nil
+			})
+			if tt.wantErr {
+				var fe *granulate.FormatError
+				assert.True(t, errors.As(err, &fe), "expected a FormatError, got %v", err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantKeys, keys)
+			assert.Equal(t, tt.wantMeta, meta)
+			for k, v := range tt.wantData {
+				assert.Equal(t, v, data[k])
+			}
+		})
+	}
+}
+
+func TestACESNamespaces(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<ACES xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="ACES_4_2.xsd" version="4.2">
+	<App action="A" id="1" xsi:type="AppType"><BaseVehicle id="5911"/><Note xsi:nil="true"/></App>
+	<App xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" action="A" id="2"/>
+</ACES>`
+	data := make(map[string]string)
+	meta, err := granulate.ACES(strings.NewReader(doc), func(item granulate.Item) error {
+		data[item.Key] = string(item.Data)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "4.2", meta["aces.version"])
+
+	// prefixes are kept and the root declaration is copied so each grain is valid xml by itself
+	assert.Equal(t, `<App xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" action="A" id="1" xsi:type="AppType"><BaseVehicle id="5911"/><Note xsi:nil="true"/></App>`, data["app:1"])
+	assert.Equal(t, `<App xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" action="A" id="2"/>`, data["app:2"])
+	for k, v := range data {
+		var e struct{}
+		assert.Nil(t, xml.Unmarshal([]byte(v), &e), k)
+	}
+}
+
+func TestACESLargeDocument(t *testing.T) {
+	// items cross the decoder's read buffer boundaries
+	var b strings.Builder
+	b.WriteString("<ACES>\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&b, "  <App action=\"A\" id=\"%d\"><Part>%s</Part></App>\n", i, strings.Repeat("x", i%50))
+	}
+	b.WriteString("</ACES>")
+
+	n := 0
+	_, err := granulate.ACES(strings.NewReader(b.String()), func(item granulate.Item) error {
+		assert.Equal(t, fmt.Sprintf(`<App action="A" id="%d"><Part>%s</Part></App>`, n, strings.Repeat("x", n%50)), string(item.Data))
+		n++
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 500, n)
+}
+
+func TestACESCallbackError(t *testing.T) {
+	errStore := errors.New("store failed")
+	_, err := granulate.ACES(strings.NewReader(acesDoc), func(item granulate.Item) error {
+		return errStore
+	})
+	assert.Equal(t, errStore, err)
+}
+
+func TestLookup(t *testing.T) {
+	f, ok := granulate.Lookup("aces-items")
+	assert.True(t, ok)
+	assert.Equal(t, "aces.", f.MetaPrefix)
+
+	_, ok = granulate.Lookup("aces-file")
+	assert.False(t, ok)
+}
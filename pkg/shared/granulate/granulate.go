@@ -0,0 +1,234 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package granulate splits standard delivery files (e.g. ACES, PIES) into item-level grains.
+package granulate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+/* Documents are read as a stream (never completely in memory). Each item element is passed to
+ * a callback as raw xml, ready to be stored as a grain payload. Document-level information
+ * (header, footer, version) is returned as slice metadata using a prefix unique to the format.
+ *
+ * A document can contain items for several slice types (e.g. a full PIES file includes items,
+ * marketing copy and price sheets), so each item identifies the slice type it belongs to. Formats
+ * are named after the standard slice types, and each registered slice type names its format (its
+ * "granulator") in the slice_types table.
+ *
+ * Usage:
+ *   f, ok := granulate.Lookup(sliceType.Granulator)
+ *   meta, err := f.Parse(file, func(item granulate.Item) error { ... })
+ */
+
+// Item is one granulated element (stored as a grain)
+type Item struct {
+	SliceType string // the type of slice that holds this item (a format name)
+	Key       string // unique within the slice type
+	Data      []byte // the complete xml element
+}
+
+// Parser reads a document calling fn for each item, returning document-level metadata
+type Parser func(r io.Reader, fn func(Item) error) (sandpiper.MetaMap, error)
+
+// Format describes how a granular slice type is parsed
+type Format struct {
+	MetaPrefix string // all metadata keys created by the parser start with this prefix (unique)
+	Parse      Parser
+}
+
+// formats holds our granular slice types (slice types sharing a format are split from one document)
+var formats = map[string]Format{
+	"aces-items":      {MetaPrefix: acesMetaPrefix, Parse: ACES},
+	"pies-items":      {MetaPrefix: piesMetaPrefix, Parse: PIES},
+	"pies-marketcopy": {MetaPrefix: piesMetaPrefix, Parse: PIES},
+	"pies-pricesheet": {MetaPrefix: piesMetaPrefix, Parse: PIES},
+}
+
+// Lookup returns a granulation format by name (if found)
+func Lookup(name string) (Format, bool) {
+	f, ok := formats[name]
+	return f, ok
+}
+
+// FormatError indicates a problem with the document (rather than with storing its items)
+type FormatError struct {
+	Err error
+}
+
+func (e *FormatError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// formatError creates a FormatError from a message
+func formatError(format string, a ...interface{}) error {
+	return &FormatError{Err: fmt.Errorf(format, a...)}
+}
+
+// element holds the attributes of a single xml element (without interpreting its contents)
+type element struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+}
+
+// attr returns an attribute value by (local) name
+func (e *element) attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// field is a simple (text-only) child element
+type field struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// section is an element containing simple fields (e.g. a header)
+type section struct {
+	Fields []field `xml:",any"`
+}
+
+// addTo adds section fields to a metadata map using the supplied key prefix
+func (s *section) addTo(meta sandpiper.MetaMap, prefix string) {
+	for _, f := range s.Fields {
+		meta[prefix+f.XMLName.Local] = strings.TrimSpace(f.Value)
+	}
+}
+
+// recorder keeps the bytes read by the xml decoder so items can be copied exactly as written
+// (only bytes from the start of the current top-level element are kept)
+type recorder struct {
+	r    io.Reader
+	buf  []byte
+	base int64 // input offset of buf[0]
+}
+
+func (rec *recorder) Read(p []byte) (int, error) {
+	n, err := rec.r.Read(p)
+	rec.buf = append(rec.buf, p[:n]...)
+	return n, err
+}
+
+// discard forgets the bytes before an input offset
+func (rec *recorder) discard(offset int64) {
+	rec.buf = append(rec.buf[:0], rec.buf[offset-rec.base:]...)
+	rec.base = offset
+}
+
+// document is the xml decoder used to walk a document (with the raw input of the current element)
+type document struct {
+	*xml.Decoder
+	rec   *recorder
+	start int64      // input offset of the current element
+	ns    []xml.Attr // namespace declarations on the root element
+}
+
+// raw returns the current element exactly as written (call after decoding or skipping it). Root
+// namespace declarations (e.g. xmlns:xsi) are added to the start tag unless the element has its
+// own, so prefixed names in the item are still valid xml when it is stored by itself.
+func (d *document) raw(t xml.StartElement) []byte {
+	data := d.rec.buf[d.start-d.rec.base : d.InputOffset()-d.rec.base]
+
+	var decl bytes.Buffer
+	for _, a := range d.ns {
+		if !hasAttr(t.Attr, a.Name) {
+			decl.WriteString(" " + nsAttrName(a.Name) + `="`)
+			_ = xml.EscapeText(&decl, []byte(a.Value))
+			decl.WriteString(`"`)
+		}
+	}
+
+	// the start tag name ends at the first space, "/" or ">"
+	n := bytes.IndexAny(data, " \t\r\n/>")
+	if n < 0 || decl.Len() == 0 {
+		return append([]byte(nil), data...)
+	}
+	b := make([]byte, 0, len(data)+decl.Len())
+	b = append(b, data[:n]...)
+	b = append(b, decl.Bytes()...)
+	return append(b, data[n:]...)
+}
+
+// isNamespace reports if an attribute is a namespace declaration (xmlns or xmlns:prefix)
+func isNamespace(n xml.Name) bool {
+	return n.Space == "xmlns" || (n.Space == "" && n.Local == "xmlns")
+}
+
+// nsAttrName returns the name of a namespace declaration as written
+func nsAttrName(n xml.Name) string {
+	if n.Space == "xmlns" {
+		return "xmlns:" + n.Local
+	}
+	return n.Local
+}
+
+func hasAttr(attrs []xml.Attr, name xml.Name) bool {
+	for _, a := range attrs {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls fn for each child element of the document root (allowing it to be decoded).
+// The root element is returned so its attributes can be used.
+func walk(r io.Reader, root string, fn func(*document, xml.StartElement) error) (*xml.StartElement, error) {
+	var start *xml.StartElement
+
+	rec := &recorder{r: r}
+	d := &document{Decoder: xml.NewDecoder(rec), rec: rec}
+	for {
+		offset := d.InputOffset()
+		rec.discard(offset)
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &FormatError{Err: err}
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if start == nil {
+				if t.Name.Local != root {
+					return nil, formatError("expected root element <%s> (found <%s>)", root, t.Name.Local)
+				}
+				s := t.Copy()
+				start = &s
+				for _, a := range s.Attr {
+					if isNamespace(a.Name) {
+						d.ns = append(d.ns, a)
+					}
+				}
+				continue
+			}
+			// callback must consume the entire element (decode or skip) unless it is a container
+			d.start = offset
+			if err := fn(d, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if start == nil {
+		return nil, formatError("missing root element <%s>", root)
+	}
+	return start, nil
+}
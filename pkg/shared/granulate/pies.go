@@ -0,0 +1,137 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package granulate
+
+// PIES (Product Information Exchange Standard) granulation
+
+/* A PIES document looks like this (abbreviated):
+ *
+ *   <PIES xmlns="http://www.autocare.org">
+ *     <Header> <PIESVersion>7.1</PIESVersion> <SubmissionType>FULL</SubmissionType> ... </Header>
+ *     <PriceSheets> <PriceSheet MaintenanceType="A"> <PriceSheetNumber>...</PriceSheetNumber> ... </PriceSheet> </PriceSheets>
+ *     <MarketingCopy> <MarketCopy> <MarketCopyContent ...>...</MarketCopyContent> ... </MarketCopy> </MarketingCopy>
+ *     <Items> <Item MaintenanceType="A"> <PartNumber>...</PartNumber> <BrandAAIAID>...</BrandAAIAID> ... </Item> </Items>
+ *     <Trailer> <ItemCount>...</ItemCount> <TransactionDate>...</TransactionDate> </Trailer>
+ *   </PIES>
+ *
+ * Grain keys are "<PartNumber>@<BrandAAIAID>" for items (using BrandLabel if there is no BrandAAIAID),
+ * the PriceSheetNumber for price sheets and "mc:<content-hash>" for marketing copy (which has no
+ * identifier, so a changed MarketCopy element is a delete and an add).
+ */
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+const (
+	piesMetaPrefix  = "pies."
+	piesItems       = "pies-items"
+	piesMarketCopy  = "pies-marketcopy"
+	piesPriceSheets = "pies-pricesheet"
+)
+
+// piesItem is an Item (or PriceSheet) element with the fields used to create its key
+type piesItem struct {
+	PartNumber       string `xml:"PartNumber"`
+	BrandAAIAID      string `xml:"BrandAAIAID"`
+	BrandLabel       string `xml:"BrandLabel"`
+	PriceSheetNumber string `xml:"PriceSheetNumber"`
+}
+
+// itemKey returns the grain key for an Item (part number and brand)
+func (p *piesItem) itemKey() (string, error) {
+	part := strings.TrimSpace(p.PartNumber)
+	if part == "" {
+		return "", formatError("<Item> element is missing <PartNumber>")
+	}
+	brand := strings.TrimSpace(p.BrandAAIAID)
+	if brand == "" {
+		brand = strings.TrimSpace(p.BrandLabel)
+	}
+	if brand == "" {
+		return part, nil
+	}
+	return part + "@" + brand, nil
+}
+
+// PIES granulates a PIES xml document into one item per Item, MarketCopy and PriceSheet (each
+// for its own slice type). The header and trailer are returned as metadata.
+func PIES(r io.Reader, fn func(Item) error) (sandpiper.MetaMap, error) {
+	meta := make(sandpiper.MetaMap)
+	keys := make(map[string]bool) // by slice type and key
+
+	// emit calls fn for a unique item (allowing identical content-keyed items to be ignored)
+	emit := func(item Item, ignoreDuplicate bool) error {
+		k := item.SliceType + "/" + item.Key
+		if keys[k] {
+			if ignoreDuplicate {
+				return nil
+			}
+			return formatError("duplicate %s key \"%s\"", item.SliceType, item.Key)
+		}
+		keys[k] = true
+		return fn(item)
+	}
+
+	// addSection saves a simple element's fields as metadata
+	addSection := func(d *document, t xml.StartElement, prefix string) error {
+		var s section
+		if err := d.DecodeElement(&s, &t); err != nil {
+			return &FormatError{Err: err}
+		}
+		s.addTo(meta, prefix)
+		return nil
+	}
+
+	_, err := walk(r, "PIES", func(d *document, t xml.StartElement) error {
+		switch t.Name.Local {
+		case "Header":
+			return addSection(d, t, piesMetaPrefix+"header.")
+		case "Trailer":
+			return addSection(d, t, piesMetaPrefix+"trailer.")
+		case "Item":
+			var p piesItem
+			if err := d.DecodeElement(&p, &t); err != nil {
+				return &FormatError{Err: err}
+			}
+			key, err := p.itemKey()
+			if err != nil {
+				return err
+			}
+			return emit(Item{SliceType: piesItems, Key: key, Data: d.raw(t)}, false)
+		case "PriceSheet":
+			var p piesItem
+			if err := d.DecodeElement(&p, &t); err != nil {
+				return &FormatError{Err: err}
+			}
+			key := strings.TrimSpace(p.PriceSheetNumber)
+			if key == "" {
+				return formatError("<PriceSheet> element is missing <PriceSheetNumber>")
+			}
+			return emit(Item{SliceType: piesPriceSheets, Key: key, Data: d.raw(t)}, false)
+		case "MarketCopy":
+			if err := d.Skip(); err != nil {
+				return &FormatError{Err: err}
+			}
+			data := d.raw(t)
+			key := fmt.Sprintf("mc:%x", sha1.Sum(data))[:19] // "mc:" + 64 bits of the hash
+			return emit(Item{SliceType: piesMarketCopy, Key: key, Data: data}, true)
+		case "Items", "PriceSheets", "MarketingCopy":
+			// containers only, so process their children (on the next token)
+			return nil
+		}
+		return d.Skip()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
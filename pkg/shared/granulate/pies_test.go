@@ -0,0 +1,131 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package granulate_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/granulate"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+const piesDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<PIES xmlns="http://www.autocare.org">
+	<Header>
+		<PIESVersion>7.1</PIESVersion>
+		<SubmissionType>FULL</SubmissionType>
+	</Header>
+	<PriceSheets>
+		<PriceSheet MaintenanceType="A"><PriceSheetNumber>PS-2020</PriceSheetNumber><CurrencyCode>USD</CurrencyCode></PriceSheet>
+	</PriceSheets>
+	<MarketingCopy>
+		<MarketCopy><MarketCopyContent MarketCopyCode="DES">Stops on a dime</MarketCopyContent></MarketCopy>
+		<MarketCopy><MarketCopyContent MarketCopyCode="DES">Stops on a dime</MarketCopyContent></MarketCopy>
+	</MarketingCopy>
+	<Items>
+		<Item MaintenanceType="A"><PartNumber>BB-100</PartNumber><BrandAAIAID>BKDZ</BrandAAIAID></Item>
+		<Item MaintenanceType="A"><PartNumber>BB-200</PartNumber><BrandLabel>Better Brakes</BrandLabel></Item>
+		<Item MaintenanceType="A"><PartNumber>BB-300</PartNumber></Item>
+	</Items>
+	<Trailer>
+		<ItemCount>3</ItemCount>
+	</Trailer>
+</PIES>`
+
+func TestPIES(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      string
+		wantKeys map[string][]string // by slice type
+		wantCopy int                 // marketing copy items (keyed by content hash)
+		wantMeta sandpiper.MetaMap
+		wantErr  bool
+	}{
+		{
+			name: "Complete document",
+			doc:  piesDoc,
+			wantKeys: map[string][]string{
+				"pies-items":      {"BB-100@BKDZ", "BB-200@Better Brakes", "BB-300"},
+				"pies-pricesheet": {"PS-2020"},
+			},
+			wantCopy: 1, // duplicate content is ignored
+			wantMeta: sandpiper.MetaMap{
+				"pies.header.PIESVersion":    "7.1",
+				"pies.header.SubmissionType": "FULL",
+				"pies.trailer.ItemCount":     "3",
+			},
+		},
+		{
+			name:    "Wrong root element",
+			doc:     `<ACES><App id="1"/></ACES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Missing part number",
+			doc:     `<PIES><Items><Item><BrandAAIAID>BKDZ</BrandAAIAID></Item></Items></PIES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate item",
+			doc:     `<PIES><Items><Item><PartNumber>1</PartNumber></Item><Item><PartNumber>1</PartNumber></Item></Items></PIES>`,
+			wantErr: true,
+		},
+		{
+			name:    "Missing price sheet number",
+			doc:     `<PIES><PriceSheets><PriceSheet/></PriceSheets></PIES>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := make(map[string][]string)
+
+			meta, err := granulate.PIES(strings.NewReader(tt.doc), func(item granulate.Item) error {
+				keys[item.SliceType] = append(keys[item.SliceType], item.Key)
+				return nil
+			})
+			if tt.wantErr {
+				var fe *granulate.FormatError
+				assert.True(t, errors.As(err, &fe), "expected a FormatError, got %v", err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantMeta, meta)
+			for sliceType, want := range tt.wantKeys {
+				assert.Equal(t, want, keys[sliceType])
+			}
+			assert.Len(t, keys["pies-marketcopy"], tt.wantCopy)
+			for _, key := range keys["pies-marketcopy"] {
+				assert.Regexp(t, "^mc:[0-9a-f]{16}$", key)
+			}
+		})
+	}
+}
+
+func TestPIESItemData(t *testing.T) {
+	doc := `<PIES><Items><Item MaintenanceType="A"><PartNumber>BB-100</PartNumber><Description>Pads &amp; Shims</Description></Item></Items></PIES>`
+	var data string
+	_, err := granulate.PIES(strings.NewReader(doc), func(item granulate.Item) error {
+		data = string(item.Data)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `<Item MaintenanceType="A"><PartNumber>BB-100</PartNumber><Description>Pads &amp; Shims</Description></Item>`, data)
+}
+
+func TestPIESDefaultNamespace(t *testing.T) {
+	doc := `<PIES xmlns="http://www.autocare.org"><Items><Item><PartNumber>BB-100</PartNumber></Item></Items></PIES>`
+	var data string
+	_, err := granulate.PIES(strings.NewReader(doc), func(item granulate.Item) error {
+		data = string(item.Data)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `<Item xmlns="http://www.autocare.org"><PartNumber>BB-100</PartNumber></Item>`, data)
+}
@@ -0,0 +1,179 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package health reports the liveness and readiness of the server for orchestrators and
+// monitoring. Liveness only shows the process can answer requests, while readiness runs a
+// list of named checks (database, schema, disk, etc.) and fails if any of them fails.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/metrics"
+)
+
+// Status is the result of a check (or of all checks)
+type Status string
+
+// check results (a warning does not make the server unready)
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// checkTimeout limits how long a readiness check can take
+const checkTimeout = 5 * time.Second
+
+// Check is the result of a single readiness check
+type Check struct {
+	Name    string                 `json:"name"`
+	Status  Status                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report is the readiness response
+type Report struct {
+	Status Status  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// summary returns the report with only the name and status of each check
+func (r *Report) summary() *Report {
+	checks := make([]Check, len(r.Checks))
+	for i, chk := range r.Checks {
+		checks[i] = Check{Name: chk.Name, Status: chk.Status}
+	}
+	return &Report{Status: r.Status, Checks: checks}
+}
+
+// CheckFunc performs one check (the name is filled in by the caller)
+type CheckFunc func(ctx context.Context) Check
+
+// Health holds the readiness checks (added before the server starts)
+type Health struct {
+	checks []named
+}
+
+type named struct {
+	name string
+	fn   CheckFunc
+}
+
+// New returns an empty set of readiness checks
+func New() *Health {
+	return &Health{}
+}
+
+// Add registers a readiness check
+func (h *Health) Add(name string, fn CheckFunc) {
+	h.checks = append(h.checks, named{name: name, fn: fn})
+}
+
+// Ready runs every check (in the order added) and returns the combined result
+func (h *Health) Ready(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	rpt := &Report{Status: Pass, Checks: make([]Check, 0, len(h.checks))}
+	for _, c := range h.checks {
+		chk := c.fn(ctx)
+		chk.Name = c.name
+		if chk.Status == "" {
+			chk.Status = Pass
+		}
+		rpt.Checks = append(rpt.Checks, chk)
+		if chk.Status == Fail || (chk.Status == Warn && rpt.Status == Pass) {
+			rpt.Status = chk.Status
+		}
+	}
+	return rpt
+}
+
+// Register adds the liveness (`/live`) and readiness (`/ready`) endpoints. Readiness returns
+// "503 Service Unavailable" if any check fails. The endpoints are public, so only a caller
+// presenting the token (the metrics token) sees the message and details of each check (they
+// name primaries and show the pool and schema version).
+func (h *Health) Register(e *echo.Echo, token string) {
+	e.GET("/live", live)
+	e.GET("/ready", h.ready(token))
+}
+
+func live(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]Status{"status": Pass})
+}
+
+func (h *Health) ready(token string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		rpt := h.Ready(c.Request().Context())
+		if !metrics.Authorized(c, token) {
+			rpt = rpt.summary()
+		}
+		if rpt.Status == Fail {
+			return c.JSON(http.StatusServiceUnavailable, rpt)
+		}
+		return c.JSON(http.StatusOK, rpt)
+	}
+}
+
+// Passed returns a passing check with optional details
+func Passed(details map[string]interface{}) Check {
+	return Check{Status: Pass, Details: details}
+}
+
+// Warned returns a warning check
+func Warned(msg string, details map[string]interface{}) Check {
+	return Check{Status: Warn, Message: msg, Details: details}
+}
+
+// Failed returns a failing check
+func Failed(msg string, details map[string]interface{}) Check {
+	return Check{Status: Fail, Message: msg, Details: details}
+}
+
+// DiskSpace checks the free space of the filesystem holding a directory (or its nearest
+// existing parent, since a directory may only be created when first used)
+func DiskSpace(dir string, minFree uint64) CheckFunc {
+	return func(ctx context.Context) Check {
+		path, err := existingDir(dir)
+		if err != nil {
+			return Failed(err.Error(), map[string]interface{}{"path": dir})
+		}
+		free, err := freeSpace(path)
+		if err != nil {
+			return Failed(err.Error(), map[string]interface{}{"path": path})
+		}
+		details := map[string]interface{}{"path": path, "free_bytes": free, "min_free_bytes": minFree}
+		if free < minFree {
+			return Failed(fmt.Sprintf("only %d MB free", free>>20), details)
+		}
+		return Passed(details)
+	}
+}
+
+// existingDir returns the absolute path of dir or of its nearest existing parent
+func existingDir(dir string) (string, error) {
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("no existing directory for %s", dir)
+		}
+		path = parent
+	}
+}
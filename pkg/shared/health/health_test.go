@@ -0,0 +1,140 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/health"
+)
+
+func TestReady(t *testing.T) {
+	pass := func(context.Context) health.Check { return health.Passed(nil) }
+	warn := func(context.Context) health.Check { return health.Warned("slow", nil) }
+	fail := func(context.Context) health.Check { return health.Failed("down", nil) }
+
+	cases := []struct {
+		name       string
+		checks     []health.CheckFunc
+		wantStatus health.Status
+		wantCode   int
+	}{
+		{
+			name:       "No checks",
+			wantStatus: health.Pass,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "All pass",
+			checks:     []health.CheckFunc{pass, pass},
+			wantStatus: health.Pass,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "Warning is still ready",
+			checks:     []health.CheckFunc{pass, warn},
+			wantStatus: health.Warn,
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "Failure wins",
+			checks:     []health.CheckFunc{fail, warn},
+			wantStatus: health.Fail,
+			wantCode:   http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.New()
+			for i, fn := range tt.checks {
+				h.Add(string(rune('a'+i)), fn)
+			}
+			e := echo.New()
+			h.Register(e, "")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			rpt := new(health.Report)
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), rpt))
+			assert.Equal(t, tt.wantStatus, rpt.Status)
+			assert.Equal(t, len(tt.checks), len(rpt.Checks))
+		})
+	}
+}
+
+func TestReadyDetails(t *testing.T) {
+	h := health.New()
+	h.Add("sync", func(context.Context) health.Check {
+		return health.Warned("no successful sync within 48h0m0s: [Acme]",
+			map[string]interface{}{"primaries": []string{"Acme"}})
+	})
+	e := echo.New()
+	h.Register(e, "s3cret")
+
+	ready := func(auth string) *health.Report {
+		req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+		if auth != "" {
+			req.Header.Set(echo.HeaderAuthorization, auth)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, auth == "Bearer s3cret", strings.Contains(rec.Body.String(), "Acme"))
+		rpt := new(health.Report)
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), rpt))
+		return rpt
+	}
+
+	// without the token only the status of each check is shown
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		rpt := ready(auth)
+		assert.Equal(t, health.Warn, rpt.Status)
+		assert.Equal(t, []health.Check{{Name: "sync", Status: health.Warn}}, rpt.Checks)
+	}
+
+	rpt := ready("Bearer s3cret")
+	if assert.Equal(t, 1, len(rpt.Checks)) {
+		assert.Equal(t, "no successful sync within 48h0m0s: [Acme]", rpt.Checks[0].Message)
+		assert.NotNil(t, rpt.Checks[0].Details)
+	}
+}
+
+func TestLive(t *testing.T) {
+	e := echo.New()
+	health.New().Register(e, "")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/live", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status": "pass"}`, rec.Body.String())
+}
+
+func TestDiskSpace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	missing := filepath.Join(dir, "archive", "not-created-yet")
+
+	chk := health.DiskSpace(missing, 1)(context.Background())
+	assert.Equal(t, health.Pass, chk.Status)
+	assert.Equal(t, dir, chk.Details["path"])
+
+	chk = health.DiskSpace(dir, ^uint64(0))(context.Background())
+	assert.Equal(t, health.Fail, chk.Status)
+}
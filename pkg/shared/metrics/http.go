@@ -0,0 +1,79 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package metrics
+
+// http request metrics and the /metrics endpoint
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+var (
+	httpRequests = Default.Counter("sandpiper_http_requests_total",
+		"HTTP requests by method, route and status code.", "method", "route", "status")
+	httpDuration = Default.Histogram("sandpiper_http_request_duration_seconds",
+		"HTTP request latency by method and route.", DefBuckets, "method", "route")
+)
+
+// Middleware counts and times each request by route (the registered path, e.g. "/v1/slices/:id",
+// so ids don't create new series)
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			begin := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			status := c.Response().Status
+			if err != nil {
+				// the error handler hasn't written the response yet
+				status = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					status = he.Code
+				}
+			}
+			method := c.Request().Method
+			httpRequests.With(method, route, strconv.Itoa(status)).Inc()
+			httpDuration.With(method, route).Observe(time.Since(begin).Seconds())
+			return err
+		}
+	}
+}
+
+// Handler writes the registry in the prometheus text format for a scraper presenting the
+// token (as "Authorization: Bearer <token>"), logging any metric that could not be collected
+func Handler(r *Registry, token string, logger sandpiper.Logger) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !Authorized(c, token) {
+			return echo.ErrUnauthorized
+		}
+		var b bytes.Buffer
+		if err := r.Write(&b); err != nil {
+			// still return the metrics that could be collected
+			logger.Log(c, "metrics", "Collect metrics", err, nil)
+		}
+		return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", b.Bytes())
+	}
+}
+
+// Authorized reports whether a request presents the token (as "Authorization: Bearer <token>").
+// An empty token authorizes nobody.
+func Authorized(c echo.Context, token string) bool {
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	got := strings.TrimPrefix(auth, "Bearer ")
+	return token != "" && got != auth && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
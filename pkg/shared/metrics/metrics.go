@@ -0,0 +1,370 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package metrics collects runtime telemetry and exposes it in the prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/* Metrics are registered once (usually as package variables) and updated by label values.
+ * Values that are cheaper to read when scraped (e.g. pool statistics) use a collect function.
+ *
+ * Usage:
+ *   var syncRuns = metrics.Default.Counter("sandpiper_sync_runs_total", "Sync runs.", "primary")
+ *   syncRuns.With(primaryID.String()).Inc()
+ *
+ * Why not github.com/prometheus/client_golang? The server only needs counters, gauges and
+ * histograms written in the text format (a few hundred lines here). The client library would add
+ * about a dozen modules to go.mod (prometheus/common, procfs, client_model, protobuf,
+ * xxhash, ...), and its current releases need a newer Go than the go 1.14 this module supports. The metric names
+ * and format follow the prometheus conventions, so switching later only changes this package.
+ */
+
+// Default is the registry exposed by the /metrics endpoint
+var Default = NewRegistry()
+
+// DefBuckets are the default histogram upper bounds (in seconds)
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Sample is a single value (with label values) returned by a collect function
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// CollectFunc returns the current samples of a metric when scraped
+type CollectFunc func() ([]Sample, error)
+
+// metric is anything that can be written in the text format
+type metric interface {
+	header() (name, help, kind string)
+	write(w io.Writer) error
+}
+
+// Registry holds metrics by name
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// Counter registers a counter (a value that only goes up) with optional labels
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec: newVec(name, help, labels)}
+	r.register(name, c)
+	return c
+}
+
+// Histogram registers a histogram (observations counted in buckets) with optional labels
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	h := &HistogramVec{vec: newVec(name, help, labels), buckets: b}
+	r.register(name, h)
+	return h
+}
+
+// CounterFunc registers a counter read from fn when scraped
+func (r *Registry) CounterFunc(name, help string, fn CollectFunc, labels ...string) {
+	r.register(name, &funcMetric{vec: newVec(name, help, labels), kind: "counter", fn: fn})
+}
+
+// GaugeFunc registers a gauge (a value that can go up and down) read from fn when scraped
+func (r *Registry) GaugeFunc(name, help string, fn CollectFunc, labels ...string) {
+	r.register(name, &funcMetric{vec: newVec(name, help, labels), kind: "gauge", fn: fn})
+}
+
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.metrics[name] = m
+}
+
+// Write outputs every metric (sorted by name) in the prometheus text format. A collect function
+// that fails is left out (and its error returned after the other metrics are written).
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ms := make([]metric, len(names))
+	for i, name := range names {
+		ms[i] = r.metrics[name]
+	}
+	r.mu.Unlock()
+
+	var failed error
+	for _, m := range ms {
+		name, help, kind := m.header()
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, kind); err != nil {
+			return err
+		}
+		if err := m.write(w); err != nil {
+			if _, ok := err.(collectError); !ok {
+				return err
+			}
+			failed = err
+		}
+	}
+	return failed
+}
+
+// vec holds the values of a metric by label values
+type vec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	keys   []string // sorted label keys (for consistent output)
+}
+
+func newVec(name, help string, labels []string) vec {
+	return vec{name: name, help: help, labels: labels}
+}
+
+// key joins label values (panics if the count is wrong, which is a programming error)
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values (got %d)", v.name, len(v.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// addKey remembers a new label key (keeping them sorted)
+func (v *vec) addKey(key string) {
+	i := sort.SearchStrings(v.keys, key)
+	v.keys = append(v.keys, "")
+	copy(v.keys[i+1:], v.keys[i:])
+	v.keys[i] = key
+}
+
+// labelPairs formats label values (with any extra pair) as {a="x",b="y"}
+func (v *vec) labelPairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(values)+1)
+	for i, val := range values {
+		pairs = append(pairs, v.labels[i]+`="`+escapeLabel(val)+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+escapeLabel(extra[1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a counter by label values
+type CounterVec struct {
+	vec
+	values map[string]*Counter
+}
+
+// Counter is a single counter
+type Counter struct {
+	mu    *sync.Mutex
+	value float64
+}
+
+// With returns the counter for label values (creating it if necessary)
+func (c *CounterVec) With(values ...string) *Counter {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]*Counter)
+	}
+	ctr, ok := c.values[key]
+	if !ok {
+		ctr = &Counter{mu: &c.mu}
+		c.values[key] = ctr
+		c.addKey(key)
+	}
+	return ctr
+}
+
+// Inc adds one to a counter
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increases a counter (ignoring negative values)
+func (c *Counter) Add(n float64) {
+	if n <= 0 {
+		return
+	}
+	c.mu.Lock()
+	c.value += n
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) header() (string, string, string) {
+	return c.name, c.help, "counter"
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range c.keys {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(split(key, len(c.labels))), formatValue(c.values[key].value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistogramVec is a histogram by label values
+type HistogramVec struct {
+	vec
+	buckets []float64
+	values  map[string]*Histogram
+}
+
+// Histogram is a single histogram
+type Histogram struct {
+	mu      *sync.Mutex
+	buckets []float64
+	counts  []uint64 // by bucket (not cumulative)
+	sum     float64
+	count   uint64
+}
+
+// With returns the histogram for label values (creating it if necessary)
+func (h *HistogramVec) With(values ...string) *Histogram {
+	key := h.key(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.values == nil {
+		h.values = make(map[string]*Histogram)
+	}
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &Histogram{mu: &h.mu, buckets: h.buckets, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hist
+		h.addKey(key)
+	}
+	return hist
+}
+
+// Observe adds a value to a histogram
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v) // first bucket with an upper bound >= v
+	h.mu.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+func (h *HistogramVec) header() (string, string, string) {
+	return h.name, h.help, "histogram"
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range h.keys {
+		values := split(key, len(h.labels))
+		hist := h.values[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += hist.counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(values, "le", formatValue(le)), cumulative); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, h.labelPairs(values, "le", "+Inf"), hist.count,
+			h.name, h.labelPairs(values), formatValue(hist.sum),
+			h.name, h.labelPairs(values), hist.count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// funcMetric is read from a collect function when scraped
+type funcMetric struct {
+	vec
+	kind string
+	fn   CollectFunc
+}
+
+// collectError is a collect function failure (which doesn't stop other metrics being written)
+type collectError struct {
+	name string
+	err  error
+}
+
+func (e collectError) Error() string {
+	return e.name + ": " + e.err.Error()
+}
+
+func (f *funcMetric) header() (string, string, string) {
+	return f.name, f.help, f.kind
+}
+
+func (f *funcMetric) write(w io.Writer) error {
+	samples, err := f.fn()
+	if err != nil {
+		return collectError{name: f.name, err: err}
+	}
+	for _, s := range samples {
+		f.key(s.Labels) // check the label count
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", f.name, f.labelPairs(s.Labels), formatValue(s.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// split separates joined label values
+func split(key string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	return strings.Split(key, "\xff")
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,128 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/metrics"
+)
+
+func TestWrite(t *testing.T) {
+	r := metrics.NewRegistry()
+	runs := r.Counter("test_runs_total", "Runs.", "primary")
+	runs.With("b").Inc()
+	runs.With("a").Add(2)
+	runs.With("a").Add(-1) // ignored
+	dur := r.Histogram("test_duration_seconds", "Duration.", []float64{1, 0.1})
+	dur.With().Observe(0.05)
+	dur.With().Observe(0.5)
+	dur.With().Observe(5)
+	r.GaugeFunc("test_bytes", "Bytes\nstored.", func() ([]metrics.Sample, error) {
+		return []metrics.Sample{{Labels: []string{`x"y`}, Value: 10}}, nil
+	}, "slice")
+
+	var b bytes.Buffer
+	assert.Nil(t, r.Write(&b))
+	want := `# HELP test_bytes Bytes\nstored.
+# TYPE test_bytes gauge
+test_bytes{slice="x\"y"} 10
+# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 1
+test_duration_seconds_bucket{le="1"} 2
+test_duration_seconds_bucket{le="+Inf"} 3
+test_duration_seconds_sum 5.55
+test_duration_seconds_count 3
+# HELP test_runs_total Runs.
+# TYPE test_runs_total counter
+test_runs_total{primary="a"} 2
+test_runs_total{primary="b"} 1
+`
+	assert.Equal(t, want, b.String())
+}
+
+func TestWriteCollectError(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.GaugeFunc("test_fails", "Fails.", func() ([]metrics.Sample, error) {
+		return nil, errors.New("no database")
+	})
+	r.Counter("test_total", "Total.").With().Inc()
+
+	var b bytes.Buffer
+	assert.NotNil(t, r.Write(&b))
+	assert.Contains(t, b.String(), "test_total 1\n")
+}
+
+func TestHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		auth     string
+		wantCode int
+	}{
+		{name: "Fail without token", wantCode: http.StatusUnauthorized},
+		{name: "Fail on wrong token", auth: "Bearer wrong", wantCode: http.StatusUnauthorized},
+		{name: "Success", auth: "Bearer secret", wantCode: http.StatusOK},
+	}
+	r := metrics.NewRegistry()
+	r.Counter("test_total", "Total.").With().Inc()
+	e := echo.New()
+	e.GET("/metrics", metrics.Handler(r, "secret", &logger{}))
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.auth != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Contains(t, rec.Body.String(), "test_total 1\n")
+			}
+		})
+	}
+}
+
+// logger records the errors logged
+type logger struct {
+	errs []error
+}
+
+func (l *logger) Log(_ echo.Context, _, _ string, err error, _ map[string]interface{}) {
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+}
+
+func TestHandlerCollectError(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.Counter("test_total", "Total.").With().Inc()
+	r.GaugeFunc("test_bytes", "Bytes.", func() ([]metrics.Sample, error) {
+		return nil, errors.New("no database")
+	})
+	log := &logger{}
+	e := echo.New()
+	e.GET("/metrics", metrics.Handler(r, "secret", log))
+
+	// the other metrics are still returned (and the failure logged)
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "test_total 1\n")
+	if assert.Equal(t, 1, len(log.errs)) {
+		assert.Contains(t, log.errs[0].Error(), "no database")
+	}
+}
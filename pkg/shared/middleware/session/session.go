@@ -0,0 +1,41 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package session contains middleware for correlating the requests of a sync session.
+package session
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// Middleware saves a valid sync session id sent by a secondary server in the request context
+// (where it is picked up by the activity service and the service logger). Invalid ids are ignored.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if h := c.Request().Header.Get(sandpiper.SyncSessionHeader); h != "" {
+				if id, err := uuid.Parse(h); err == nil {
+					Set(c, id)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// Set saves the sync session id in the request context
+func Set(c echo.Context, id uuid.UUID) {
+	c.Set(sandpiper.SyncSessionKey, id)
+}
+
+// ID returns the sync session id of the request (or uuid.Nil if not part of a sync)
+func ID(c echo.Context) uuid.UUID {
+	if id, ok := c.Get(sandpiper.SyncSessionKey).(uuid.UUID); ok {
+		return id
+	}
+	return uuid.Nil
+}
@@ -0,0 +1,59 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/middleware/session"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+func TestMiddleware(t *testing.T) {
+	id := uuid.New()
+	cases := []struct {
+		name   string
+		header string
+		want   uuid.UUID
+	}{
+		{
+			name: "No header",
+			want: uuid.Nil,
+		},
+		{
+			name:   "Valid session",
+			header: id.String(),
+			want:   id,
+		},
+		{
+			name:   "Invalid session",
+			header: "not-a-uuid",
+			want:   uuid.Nil,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uuid.UUID
+			e := echo.New()
+			e.Use(session.Middleware())
+			e.GET("/", func(c echo.Context) error {
+				got = session.ID(c)
+				return c.NoContent(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(sandpiper.SyncSessionHeader, tt.header)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package mockdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/go-pg/pg/v9/types"
+)
+
+// Row is a result row by column name. Values are returned in postgres text format
+// (nil is NULL).
+type Row map[string]interface{}
+
+// DB is an orm.DB that records the SQL it is given and answers with canned rows
+// (instead of a database connection). Queries without an answer return no rows.
+type DB struct {
+	mu      sync.Mutex
+	SQL     []string
+	answers []answer
+}
+
+type answer struct {
+	match string
+	rows  []Row
+	err   error
+}
+
+// On answers queries containing match with rows (the latest matching answer wins).
+// Statements without RETURNING report the number of rows as rows affected.
+func (db *DB) On(match string, rows ...Row) *DB {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.answers = append(db.answers, answer{match: match, rows: rows})
+	return db
+}
+
+// Fail answers queries containing match with err
+func (db *DB) Fail(match string, err error) *DB {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.answers = append(db.answers, answer{match: match, err: err})
+	return db
+}
+
+// Find returns the recorded statements containing match
+func (db *DB) Find(match string) []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var found []string
+	for _, s := range db.SQL {
+		if strings.Contains(s, match) {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+// Model mock
+func (db *DB) Model(model ...interface{}) *orm.Query {
+	return orm.NewQuery(db, model...)
+}
+
+// ModelContext mock
+func (db *DB) ModelContext(c context.Context, model ...interface{}) *orm.Query {
+	return orm.NewQueryContext(c, db, model...)
+}
+
+// Select mock
+func (db *DB) Select(model interface{}) error {
+	return orm.Select(db, model)
+}
+
+// Insert mock
+func (db *DB) Insert(model ...interface{}) error {
+	return orm.Insert(db, model...)
+}
+
+// Update mock
+func (db *DB) Update(model interface{}) error {
+	return orm.Update(db, model)
+}
+
+// Delete mock
+func (db *DB) Delete(model interface{}) error {
+	return orm.Delete(db, model)
+}
+
+// ForceDelete mock
+func (db *DB) ForceDelete(model interface{}) error {
+	return orm.ForceDelete(db, model)
+}
+
+// Exec mock
+func (db *DB) Exec(query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.QueryContext(context.Background(), nil, query, params...)
+}
+
+// ExecContext mock
+func (db *DB) ExecContext(c context.Context, query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.QueryContext(c, nil, query, params...)
+}
+
+// ExecOne mock
+func (db *DB) ExecOne(query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.QueryOneContext(context.Background(), nil, query, params...)
+}
+
+// ExecOneContext mock
+func (db *DB) ExecOneContext(c context.Context, query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.QueryOneContext(c, nil, query, params...)
+}
+
+// Query mock
+func (db *DB) Query(model, query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.QueryContext(context.Background(), model, query, params...)
+}
+
+// QueryOne mock
+func (db *DB) QueryOne(model, query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.QueryOneContext(context.Background(), model, query, params...)
+}
+
+// QueryOneContext mock
+func (db *DB) QueryOneContext(c context.Context, model, query interface{}, params ...interface{}) (orm.Result, error) {
+	res, err := db.QueryContext(c, model, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	switch n := res.RowsAffected(); {
+	case n == 0:
+		return nil, pg.ErrNoRows
+	case n > 1:
+		return nil, pg.ErrMultiRows
+	}
+	return res, nil
+}
+
+// QueryContext formats and records the query, then scans the canned rows into model
+func (db *DB) QueryContext(c context.Context, model, query interface{}, params ...interface{}) (orm.Result, error) {
+	sql, err := db.format(query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	db.SQL = append(db.SQL, sql)
+	var ans answer
+	for i := len(db.answers) - 1; i >= 0; i-- {
+		if strings.Contains(sql, db.answers[i].match) {
+			ans = db.answers[i]
+			break
+		}
+	}
+	db.mu.Unlock()
+
+	if ans.err != nil {
+		return nil, ans.err
+	}
+	res := &result{affected: len(ans.rows)}
+	if len(ans.rows) == 0 || model == nil {
+		return res, nil
+	}
+	if res.model, err = orm.NewModel(model); err != nil {
+		return nil, err
+	}
+	if err := res.model.Init(); err != nil {
+		return nil, err
+	}
+	for _, row := range ans.rows {
+		if err := scanRow(res.model, row); err != nil {
+			return nil, err
+		}
+	}
+	res.returned = len(ans.rows)
+	return res, nil
+}
+
+// CopyFrom mock
+func (db *DB) CopyFrom(r io.Reader, query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.Exec(query, params...)
+}
+
+// CopyTo mock
+func (db *DB) CopyTo(w io.Writer, query interface{}, params ...interface{}) (orm.Result, error) {
+	return db.Exec(query, params...)
+}
+
+// Context mock
+func (db *DB) Context() context.Context {
+	return context.Background()
+}
+
+// Formatter mock
+func (db *DB) Formatter() orm.QueryFormatter {
+	return orm.NewFormatter()
+}
+
+// format renders the query the way pg.DB sends it
+func (db *DB) format(query interface{}, params ...interface{}) (string, error) {
+	fmter := orm.NewFormatter()
+	switch q := query.(type) {
+	case orm.QueryAppender:
+		b, err := q.AppendQuery(fmter.WithModel(q), nil)
+		return string(b), err
+	case string:
+		if len(params) > 0 {
+			if m, ok := params[len(params)-1].(orm.TableModel); ok {
+				return string(fmter.WithTableModel(m).FormatQuery(nil, q, params[:len(params)-1]...)), nil
+			}
+		}
+		return string(fmter.FormatQuery(nil, q, params...)), nil
+	}
+	return "", fmt.Errorf("mockdb: can't format %T", query)
+}
+
+// scanRow scans one row (columns in name order) into model
+func scanRow(model orm.Model, row Row) error {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scanner := model.NextColumnScanner()
+	for i, name := range names {
+		b, null := text(row[name])
+		n := len(b)
+		if null {
+			n = -1
+		}
+		if err := scanner.ScanColumn(i, name, types.NewBytesReader(b), n); err != nil {
+			return fmt.Errorf("mockdb: scan %s: %v", name, err)
+		}
+	}
+	return model.AddColumnScanner(scanner)
+}
+
+// text returns v in postgres text format
+func text(v interface{}) ([]byte, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []byte(v), false
+	case []byte:
+		return v, false
+	case bool:
+		if v {
+			return []byte("t"), false
+		}
+		return []byte("f"), false
+	case int:
+		return []byte(strconv.Itoa(v)), false
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), false
+	case time.Time:
+		return []byte(v.Format(time.RFC3339Nano)), false
+	case fmt.Stringer:
+		return []byte(v.String()), false
+	}
+	return []byte(fmt.Sprint(v)), false
+}
+
+type result struct {
+	model    orm.Model
+	affected int
+	returned int
+}
+
+func (r *result) Model() orm.Model  { return r.model }
+func (r *result) RowsAffected() int { return r.affected }
+func (r *result) RowsReturned() int { return r.returned }
@@ -12,12 +12,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// SyncSessionHeader carries the id of a sync session on every request the secondary sends to
+// the primary, so activity (and log entries) on both servers can be tied to the same sync
+const SyncSessionHeader = "X-Sync-Session"
+
+// SyncSessionKey is the echo context key (and log field) for the sync session id
+const SyncSessionKey = "sync_session"
+
 // Activity logs for sync requests
 type Activity struct {
 	tableName    struct{}      `pg:"activity"` // we don't want the plural `activities`
 	ID           int           `json:"id" pg:",pk"`
 	CompanyID    uuid.UUID     `json:"company_id"`
 	SubID        uuid.UUID     `json:"sub_id"`
+	SessionID    uuid.UUID     `json:"session_id"` // sync session (see SyncSessionHeader)
 	Success      bool          `json:"success" pg:",use_zero"`
 	Message      string        `json:"message"`
 	Error        string        `json:"error"`
@@ -40,3 +48,44 @@ type ActivityPaginated struct {
 	Activity []Activity  `json:"data"`
 	Paging   *Pagination `json:"paging"`
 }
+
+// ActivityReport summarizes sync activity for account managers (daily results are limited to
+// the report period, the rest covers all recorded activity)
+type ActivityReport struct {
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"` // inclusive
+	Daily       []ActivityDay   `json:"daily"`
+	NeverSynced []UnsyncedSlice `json:"never_synced"`
+	LastSyncs   []LastSync      `json:"last_syncs"`
+}
+
+// ActivityDay is the sync success rate and average duration for a company on one day
+type ActivityDay struct {
+	CompanyID   uuid.UUID     `json:"company_id"`
+	CompanyName string        `json:"company_name"`
+	Day         time.Time     `json:"day"`
+	Syncs       int           `json:"syncs"`
+	Successes   int           `json:"successes"`
+	SuccessRate float64       `json:"success_rate"` // 0 to 1
+	AvgDuration time.Duration `json:"avg_duration"`
+}
+
+// UnsyncedSlice is a slice without a successful sync for any of its subscriptions
+type UnsyncedSlice struct {
+	SliceID       uuid.UUID `json:"slice_id"`
+	SliceName     string    `json:"slice_name"`
+	Subscriptions int       `json:"subscriptions"`
+}
+
+// LastSync is the most recent sync attempt and success for a subscription
+type LastSync struct {
+	SubID       uuid.UUID  `json:"sub_id"`
+	SubName     string     `json:"sub_name"`
+	CompanyID   uuid.UUID  `json:"company_id"`
+	CompanyName string     `json:"company_name"`
+	SliceID     uuid.UUID  `json:"slice_id"`
+	SliceName   string     `json:"slice_name"`
+	Active      bool       `json:"active"`
+	LastAttempt *time.Time `json:"last_attempt"` // nil if never attempted
+	LastSuccess *time.Time `json:"last_success"` // nil if never successful
+}
@@ -0,0 +1,104 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+)
+
+// Alert rules
+const (
+	AlertSyncFailure         = "sync_failure"          // the latest sync failed
+	AlertConsecutiveFailures = "consecutive_failures"  // the last few syncs all failed
+	AlertStaleSlice          = "stale_slice"           // a subscribed slice has not synced successfully for days
+	AlertExpiringSub         = "expiring_subscription" // a subscription ends soon
+)
+
+// AlertRules lists every alert rule
+var AlertRules = []string{AlertSyncFailure, AlertConsecutiveFailures, AlertStaleSlice, AlertExpiringSub}
+
+// Alert delivery channels
+const (
+	AlertEmail   = "email"
+	AlertWebhook = "webhook"
+)
+
+// Alert notice events
+const (
+	AlertOpened   = "alert"
+	AlertResolved = "resolved"
+)
+
+// Alert is a condition found by an alert rule. Only one alert is open for each rule and key, so
+// recipients are notified once when it opens (optionally repeating) and once when it resolves.
+type Alert struct {
+	ID            int       `json:"id" pg:",pk"`
+	Rule          string    `json:"rule"`
+	Key           string    `json:"key" pg:"alert_key"` // what the alert is about (e.g. a subscription id)
+	CompanyID     uuid.UUID `json:"company_id"`         // company whose recipients are notified
+	Title         string    `json:"title"`
+	Detail        string    `json:"detail"`
+	OpenedAt      time.Time `json:"opened_at"`
+	NotifiedAt    time.Time `json:"notified_at"`
+	ResolvedAt    time.Time `json:"resolved_at"` // zero while open
+	DeliveryError string    `json:"delivery_error,omitempty"`
+}
+
+// AlertPaginated defines the list response
+type AlertPaginated struct {
+	Alerts []Alert     `json:"data"`
+	Paging *Pagination `json:"paging"`
+}
+
+// AlertRecipient receives the alerts about a company by email or webhook (the recipients of our
+// own company receive every alert)
+type AlertRecipient struct {
+	ID        uuid.UUID `json:"id" pg:",pk"`
+	CompanyID uuid.UUID `json:"company_id"`
+	Channel   string    `json:"channel"`           // email or webhook
+	Address   string    `json:"address"`           // email address or webhook url
+	Rules     []string  `json:"rules" pg:",array"` // empty for all rules
+	Active    bool      `json:"active" pg:",use_zero"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// compile-time check variables for model hooks (which take no memory)
+var _ orm.BeforeInsertHook = (*AlertRecipient)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt to current time
+func (b *AlertRecipient) BeforeInsert(ctx context.Context) (context.Context, error) {
+	b.CreatedAt = time.Now()
+	return ctx, nil
+}
+
+// AlertRecipientsPaginated defines the list response
+type AlertRecipientsPaginated struct {
+	Recipients []AlertRecipient `json:"data"`
+	Paging     *Pagination      `json:"paging"`
+}
+
+// Wants returns true if the recipient receives alerts for a rule
+func (b *AlertRecipient) Wants(rule string) bool {
+	if len(b.Rules) == 0 {
+		return true
+	}
+	for _, r := range b.Rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertNotice is the webhook body sent when an alert opens (or repeats) and when it resolves
+type AlertNotice struct {
+	Event    string    `json:"event"` // alert or resolved
+	ServerID uuid.UUID `json:"server_id"`
+	Alert    Alert     `json:"alert"`
+}
@@ -0,0 +1,51 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+)
+
+// Audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records who made an administrative change (with the resource before and after)
+type AuditEntry struct {
+	tableName  struct{}               `pg:"audit_log"`
+	ID         int                    `json:"id" pg:",pk"`
+	UserID     int                    `json:"user_id"`
+	Username   string                 `json:"username"`
+	CompanyID  uuid.UUID              `json:"company_id"`
+	Action     string                 `json:"action"`   // create, update or delete
+	Resource   string                 `json:"resource"` // e.g. company, subscription
+	ResourceID string                 `json:"resource_id"`
+	Before     map[string]interface{} `json:"before"` // nil for a create
+	After      map[string]interface{} `json:"after"`  // nil for a delete
+	SourceIP   string                 `json:"source_ip"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// compile-time check variables for model hooks (which take no memory)
+var _ orm.BeforeInsertHook = (*AuditEntry)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt to current time
+func (b *AuditEntry) BeforeInsert(ctx context.Context) (context.Context, error) {
+	b.CreatedAt = time.Now()
+	return ctx, nil
+}
+
+// AuditPaginated defines the list response
+type AuditPaginated struct {
+	Entries []AuditEntry `json:"data"`
+	Paging  *Pagination  `json:"paging"`
+}
@@ -29,6 +29,7 @@ type Grain struct {
 	Encoding   string              `json:"encoding"`
 	PayloadLen int                 `json:"payload_len" pg:"-"` // calculated: "length(payload) AS payload_len"
 	Payload    payload.PayloadData `json:"payload,omitempty"`
+	KeyID      int                 `json:"-"` // slice data key used for encryption at rest (0 if not encrypted)
 	CreatedAt  time.Time           `json:"created_at"`
 	Slice      *Slice              `json:"slice,omitempty"` // has-one relation
 }
@@ -77,4 +78,44 @@ func (g *Grain) DisplayFull() string {
 type GrainsPaginated struct {
 	Grains []Grain     `json:"data"`
 	Paging *Pagination `json:"paging"`
+	Next   string      `json:"next,omitempty"` // "after" key for the next page (keyset pagination only)
+}
+
+// GrainDigest identifies a grain without its payload, including an md5 hash of the payload (as
+// stored) so a client can find changed content without downloading it
+type GrainDigest struct {
+	ID     uuid.UUID `json:"id"`
+	Key    string    `json:"grain_key" pg:"grain_key"`
+	Source string    `json:"source"`
+	Hash   string    `json:"hash"`
+	KeyID  int       `json:"-"` // encrypted at rest (hash is of the decrypted payload)
+}
+
+// GrainImport reports the results of a bulk grain import (newline-delimited json) into a slice
+type GrainImport struct {
+	SliceID  uuid.UUID          `json:"slice_id"`
+	Replaced bool               `json:"replaced"` // existing grains were removed first
+	Lines    int                `json:"lines"`    // grains read (ignoring blank lines)
+	Added    int                `json:"added"`
+	Failed   int                `json:"failed"`
+	Errors   []GrainImportError `json:"errors,omitempty"` // possibly truncated (see Failed)
+}
+
+// GrainImportError identifies a problem with one line of a bulk import
+type GrainImportError struct {
+	Line  int    `json:"line"`
+	Key   string `json:"grain_key,omitempty"`
+	Error string `json:"error"`
+}
+
+// Granulation reports the results of splitting a document into item-level grains for a slice.
+// Only changed items are added (or removed), so unchanged grains keep their original ids.
+type Granulation struct {
+	SliceID   uuid.UUID `json:"slice_id"`
+	SliceType string    `json:"slice_type"`
+	Source    string    `json:"source"`
+	Items     int       `json:"items"`     // items found in the document
+	Added     int       `json:"added"`     // new or changed items
+	Deleted   int       `json:"deleted"`   // changed items or items no longer in the document
+	Unchanged int       `json:"unchanged"` // items already in the slice
 }
@@ -0,0 +1,29 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"time"
+)
+
+// PurgeRun records one purge of a table (its old rows archived and then deleted)
+type PurgeRun struct {
+	ID          int       `json:"id" pg:",pk"`
+	Target      string    `json:"table"`  // table purged
+	Cutoff      time.Time `json:"cutoff"` // rows created before this were purged
+	Archived    int       `json:"archived" pg:",use_zero"`
+	Deleted     int       `json:"deleted" pg:",use_zero"`
+	ArchiveFile string    `json:"archive_file"` // compressed json lines (none if nothing was purged)
+	Error       string    `json:"error,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+}
+
+// PurgeStatus shows the retention policies and the last purge of each table
+type PurgeStatus struct {
+	Policies map[string]int `json:"policies"` // days kept by table
+	Interval string         `json:"interval"` // how often the purge runs
+	LastRuns []PurgeRun     `json:"last_runs"`
+}
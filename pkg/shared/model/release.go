@@ -0,0 +1,85 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
+)
+
+// LatestRelease is the version label used for a slice's current content
+const LatestRelease = "latest"
+
+// SliceRelease is a named snapshot (by version label) of a slice's grains and metadata.
+// Subscriptions can be pinned to a release instead of tracking the latest content.
+type SliceRelease struct {
+	ID           uuid.UUID `json:"id" pg:",pk"`
+	SliceID      uuid.UUID `json:"slice_id"`
+	Version      string    `json:"version"`
+	Description  string    `json:"description"`
+	ContentHash  string    `json:"content_hash"`
+	ContentCount int       `json:"content_count" pg:",use_zero"`
+	ContentDate  time.Time `json:"content_date"`
+	Metadata     MetaMap   `json:"metadata"` // frozen slice metadata (stored as jsonb)
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+var _ orm.BeforeInsertHook = (*SliceRelease)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt
+func (r *SliceRelease) BeforeInsert(ctx context.Context) (context.Context, error) {
+	r.CreatedAt = time.Now()
+	return ctx, nil
+}
+
+// ReleaseGrain is a frozen copy of a grain (as stored) included in a release
+type ReleaseGrain struct {
+	ReleaseID uuid.UUID           `pg:",pk"`
+	GrainID   uuid.UUID           `pg:",pk"`
+	Key       string              `pg:"grain_key"`
+	Source    string              `pg:"source"`
+	Encoding  string              `pg:"encoding"`
+	Payload   payload.PayloadData `pg:"payload"`
+	KeyID     int                 `pg:"key_id"`
+	CreatedAt time.Time           `pg:"created_at"`
+}
+
+// Grain returns the release grain as a grain of a slice
+func (rg *ReleaseGrain) Grain(sliceID uuid.UUID) Grain {
+	return Grain{
+		ID:         rg.GrainID,
+		SliceID:    &sliceID,
+		Key:        rg.Key,
+		Source:     rg.Source,
+		Encoding:   rg.Encoding,
+		PayloadLen: len(rg.Payload),
+		Payload:    rg.Payload,
+		KeyID:      rg.KeyID,
+		CreatedAt:  rg.CreatedAt,
+	}
+}
+
+// ReleaseDiff compares the content of two releases of a slice (by grain key)
+type ReleaseDiff struct {
+	SliceID     uuid.UUID `json:"slice_id"`
+	From        string    `json:"from"` // version
+	To          string    `json:"to"`   // version (or "latest")
+	Added       []string  `json:"added"`
+	Removed     []string  `json:"removed"`
+	Changed     []string  `json:"changed"` // same key with a different grain
+	Unchanged   int       `json:"unchanged"`
+	MetaChanged bool      `json:"metadata_changed"`
+}
+
+// ReleasesPaginated defines the list response
+type ReleasesPaginated struct {
+	Releases []SliceRelease `json:"data"`
+	Paging   *Pagination    `json:"paging"`
+}
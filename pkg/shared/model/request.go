@@ -0,0 +1,73 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+)
+
+// Subscription request status values
+const (
+	RequestPending  = "pending"
+	RequestApproved = "approved"
+	RequestRejected = "rejected"
+)
+
+// SubscriptionRequest is a request (from a prospective or existing secondary) for access to slices
+// on a primary server. Approval creates the company, its sync user and the subscriptions.
+type SubscriptionRequest struct {
+	ID           uuid.UUID   `json:"id" pg:",pk"`
+	CompanyID    *uuid.UUID  `json:"company_id,omitempty"` // existing (or approved) company
+	CompanyName  string      `json:"company_name"`
+	ContactName  string      `json:"contact_name"`
+	Email        string      `json:"email"`
+	ServerID     uuid.UUID   `json:"server_id"` // requesting secondary server (becomes the company id)
+	SyncAddr     string      `json:"sync_addr"`
+	Kind         string      `json:"kind"` // e.g. distributor, retailer
+	SliceIDs     []uuid.UUID `json:"slice_ids" pg:"slice_ids,array"`
+	Message      string      `json:"message"`
+	Status       string      `json:"status"`
+	DecidedBy    int         `json:"decided_by,omitempty"` // user id
+	DecidedAt    time.Time   `json:"decided_at"`
+	DecisionNote string      `json:"decision_note"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// compile-time check variables for model hooks (which take no memory)
+var _ orm.BeforeInsertHook = (*SubscriptionRequest)(nil)
+var _ orm.BeforeUpdateHook = (*SubscriptionRequest)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt and updatedAt to current time
+func (r *SubscriptionRequest) BeforeInsert(ctx context.Context) (context.Context, error) {
+	now := time.Now()
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	return ctx, nil
+}
+
+// BeforeUpdate hooks into update operations, setting updatedAt to current time
+func (r *SubscriptionRequest) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	r.UpdatedAt = time.Now()
+	return ctx, nil
+}
+
+// RequestDecision is the result of approving a subscription request. The api key is only
+// included if a sync user was created (and is never shown again).
+type RequestDecision struct {
+	Request       *SubscriptionRequest `json:"request"`
+	Subscriptions []Subscription       `json:"subscriptions"`
+	APIKey        *APIKey              `json:"api_key,omitempty"`
+}
+
+// RequestsPaginated adds pagination
+type RequestsPaginated struct {
+	Requests []SubscriptionRequest `json:"data"`
+	Paging   *Pagination           `json:"paging"`
+}
@@ -0,0 +1,77 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+)
+
+// SubscriptionRule subscribes a company to every slice with a tag. Subscriptions are created
+// (or retired) automatically as slices are tagged (or untagged) while the rule is active.
+type SubscriptionRule struct {
+	ID          uuid.UUID `json:"id" pg:",pk"`
+	CompanyID   uuid.UUID `json:"company_id"`
+	TagID       int       `json:"tag_id"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active" pg:",use_zero"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Company     *Company  `json:"company,omitempty"`
+	Tag         *Tag      `json:"tag,omitempty"`
+}
+
+// compile-time check variables for model hooks (which take no memory)
+var _ orm.BeforeInsertHook = (*SubscriptionRule)(nil)
+var _ orm.BeforeUpdateHook = (*SubscriptionRule)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt and updatedAt to current time
+func (r *SubscriptionRule) BeforeInsert(ctx context.Context) (context.Context, error) {
+	now := time.Now()
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	return ctx, nil
+}
+
+// BeforeUpdate hooks into update operations, setting updatedAt to current time
+func (r *SubscriptionRule) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	r.UpdatedAt = time.Now()
+	return ctx, nil
+}
+
+// RuleChange is a subscription created, reactivated or retired by subscription rules
+type RuleChange struct {
+	SubID       *uuid.UUID `json:"sub_id,omitempty"` // nil if not created yet
+	RuleID      *uuid.UUID `json:"rule_id,omitempty"`
+	CompanyID   uuid.UUID  `json:"company_id"`
+	CompanyName string     `json:"company_name"`
+	SliceID     uuid.UUID  `json:"slice_id"`
+	SliceName   string     `json:"slice_name"`
+
+	// audit snapshots of the subscription (Before is nil for a create)
+	Before *Subscription `json:"-"`
+	After  *Subscription `json:"-"`
+}
+
+// RuleEffect lists the subscription changes made (or previewed) by subscription rules
+type RuleEffect struct {
+	Create     []RuleChange `json:"create"`
+	Reactivate []RuleChange `json:"reactivate"`
+	Retire     []RuleChange `json:"retire"`
+}
+
+// Empty checks if there are no subscription changes
+func (e *RuleEffect) Empty() bool {
+	return len(e.Create) == 0 && len(e.Reactivate) == 0 && len(e.Retire) == 0
+}
+
+// RulesPaginated adds pagination
+type RulesPaginated struct {
+	Rules  []SubscriptionRule `json:"data"`
+	Paging *Pagination        `json:"paging"`
+}
@@ -0,0 +1,32 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"github.com/google/uuid"
+)
+
+// SearchResult is a grain matching a full-text search (best matches first)
+type SearchResult struct {
+	GrainID   uuid.UUID `json:"grain_id"`
+	SliceID   uuid.UUID `json:"slice_id"`
+	SliceName string    `json:"slice_name"`
+	GrainKey  string    `json:"grain_key"`
+	Source    string    `json:"source"`
+	Rank      float32   `json:"rank"`
+}
+
+// SearchReindex reports the grains indexed again (e.g. after an upgrade)
+type SearchReindex struct {
+	SliceID uuid.UUID `json:"slice_id,omitempty"` // all slices if not provided
+	Grains  int       `json:"grains"`
+}
+
+// SearchPaginated defines the search response
+type SearchPaginated struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"data"`
+	Paging  *Pagination    `json:"paging"`
+}
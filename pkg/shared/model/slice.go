@@ -6,6 +6,7 @@ package sandpiper
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/go-pg/pg/v9/orm"
@@ -32,6 +33,8 @@ type Slice struct {
 	ContentCount    int        `json:"content_count"`
 	ContentDate     time.Time  `json:"content_date"`
 	AllowSync       bool       `json:"allow_sync"`
+	Encrypted       bool       `json:"encrypted" pg:",use_zero"` // payloads encrypted at rest
+	Composite       bool       `json:"composite" pg:",use_zero"` // grains are the union of member slices
 	SyncStatus      string     `json:"sync_status"`
 	LastSyncAttempt time.Time  `json:"last_sync_attempt"`
 	LastGoodSync    time.Time  `json:"last_good_sync"`
@@ -39,6 +42,7 @@ type Slice struct {
 	UpdatedAt       time.Time  `json:"updated_at"`
 	Metadata        MetaMap    `json:"metadata,omitempty" pg:"-"`
 	Companies       []*Company `json:"companies,omitempty" pg:"many2many:subscriptions"`
+	Members         []Slice    `json:"members,omitempty" pg:"-"` // only for composite slices
 }
 
 // compile-time check variables for model hooks (which take no memory)
@@ -59,19 +63,12 @@ func (s *Slice) BeforeUpdate(ctx context.Context) (context.Context, error) {
 	return ctx, nil
 }
 
-// Validate checks slice_type enum
-func (s Slice) Validate() bool {
-	// using `select enum_range(null::slice_type_enum)`
-	switch s.SliceType {
-	case "aces-file",
-		"aces-items",
-		"asset-files",
-		"pies-file",
-		"pies-items",
-		"pies-marketcopy",
-		"pies-pricesheet",
-		"partspro-file":
-		return true
+// Validate checks slice_type against the registered slice types
+func (s Slice) Validate(types []SliceType) bool {
+	for _, t := range types {
+		if t.Name == s.SliceType {
+			return true
+		}
 	}
 	return false
 }
@@ -83,6 +80,38 @@ type SliceMetadata struct {
 	Value   string    `json:"val"`
 }
 
+// SliceMember assigns a (regular) slice to a composite slice
+type SliceMember struct {
+	CompositeID uuid.UUID `json:"composite_id" pg:",pk"`
+	MemberID    uuid.UUID `json:"member_id" pg:",pk"`
+}
+
+// SliceKey is a data key (wrapped by the server master key) used to encrypt grain payloads
+// for a slice. Only the active key encrypts new payloads; others remain until re-encrypted.
+type SliceKey struct {
+	ID        int       `json:"id"`
+	SliceID   uuid.UUID `json:"slice_id"`
+	DataKey   string    `json:"-"` // wrapped (never the plain key)
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var _ orm.BeforeInsertHook = (*SliceKey)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt
+func (k *SliceKey) BeforeInsert(ctx context.Context) (context.Context, error) {
+	k.CreatedAt = time.Now()
+	return ctx, nil
+}
+
+// SliceKeyRotation reports the results of re-encrypting a slice's grains
+type SliceKeyRotation struct {
+	SliceID   uuid.UUID `json:"slice_id"`
+	Encrypted bool      `json:"encrypted"`
+	KeyID     int       `json:"key_id,omitempty"` // active data key (if encrypted)
+	Grains    int       `json:"grains"`           // grains re-encrypted (or decrypted)
+}
+
 // MetaArray is an array of slice metadata
 type MetaArray []SliceMetadata
 
@@ -97,6 +126,20 @@ func (a MetaArray) ToMap(sliceID uuid.UUID) MetaMap {
 	return mm
 }
 
+// ToArray converts a map of metadata to an array of key/value structs (sorted by key)
+func (a MetaMap) ToArray(sliceID uuid.UUID) MetaArray {
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	meta := make(MetaArray, 0, len(keys))
+	for _, k := range keys {
+		meta = append(meta, SliceMetadata{SliceID: sliceID, Key: k, Value: a[k]})
+	}
+	return meta
+}
+
 // Equals checks if two MetaMaps have identical key/value pairs
 func (a MetaMap) Equals(b MetaMap) bool {
 	if len(a) != len(b) {
@@ -0,0 +1,51 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v9/orm"
+)
+
+// DefaultEncoding is used for grains of a slice type without an encoding
+const DefaultEncoding = "z64"
+
+// SliceType is a registered kind of slice content. Its plugins (referenced by name) validate
+// grain payloads and split documents into item-level grains.
+type SliceType struct {
+	Name        string    `json:"name" pg:",pk"`
+	Description string    `json:"description"`
+	Encoding    string    `json:"encoding"`   // default grain encoding
+	Validator   string    `json:"validator"`  // validation plugin (none accepts any payload)
+	Granulator  string    `json:"granulator"` // granulation plugin (none for level-1 grains)
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// compile-time check variables for model hooks (which take no memory)
+var _ orm.BeforeInsertHook = (*SliceType)(nil)
+var _ orm.BeforeUpdateHook = (*SliceType)(nil)
+
+// BeforeInsert hooks into insert operations, setting createdAt and updatedAt to current time
+func (t *SliceType) BeforeInsert(ctx context.Context) (context.Context, error) {
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	return ctx, nil
+}
+
+// BeforeUpdate hooks into update operations, setting updatedAt to current time
+func (t *SliceType) BeforeUpdate(ctx context.Context) (context.Context, error) {
+	t.UpdatedAt = time.Now()
+	return ctx, nil
+}
+
+// SliceTypesPaginated defines the list response
+type SliceTypesPaginated struct {
+	SliceTypes []SliceType `json:"data"`
+	Paging     *Pagination `json:"paging"`
+}
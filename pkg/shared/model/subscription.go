@@ -12,18 +12,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// SubInTerm is the sql condition for an active subscription (aliased "sub") within its terms
+const SubInTerm = "sub.active AND (sub.start_date IS NULL OR sub.start_date <= now()) AND (sub.end_date IS NULL OR sub.end_date > now())"
+
 // Subscription represents subscription model (also a m2m junction table between companies and slices)
 type Subscription struct {
-	SubID       uuid.UUID `json:"id" pg:",pk"`
-	SliceID     uuid.UUID `json:"slice_id" pg:",unique:altkey"`
-	CompanyID   uuid.UUID `json:"company_id" pg:",unique:altkey"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Active      bool      `json:"active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Company     *Company  `json:"company,omitempty"`
-	Slice       *Slice    `json:"slice,omitempty"`
+	SubID       uuid.UUID  `json:"id" pg:",pk"`
+	SliceID     uuid.UUID  `json:"slice_id" pg:",unique:altkey"`
+	CompanyID   uuid.UUID  `json:"company_id" pg:",unique:altkey"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Active      bool       `json:"active"`
+	ReleaseID   *uuid.UUID `json:"release_id,omitempty"` // pinned slice release (nil tracks the latest content)
+	RuleID      *uuid.UUID `json:"rule_id,omitempty"`    // created by a subscription rule
+	RuleRetired bool       `json:"rule_retired"`         // deactivated by its rule (not by hand)
+	StartDate   time.Time  `json:"start_date"`           // effective date (zero is open-ended)
+	EndDate     time.Time  `json:"end_date"`             // expiration date (zero is open-ended)
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Company     *Company   `json:"company,omitempty"`
+	Slice       *Slice     `json:"slice,omitempty"`
 }
 
 // compile-time check variables for model hooks (which take no memory)
@@ -44,6 +52,21 @@ func (b *Subscription) BeforeUpdate(ctx context.Context) (context.Context, error
 	return ctx, nil
 }
 
+// Started checks if the subscription's start date (if any) has been reached
+func (b *Subscription) Started(t time.Time) bool {
+	return b.StartDate.IsZero() || !t.Before(b.StartDate)
+}
+
+// Expired checks if the subscription's end date (if any) has passed
+func (b *Subscription) Expired(t time.Time) bool {
+	return !b.EndDate.IsZero() && !t.Before(b.EndDate)
+}
+
+// InTerm checks if a time is between the subscription's start and end dates
+func (b *Subscription) InTerm(t time.Time) bool {
+	return b.Started(t) && !b.Expired(t)
+}
+
 // SemiDeepCopy makes a copy of subscription without sharing memory (but one level deep)
 func (b *Subscription) SemiDeepCopy() Subscription {
 	sub := *b
@@ -0,0 +1,40 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package sandpiper_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+func TestInTerm(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	cases := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  bool
+	}{
+		{name: "open-ended", want: true},
+		{name: "started", start: now.Add(-day), want: true},
+		{name: "not started", start: now.Add(day), want: false},
+		{name: "not expired", end: now.Add(day), want: true},
+		{name: "expired", end: now.Add(-day), want: false},
+		{name: "ends now", end: now, want: false},
+		{name: "within dates", start: now.Add(-day), end: now.Add(day), want: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &sandpiper.Subscription{StartDate: tt.start, EndDate: tt.end}
+			if got := sub.InTerm(now); got != tt.want {
+				t.Errorf("InTerm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/notify"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/notify/notifytest"
+)
+
+func TestMailerSend(t *testing.T) {
+	srv, err := notifytest.NewSMTPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	m := &notify.Mailer{Addr: srv.Addr, From: "sandpiper@example.com"}
+	err = m.Send([]string{"ops@example.com", "admin@example.com"}, "Sync failed\r\nBcc: evil@example.com", "line one\n.line two")
+	assert.Nil(t, err)
+
+	msgs := srv.Messages()
+	if assert.Equal(t, 1, len(msgs)) {
+		msg := msgs[0]
+		assert.Equal(t, "sandpiper@example.com", msg.From)
+		assert.Equal(t, []string{"ops@example.com", "admin@example.com"}, msg.To)
+		assert.Contains(t, msg.Data, "Subject: Sync failed  Bcc: evil@example.com\r\n")
+		assert.NotContains(t, msg.Data, "\r\nBcc:")
+		assert.True(t, strings.HasSuffix(msg.Data, "\r\n\r\nline one\r\n.line two\r\n"))
+	}
+
+	assert.NotNil(t, m.Send(nil, "subject", "body"))
+}
+
+func TestPost(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	err := notify.Post(context.Background(), srv.URL+"/ok", map[string]string{"rule": "sync_failure"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"rule": "sync_failure"}, got)
+
+	err = notify.Post(context.Background(), srv.URL+"/fail", nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,138 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package notifytest provides a local SMTP stand-in for tests (in the spirit of httptest). It
+// accepts any sender and recipients without TLS or authentication and keeps each message.
+package notifytest
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Message is a mail received by the stand-in
+type Message struct {
+	From string
+	To   []string
+	Data string // headers and body (CRLF line endings, dot-stuffing removed)
+}
+
+// SMTPServer is a minimal SMTP server listening on a local port
+type SMTPServer struct {
+	Addr string // host:port to send to
+
+	ln   net.Listener
+	mu   sync.Mutex
+	msgs []Message
+	wg   sync.WaitGroup
+}
+
+// NewSMTPServer starts a stand-in on a random local port (call Close when done)
+func NewSMTPServer() (*SMTPServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &SMTPServer{Addr: ln.Addr().String(), ln: ln}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Messages returns the messages received so far
+func (s *SMTPServer) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.msgs...)
+}
+
+// Close stops the server (waiting for open sessions to end)
+func (s *SMTPServer) Close() {
+	s.ln.Close()
+	s.wg.Wait()
+}
+
+func (s *SMTPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return // closed
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.session(conn)
+		}()
+	}
+}
+
+// session handles one smtp conversation
+func (s *SMTPServer) session(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) bool {
+		_, err := conn.Write([]byte(line + "\r\n"))
+		return err == nil
+	}
+	if !reply("220 localhost stand-in") {
+		return
+	}
+
+	var msg Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = Message{From: address(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.To = append(msg.To, address(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" || l == ".\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.Data = b.String()
+			s.mu.Lock()
+			s.msgs = append(s.msgs, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// address removes the angle brackets (and any parameters) from a MAIL or RCPT argument
+func address(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if i := strings.Index(arg, ">"); i != -1 {
+		arg = arg[:i]
+	}
+	return strings.TrimPrefix(arg, "<")
+}
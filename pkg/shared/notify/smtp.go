@@ -0,0 +1,114 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package notify delivers messages by email (SMTP) and by json webhook.
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// defaultTimeout limits each delivery (the whole smtp conversation or webhook request)
+const defaultTimeout = 10 * time.Second
+
+// Mailer sends plain text email through an SMTP server (using STARTTLS when offered)
+type Mailer struct {
+	Addr     string // host:port of the smtp server
+	Username string // optional (PLAIN auth requires TLS unless the server is local)
+	Password string
+	From     string        // envelope and header sender
+	Timeout  time.Duration // default 10 seconds
+}
+
+// Send mails a message to the recipients
+func (m *Mailer) Send(to []string, subject, body string) error {
+	if len(to) == 0 {
+		return errors.New("no email recipients")
+	}
+	host, _, err := net.SplitHostPort(m.Addr)
+	if err != nil {
+		return err
+	}
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	conn, err := net.DialTimeout("tcp", m.Addr, timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(m.From); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return fmt.Errorf("%s: %w", addr, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.message(to, subject, body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message formats the headers and body (with CRLF line endings)
+func (m *Mailer) message(to []string, subject, body string) []byte {
+	var b bytes.Buffer
+
+	// line breaks in a value would allow injecting headers
+	clean := strings.NewReplacer("\r", " ", "\n", " ").Replace
+	header := func(k, v string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, clean(v))
+	}
+	header("From", m.From)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", clean(subject)))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	b.WriteString("\r\n")
+
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	if !strings.HasSuffix(body, "\n") {
+		b.WriteString("\r\n")
+	}
+	return b.Bytes()
+}
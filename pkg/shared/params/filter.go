@@ -0,0 +1,160 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package params
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+)
+
+/*
+Filter conditions are field:op:value (or field:value for equality). Multiple values (for "in" and
+"between") are separated by "|" because commas separate conditions. Dates are compared as text
+literals (coerced by postgresql to the column type), so "between" works as a date range.
+
+	?filter=status:ne:rejected,created_at:between:2020-01-01|2020-01-31
+	?filter=name:like:acme*          # glob pattern (* and ?), case-insensitive
+	?filter=role:in:100|110,phone:null
+*/
+
+// Filter operators (eq is assumed if no operator is provided)
+const (
+	OpEq      = "eq"
+	OpNe      = "ne"
+	OpGt      = "gt"
+	OpGe      = "ge"
+	OpLt      = "lt"
+	OpLe      = "le"
+	OpIn      = "in"
+	OpLike    = "like"
+	OpNull    = "null"
+	OpNotNull = "notnull"
+	OpBetween = "between"
+)
+
+// comparisons are operators using a simple sql comparison
+var comparisons = map[string]string{
+	OpEq: "=",
+	OpNe: "<>",
+	OpGt: ">",
+	OpGe: ">=",
+	OpLt: "<",
+	OpLe: "<=",
+}
+
+// Fields are the query fields a list allows (by field name in the query string), each mapped to
+// its column (usually qualified by the model's table alias, e.g. "tag.name")
+type Fields struct {
+	Filter map[string]string
+	Sort   map[string]string
+}
+
+// Columns maps field names to the same column names qualified by a table alias
+func Columns(alias string, names ...string) map[string]string {
+	cols := make(map[string]string, len(names))
+	for _, name := range names {
+		cols[name] = alias + "." + name
+	}
+	return cols
+}
+
+// Condition is a parsed filter condition
+type Condition struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+// ParseCondition splits a field:op:value filter condition (where field:value means equality)
+func ParseCondition(s string) (*Condition, error) {
+	i := strings.Index(s, ":")
+	if i == -1 {
+		return nil, badRequest("Invalid filter \"%s\" (use field:op:value)", s)
+	}
+	cond := &Condition{Field: strings.ToLower(strings.TrimSpace(s[:i])), Op: OpEq}
+	rest := strings.TrimSpace(s[i+1:])
+
+	// an operator is only recognized before another colon (except for the null checks)
+	if j := strings.Index(rest, ":"); j != -1 {
+		if op := strings.ToLower(rest[:j]); isOperator(op) {
+			cond.Op = op
+			rest = rest[j+1:]
+		}
+	} else if op := strings.ToLower(rest); op == OpNull || op == OpNotNull {
+		cond.Op = op
+		rest = ""
+	}
+
+	switch cond.Op {
+	case OpNull, OpNotNull:
+		if rest != "" {
+			return nil, badRequest("Filter \"%s\" does not take a value", s)
+		}
+	case OpIn:
+		cond.Values = strings.Split(rest, "|")
+	case OpBetween:
+		cond.Values = strings.Split(rest, "|")
+		if len(cond.Values) != 2 {
+			return nil, badRequest("Filter \"%s\" requires two values (from|to)", s)
+		}
+	default:
+		cond.Values = []string{rest}
+	}
+	return cond, nil
+}
+
+func isOperator(op string) bool {
+	switch op {
+	case OpIn, OpLike, OpNull, OpNotNull, OpBetween:
+		return true
+	}
+	_, ok := comparisons[op]
+	return ok
+}
+
+// apply adds the condition to a query for an (allowed) column
+func (c *Condition) apply(q *orm.Query, column string) {
+	col := pg.Ident(column)
+	switch c.Op {
+	case OpNull:
+		q.Where("? IS NULL", col)
+	case OpNotNull:
+		q.Where("? IS NOT NULL", col)
+	case OpIn:
+		q.Where("? IN (?)", col, pg.In(c.Values))
+	case OpLike:
+		q.Where("CAST(? AS text) ILIKE ?", col, GlobToLike(c.Values[0]))
+	case OpBetween:
+		q.Where("? BETWEEN ? AND ?", col, c.Values[0], c.Values[1])
+	default:
+		q.Where("? "+comparisons[c.Op]+" ?", col, c.Values[0])
+	}
+}
+
+// sortColumn parses a field[:asc|desc] sort instruction into an allowed column and direction
+func sortColumn(s string, allowed map[string]string) (string, string, error) {
+	field, dir := strings.TrimSpace(s), "ASC"
+	if i := strings.Index(field, ":"); i != -1 {
+		dir = strings.ToUpper(strings.TrimSpace(field[i+1:]))
+		field = strings.TrimSpace(field[:i])
+		if dir != "ASC" && dir != "DESC" {
+			return "", "", badRequest("Invalid sort direction \"%s\" (use asc or desc)", s)
+		}
+	}
+	column, ok := allowed[strings.ToLower(field)]
+	if !ok {
+		return "", "", badRequest("Cannot sort by \"%s\"", field)
+	}
+	return column, dir, nil
+}
+
+func badRequest(format string, a ...interface{}) error {
+	return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,153 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package params_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+type widget struct {
+	ID   int
+	Name string
+}
+
+var widgetFields = params.Fields{
+	Filter: params.Columns("widget", "id", "name", "created_at"),
+	Sort:   params.Columns("widget", "id", "name"),
+}
+
+// sql returns the select statement for a query
+func sql(t *testing.T, q *orm.Query) string {
+	b, err := q.AppendQuery(orm.NewFormatter(), nil)
+	assert.Nil(t, err)
+	return string(b)
+}
+
+func TestAddFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		filter   string
+		want     string
+		wantCode int
+	}{
+		{
+			name:   "Equality without operator",
+			filter: "name:acme",
+			want:   `WHERE ("widget"."name" = 'acme')`,
+		},
+		{
+			name:   "Comparisons",
+			filter: "id:gt:5, id:le:10,name:ne:x",
+			want:   `WHERE ("widget"."id" > '5') AND ("widget"."id" <= '10') AND ("widget"."name" <> 'x')`,
+		},
+		{
+			name:   "In list",
+			filter: "id:in:1|2|3",
+			want:   `WHERE ("widget"."id" IN ('1','2','3'))`,
+		},
+		{
+			name:   "Like glob",
+			filter: "name:like:ac_me*",
+			want:   `WHERE (CAST("widget"."name" AS text) ILIKE 'ac\_me%')`,
+		},
+		{
+			name:   "Null checks",
+			filter: "name:null,id:notnull",
+			want:   `WHERE ("widget"."name" IS NULL) AND ("widget"."id" IS NOT NULL)`,
+		},
+		{
+			name:   "Date range",
+			filter: "created_at:between:2020-01-01|2020-01-31",
+			want:   `WHERE ("widget"."created_at" BETWEEN '2020-01-01' AND '2020-01-31')`,
+		},
+		{
+			name:   "Value with colons",
+			filter: "created_at:2020-01-01T10:00:00",
+			want:   `WHERE ("widget"."created_at" = '2020-01-01T10:00:00')`,
+		},
+		{
+			name:     "Fail on field not allowed",
+			filter:   "password:x",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Fail on injection attempt",
+			filter:   "1=1 or name:x",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Fail on missing value",
+			filter:   "name",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Fail on incomplete range",
+			filter:   "created_at:between:2020-01-01",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &params.Params{Filter: []string{tt.filter}}
+			q := orm.NewQuery(nil, &widget{})
+			err := p.AddFilter(q, widgetFields)
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Contains(t, sql(t, q), tt.want)
+		})
+	}
+}
+
+func TestAddSort(t *testing.T) {
+	cases := []struct {
+		name     string
+		sort     []string
+		want     string
+		wantCode int
+	}{
+		{
+			name: "Default sort",
+			want: `ORDER BY "id" desc`,
+		},
+		{
+			name: "Fields with directions",
+			sort: []string{"name:DESC,id"},
+			want: `ORDER BY "widget"."name" DESC, "widget"."id" ASC`,
+		},
+		{
+			name:     "Fail on field not allowed",
+			sort:     []string{"created_at"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Fail on invalid direction",
+			sort:     []string{"name:sideways"},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &params.Params{Sort: tt.sort}
+			q := orm.NewQuery(nil, &widget{})
+			err := p.AddSort(q, widgetFields, "id desc")
+			if tt.wantCode != 0 {
+				assert.Equal(t, tt.wantCode, err.(*echo.HTTPError).Code)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Contains(t, sql(t, q), tt.want)
+		})
+	}
+}
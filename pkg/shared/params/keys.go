@@ -0,0 +1,122 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package params
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-pg/pg/v9/orm"
+)
+
+/*
+Grain Key Query Strings (keys are always lowercase):
+	?prefix=bb-            # keys starting with "bb-"
+	?match=bb-*-f?         # keys matching a glob pattern (* is any characters, ? is a single character)
+	?from=bb-100&to=bb-200 # keys in a range (from is inclusive, to is exclusive)
+	?after=bb-150          # keyset pagination ordered by key ("after=" for the first page)
+*/
+
+// KeyQuery is used to store grain key query uri parameters
+type KeyQuery struct {
+	Prefix string
+	Match  string
+	From   string
+	To     string
+	After  string
+	Keyset bool // "after" was provided (even if empty)
+}
+
+// NewKeyQuery searches the url query string for grain key filters
+func NewKeyQuery(vals url.Values) *KeyQuery {
+	kq := new(KeyQuery)
+	for k, v := range vals {
+		val := strings.ToLower(v[0])
+		switch k {
+		case "prefix":
+			kq.Prefix = val
+		case "match":
+			kq.Match = val
+		case "from":
+			kq.From = val
+		case "to":
+			kq.To = val
+		case "after":
+			kq.After = val
+			kq.Keyset = true
+		}
+	}
+	return kq
+}
+
+// Provided checks to see if a key query was included in the url
+func (q *KeyQuery) Provided() bool {
+	return q != nil && (q.Prefix != "" || q.Match != "" || q.From != "" || q.To != "" || q.Keyset)
+}
+
+// Values returns the key query as url query parameters (for clients)
+func (q *KeyQuery) Values() url.Values {
+	vals := url.Values{}
+	if q == nil {
+		return vals
+	}
+	var add = func(k, v string) {
+		if v != "" {
+			vals.Set(k, v)
+		}
+	}
+	add("prefix", q.Prefix)
+	add("match", q.Match)
+	add("from", q.From)
+	add("to", q.To)
+	if q.Keyset {
+		vals.Set("after", q.After)
+	}
+	return vals
+}
+
+// AddWhere includes the key conditions (and ordering by key) in an existing query
+func (q *KeyQuery) AddWhere(query *orm.Query, column string) *orm.Query {
+	if !q.Provided() {
+		return query
+	}
+	if q.Prefix != "" {
+		query = query.Where(column+" LIKE ?", likeEscape(q.Prefix)+"%")
+	}
+	if q.Match != "" {
+		query = query.Where(column+" LIKE ?", GlobToLike(q.Match))
+	}
+	if q.From != "" {
+		query = query.Where(column+" >= ?", q.From)
+	}
+	if q.To != "" {
+		query = query.Where(column+" < ?", q.To)
+	}
+	if q.Keyset && q.After != "" {
+		query = query.Where(column+" > ?", q.After)
+	}
+	return query.Order(column)
+}
+
+// GlobToLike converts a glob pattern (using * and ?) to a sql "like" pattern
+func GlobToLike(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		default:
+			b.WriteString(likeEscape(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// likeEscape escapes "like" wildcards (using the default escape character)
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
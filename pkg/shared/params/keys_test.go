@@ -0,0 +1,42 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package params_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/params"
+)
+
+func TestGlobToLike(t *testing.T) {
+	cases := map[string]string{
+		"bb-*":       "bb-%",
+		"bb-10?":     "bb-10_",
+		"bb_100*":    `bb\_100%`,
+		"50%-off*":   `50\%-off%`,
+		`path\file*`: `path\\file%`,
+	}
+	for glob, want := range cases {
+		assert.Equal(t, want, params.GlobToLike(glob), glob)
+	}
+}
+
+func TestNewKeyQuery(t *testing.T) {
+	vals, _ := url.ParseQuery("prefix=BB-&to=bb-200&after=&page=2")
+	kq := params.NewKeyQuery(vals)
+	assert.True(t, kq.Provided())
+	assert.Equal(t, "bb-", kq.Prefix)
+	assert.Equal(t, "bb-200", kq.To)
+	assert.True(t, kq.Keyset)
+
+	// round trip (for clients)
+	assert.Equal(t, *kq, *params.NewKeyQuery(kq.Values()))
+
+	empty := params.NewKeyQuery(url.Values{"page": {"2"}})
+	assert.False(t, empty.Provided())
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/go-pg/pg/v9"
 	"github.com/go-pg/pg/v9/orm"
 	"github.com/labstack/echo/v4"
 
@@ -18,7 +19,7 @@ import (
 
 /*
 Query Strings:
-	?sort=title:asc,zipcode:desc,city,&filter=lname:Johnson,age:39&include=user
+	?sort=title:asc,zipcode:desc,city,&filter=lname:Johnson,age:gt:39&include=user
 	?page=2&pagesize=20  # limit to define the number of items returned in the response
 */
 
@@ -60,46 +61,58 @@ func Parse(c echo.Context) (*Params, error) {
 	return p, nil
 }
 
-// AddSort includes zero or more "order by" clauses to an existing query
-// a missing direction implies ascending (asc)
+// AddSort includes zero or more "order by" clauses to an existing query, allowing only the sort
+// fields provided (a missing direction implies ascending), or uses the (trusted) default sort
 // e.g. ?sort=title:asc,lname:desc
-func (p *Params) AddSort(q *orm.Query, defaultSort string) {
+func (p *Params) AddSort(q *orm.Query, fields Fields, defaultSort string) error {
 	var added bool
 	// can have zero or more sort instructions
 	for _, sort := range p.Sort {
 		// each sort can have one or more comma-separated sort fields
 		for _, f := range strings.Split(sort, ",") {
-			// an optional colon separates the order direction, but postgresql requires a space
-			s := strings.ReplaceAll(f, ":", " ")
-			q.Order(s)
+			if strings.TrimSpace(f) == "" {
+				continue
+			}
+			column, dir, err := sortColumn(f, fields.Sort)
+			if err != nil {
+				return err
+			}
+			q.OrderExpr("? "+dir, pg.Ident(column))
 			added = true
 		}
 	}
 	if !added && defaultSort != "" {
 		q.Order(defaultSort)
 	}
+	return nil
 }
 
-// AddFilter includes zero or more "where" clauses to an existing query
-// Conditions are field:value and only support equivalence (=) (for now)
+// AddFilter includes zero or more "where" clauses to an existing query, allowing only the filter
+// fields provided (see filter.go for the condition grammar)
 // All conditions within a filter (and across filters) are ANDed together (for now)
 // All comparison values are strings (which works because of postgresql's "automatically coerced" literals,
 // see https://dba.stackexchange.com/questions/238983)
-// e.g. ?filter=lname:Johnson, age:39&filter=role:200
-func (p *Params) AddFilter(q *orm.Query) {
+// e.g. ?filter=lname:Johnson, age:gt:39&filter=role:in:100|110
+func (p *Params) AddFilter(q *orm.Query, fields Fields) error {
 	// can have zero or more filters
 	for _, filter := range p.Filter {
 		// each filter can have one or more comma-separated conditions
 		for _, f := range strings.Split(filter, ",") {
-			// we currently only support exact equals
-			i := strings.Index(f, ":")
-			if i != -1 {
-				field := strings.TrimSpace(f[:i])
-				value := strings.TrimSpace(f[i+1:])
-				q.Where(field+" = ?", value)
+			if strings.TrimSpace(f) == "" {
+				continue
+			}
+			cond, err := ParseCondition(f)
+			if err != nil {
+				return err
+			}
+			column, ok := fields.Filter[cond.Field]
+			if !ok {
+				return badRequest("Cannot filter by \"%s\"", cond.Field)
 			}
+			cond.apply(q, column)
 		}
 	}
+	return nil
 }
 
 // WantRelated checks to see if a particular related model should be included in the results
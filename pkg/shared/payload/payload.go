@@ -9,10 +9,12 @@ import (
 	"compress/gzip"
 	"encoding/ascii85"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"reflect"
+	"strings"
 	"unsafe"
 )
 
@@ -36,6 +38,15 @@ type PayloadData string
 // Nil is the zero value for the PayloadData type
 const Nil = ""
 
+// ValidEncoding checks an encoding name against our supported encodings (encoding_enum)
+func ValidEncoding(enc string) bool {
+	switch enc {
+	case "raw", "a85", "b64", "z64", "z85":
+		return true
+	}
+	return false
+}
+
 // Encode payload data for transmission and storage
 func Encode(b io.Reader, enc string) (PayloadData, error) {
 	switch enc {
@@ -171,3 +182,110 @@ func BytesToString(b []byte) string {
 	sh := reflect.StringHeader{Data: bh.Data, Len: bh.Len}
 	return *(*string)(unsafe.Pointer(&sh))
 }
+
+// NewReader returns a reader of the decoded payload, decompressing as it is read (so the original
+// content is never held in memory)
+func (p PayloadData) NewReader(enc string) (io.ReadCloser, error) {
+	src := strings.NewReader(string(p))
+	switch enc {
+	case "raw":
+		return ioutil.NopCloser(src), nil
+	case "a85":
+		return ioutil.NopCloser(ascii85.NewDecoder(src)), nil
+	case "b64":
+		return ioutil.NopCloser(base64.NewDecoder(base64.RawStdEncoding, src)), nil
+	case "z85":
+		return gzipReader(ascii85.NewDecoder(src))
+	case "z64":
+		return gzipReader(base64.NewDecoder(base64.RawStdEncoding, src))
+	}
+	return nil, fmt.Errorf("unknown encoding \"%s\"", enc)
+}
+
+func gzipReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	// as in fromGzip (a single compressed file)
+	reader.Multistream(false)
+	return reader, nil
+}
+
+// Content is a seekable reader of the decoded payload (e.g. for http.ServeContent). Seeking
+// backwards starts decoding again and seeking forwards skips content, so only a small buffer
+// is ever in memory. Finding the size (seeking from the end) decodes the payload once.
+type Content struct {
+	data PayloadData
+	enc  string
+	r    io.ReadCloser
+	pos  int64
+	size int64 // -1 until known
+}
+
+// NewContent returns a seekable reader of the decoded payload
+func (p PayloadData) NewContent(enc string) (*Content, error) {
+	r, err := p.NewReader(enc)
+	if err != nil {
+		return nil, err
+	}
+	return &Content{data: p, enc: enc, r: r, size: -1}, nil
+}
+
+// Read implements io.Reader
+func (c *Content) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.pos += int64(n)
+	return n, err
+}
+
+// Seek implements io.Seeker
+func (c *Content) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += c.pos
+	case io.SeekEnd:
+		if c.size < 0 {
+			if _, err := c.Seek(0, io.SeekStart); err != nil {
+				return 0, err
+			}
+			n, err := io.Copy(ioutil.Discard, c.r)
+			if err != nil {
+				return 0, err
+			}
+			c.size = n
+			c.pos = n
+		}
+		offset += c.size
+	}
+	if offset < 0 {
+		return 0, errors.New("payload: negative position")
+	}
+	if offset < c.pos {
+		if err := c.reopen(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := io.CopyN(ioutil.Discard, c.r, offset-c.pos)
+	c.pos += n
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// Close releases the decoder
+func (c *Content) Close() error {
+	return c.r.Close()
+}
+
+// reopen starts decoding from the beginning again
+func (c *Content) reopen() error {
+	c.r.Close()
+	r, err := c.data.NewReader(c.enc)
+	if err != nil {
+		return err
+	}
+	c.r, c.pos = r, 0
+	return nil
+}
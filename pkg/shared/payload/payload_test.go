@@ -7,6 +7,9 @@ package payload_test
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/sandpiper-framework/sandpiper/pkg/shared/payload"
@@ -96,6 +99,18 @@ func TestPayloadData(t *testing.T) {
 	})
 }
 
+func TestValidEncoding(t *testing.T) {
+	cases := map[string]bool{
+		"raw": true, "a85": true, "b64": true, "z64": true, "z85": true,
+		"": false, "gzip": false, "Z64": false,
+	}
+	for enc, want := range cases {
+		if got := payload.ValidEncoding(enc); got != want {
+			t.Errorf("ValidEncoding(%q) = %v, want %v", enc, got, want)
+		}
+	}
+}
+
 /*
 func main() {
 	s := []byte("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum.")
@@ -106,3 +121,42 @@ func main() {
 	}
 }
 */
+
+func TestContent(t *testing.T) {
+	src := "sandpiper rocks!"
+	for _, enc := range []string{"raw", "a85", "b64", "z64", "z85"} {
+		t.Run(enc, func(t *testing.T) {
+			data, err := payload.Encode(strings.NewReader(src), enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := data.NewContent(enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			// size (as found by http.ServeContent), then a range, then the whole content again
+			if size, err := c.Seek(0, io.SeekEnd); err != nil || size != int64(len(src)) {
+				t.Errorf("size = %d (%v), want %d", size, err, len(src))
+			}
+			if _, err := c.Seek(10, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			part := make([]byte, 5)
+			if _, err := io.ReadFull(c, part); err != nil || string(part) != "rocks" {
+				t.Errorf("range = %q (%v), want %q", part, err, "rocks")
+			}
+			if _, err := c.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			if all, err := ioutil.ReadAll(c); err != nil || string(all) != src {
+				t.Errorf("content = %q (%v), want %q", all, err, src)
+			}
+		})
+	}
+
+	if _, err := payload.PayloadData("").NewContent("zip"); err == nil {
+		t.Error("expected an unknown encoding error")
+	}
+}
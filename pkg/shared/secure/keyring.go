@@ -0,0 +1,119 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package secure
+
+// Envelope encryption for data stored at rest. Each encrypted slice has its own random
+// "data key" used to encrypt grain payloads. Data keys are only saved "wrapped" (encrypted)
+// by the server's master key, which never touches the database. Retired master keys can
+// still unwrap data keys until they are re-wrapped with the current master key.
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const keyBytes = 32 // AES-256
+
+// ErrNoMasterKey indicates encryption was requested without a configured master key
+var ErrNoMasterKey = errors.New("no master key configured for encryption at rest (see MASTER_KEY)")
+
+// ErrUnknownKey indicates a data key was not wrapped by any of our master keys
+var ErrUnknownKey = errors.New("data key cannot be unwrapped with the current (or any retired) master key")
+
+// Keyring holds the master keys used to wrap and unwrap data keys
+type Keyring struct {
+	master  string   // base64 key used to wrap new data keys
+	retired []string // base64 keys only used to unwrap
+}
+
+// NewKeyring returns a keyring using base64 encoded AES-256 keys. An empty master key is
+// allowed (if encryption is not used), but every supplied key must be valid.
+func NewKeyring(master string, retired []string) (*Keyring, error) {
+	if master == "" && len(retired) > 0 {
+		return nil, errors.New("retired master keys require a current master key")
+	}
+	for i, key := range append([]string{master}, retired...) {
+		if key == "" && i == 0 {
+			continue
+		}
+		if err := checkKey(key); err != nil {
+			return nil, fmt.Errorf("invalid master key #%d: %w", i+1, err)
+		}
+	}
+	return &Keyring{master: master, retired: retired}, nil
+}
+
+// Enabled returns true if a master key is available
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.master != ""
+}
+
+// NewDataKey returns a new random data key (base64) with its wrapped form (to be saved)
+func (k *Keyring) NewDataKey() (key string, wrapped string, err error) {
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = base64.StdEncoding.EncodeToString(b)
+	wrapped, err = k.Wrap(key)
+	return key, wrapped, err
+}
+
+// Wrap encrypts a data key with the current master key
+func (k *Keyring) Wrap(key string) (string, error) {
+	if !k.Enabled() {
+		return "", ErrNoMasterKey
+	}
+	return Seal([]byte(key), k.master)
+}
+
+// Unwrap decrypts a data key using the current or any retired master key. The current
+// flag is false if a retired master key was needed (so the data key should be re-wrapped).
+func (k *Keyring) Unwrap(wrapped string) (key string, current bool, err error) {
+	if !k.Enabled() {
+		return "", false, ErrNoMasterKey
+	}
+	for i, master := range append([]string{k.master}, k.retired...) {
+		b, err := Open(wrapped, master)
+		if err == nil {
+			return string(b), i == 0, nil
+		}
+	}
+	return "", false, ErrUnknownKey
+}
+
+// Seal encrypts data with a base64 key returning base64 text (suitable for a text column)
+func Seal(data []byte, keyB64 string) (string, error) {
+	b, err := Encrypt(data, keyB64)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Open decrypts base64 text created by Seal
+func Open(sealed string, keyB64 string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 12 { // shorter than a gcm nonce
+		return nil, errors.New("sealed data is too short")
+	}
+	return Decrypt(b, keyB64)
+}
+
+func checkKey(keyB64 string) error {
+	key, err := binaryKey(keyB64)
+	if err != nil {
+		return err
+	}
+	if len(key) != keyBytes {
+		return fmt.Errorf("must be %d bytes (base64 encoded)", keyBytes)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package secure_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/secure"
+)
+
+const (
+	oldMaster = "u7WJ3kpqyvAkKb7HIfYJoSok2DoqTa9YhaCUhUujqb8="
+	newMaster = "K1ESyBU9hDq8DhJUvjFXMEctFaAgvn7JSnDJQTNsEpI="
+)
+
+func TestKeyring(t *testing.T) {
+	old, err := secure.NewKeyring(oldMaster, nil)
+	assert.Nil(t, err)
+	key, wrapped, err := old.NewDataKey()
+	assert.Nil(t, err)
+	assert.NotEqual(t, key, wrapped)
+
+	// unwrap with the same master key
+	got, current, err := old.Unwrap(wrapped)
+	assert.Nil(t, err)
+	assert.True(t, current)
+	assert.Equal(t, key, got)
+
+	// unwrap after master key rotation
+	rotated, err := secure.NewKeyring(newMaster, []string{oldMaster})
+	assert.Nil(t, err)
+	got, current, err = rotated.Unwrap(wrapped)
+	assert.Nil(t, err)
+	assert.False(t, current)
+	assert.Equal(t, key, got)
+
+	// old master key removed
+	lost, err := secure.NewKeyring(newMaster, nil)
+	assert.Nil(t, err)
+	_, _, err = lost.Unwrap(wrapped)
+	assert.Equal(t, secure.ErrUnknownKey, err)
+
+	// no master key
+	none, err := secure.NewKeyring("", nil)
+	assert.Nil(t, err)
+	_, _, err = none.NewDataKey()
+	assert.Equal(t, secure.ErrNoMasterKey, err)
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	_, err := secure.NewKeyring("c2hvcnQ=", nil)
+	assert.NotNil(t, err)
+	_, err = secure.NewKeyring("", []string{oldMaster})
+	assert.NotNil(t, err)
+}
+
+func TestSeal(t *testing.T) {
+	sealed, err := secure.Seal([]byte("<Item>BB-100</Item>"), newMaster)
+	assert.Nil(t, err)
+	data, err := secure.Open(sealed, newMaster)
+	assert.Nil(t, err)
+	assert.Equal(t, "<Item>BB-100</Item>", string(data))
+	_, err = secure.Open(sealed, oldMaster)
+	assert.NotNil(t, err)
+}
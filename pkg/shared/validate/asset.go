@@ -0,0 +1,60 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package validate
+
+// asset (digital file) validator
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Asset checks content size and mime type. The type is taken from the file extension of the
+// source (or grain key), falling back to the content itself. Allowed types can end with "/*".
+func Asset(maxBytes int, types []string) Validator {
+	return func(c Content) []string {
+		var problems []string
+
+		if len(c.Data) == 0 {
+			problems = append(problems, "asset is empty")
+		}
+		if len(c.Data) > maxBytes {
+			problems = append(problems, fmt.Sprintf("asset size %d bytes is more than the %d allowed", len(c.Data), maxBytes))
+		}
+		if mt := MimeType(c); !allowedType(mt, types) {
+			problems = append(problems, fmt.Sprintf("asset type \"%s\" is not allowed", mt))
+		}
+		return problems
+	}
+}
+
+// MimeType returns the media type (without parameters) for content
+func MimeType(c Content) string {
+	name := c.Source
+	if name == "" {
+		name = c.Key
+	}
+	mt := mime.TypeByExtension(path.Ext(name))
+	if mt == "" {
+		mt = http.DetectContentType(c.Data)
+	}
+	if t, _, err := mime.ParseMediaType(mt); err == nil {
+		return t
+	}
+	return mt
+}
+
+// allowedType checks a media type against a list (supporting "type/*" wildcards)
+func allowedType(mt string, types []string) bool {
+	for _, t := range types {
+		if t == mt || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
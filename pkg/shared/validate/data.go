@@ -0,0 +1,59 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package validate
+
+// generic data validators (well-formed json and csv)
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// JSON checks that content is a single well-formed json value
+func JSON() Validator {
+	return func(c Content) []string {
+		d := json.NewDecoder(bytes.NewReader(c.Data))
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			if err == io.EOF {
+				return []string{"missing json value"}
+			}
+			return []string{err.Error()}
+		}
+		if _, err := d.Token(); err != io.EOF {
+			return []string{"more than one json value"}
+		}
+		return nil
+	}
+}
+
+// CSV checks that content is well-formed csv with the same number of fields on every record
+func CSV() Validator {
+	return func(c Content) []string {
+		var problems []string
+
+		r := csv.NewReader(bytes.NewReader(c.Data))
+		r.ReuseRecord = true
+		for {
+			_, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if pe, ok := err.(*csv.ParseError); ok && pe.Err == csv.ErrFieldCount {
+					problems = append(problems, err.Error()) // keep going to report every record
+					continue
+				}
+				return append(problems, err.Error())
+			}
+		}
+		if len(problems) == 0 && r.FieldsPerRecord == 0 {
+			return []string{"missing csv content"}
+		}
+		return limit(problems)
+	}
+}
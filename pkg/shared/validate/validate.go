@@ -0,0 +1,141 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+// Package validate checks grain payloads according to their slice type before they are stored.
+package validate
+
+import (
+	"fmt"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+/* Validators are registered by plugin name (the standard plugins are named after the standard
+ * slice types). Each slice type names its plugin in the slice_types table. All validators for a
+ * plugin are run (so every problem is reported at once). Slice types without a plugin accept any
+ * payload.
+ *
+ * Usage:
+ *   reg := validate.New(cfg.Validation, log)
+ *   problems := reg.Validate(sliceType.Validator, validate.Content{Key: key, Source: src, Data: data})
+ *   batch := reg.ValidateBatch(sliceType.Validator, contents) // e.g. for a bulk import
+ */
+
+const (
+	defaultMaxAssetMB = 50
+	maxProblems       = 25 // limit the problems returned by a single validator
+)
+
+var defaultAssetTypes = []string{"image/*", "video/*", "application/pdf"}
+
+// Content is a decoded grain payload to validate
+type Content struct {
+	Key    string // grain_key
+	Source string // original file name (if known)
+	Data   []byte
+}
+
+// Validator checks content, returning a description of each problem found
+type Validator func(Content) []string
+
+// BatchValidator checks several contents at once (e.g. to run an external program only once),
+// returning the problems found for each content (in the same order)
+type BatchValidator func([]Content) [][]string
+
+// Registry holds validators by plugin name
+type Registry struct {
+	validators map[string][]BatchValidator
+}
+
+// New creates a registry with our standard validators (using optional configuration settings).
+// The logger records any xsd validation that had to be skipped.
+func New(cfg *config.Validation, logger sandpiper.Logger) *Registry {
+	if cfg == nil {
+		cfg = &config.Validation{}
+	}
+	r := &Registry{validators: make(map[string][]BatchValidator)}
+
+	// well-formed xml with the expected root element
+	r.Register("aces-file", XMLRoot("ACES"))
+	r.Register("aces-items", XMLRoot("App", "Asset", "DigitalFileInformation"))
+	r.Register("pies-file", XMLRoot("PIES"))
+	r.Register("pies-items", XMLRoot("Item"))
+	r.Register("pies-marketcopy", XMLRoot("MarketCopy"))
+	r.Register("pies-pricesheet", XMLRoot("PriceSheet"))
+
+	// generic formats (for other slice types)
+	r.Register("xml", XMLRoot())
+	r.Register("json", JSON())
+	r.Register("csv", CSV())
+
+	// optional xsd validation (only for complete documents)
+	for name, schema := range cfg.Schemas {
+		r.RegisterBatch(name, XSD(cfg.XMLLint, schema, logger))
+	}
+
+	// limit asset size and type
+	maxMB, types := cfg.MaxAssetMB, cfg.AssetTypes
+	if maxMB <= 0 {
+		maxMB = defaultMaxAssetMB
+	}
+	if len(types) == 0 {
+		types = defaultAssetTypes
+	}
+	r.Register("asset-files", Asset(maxMB<<20, types))
+
+	return r
+}
+
+// Register adds a validator to a plugin
+func (r *Registry) Register(name string, v Validator) {
+	r.RegisterBatch(name, func(cs []Content) [][]string {
+		problems := make([][]string, len(cs))
+		for i, c := range cs {
+			problems[i] = v(c)
+		}
+		return problems
+	})
+}
+
+// RegisterBatch adds a batch validator to a plugin
+func (r *Registry) RegisterBatch(name string, v BatchValidator) {
+	r.validators[name] = append(r.validators[name], v)
+}
+
+// Has checks if a plugin name is registered
+func (r *Registry) Has(name string) bool {
+	_, ok := r.validators[name]
+	return ok
+}
+
+// Validate runs all validators for a plugin, returning any problems found (an empty name
+// accepts any content)
+func (r *Registry) Validate(name string, c Content) []string {
+	return r.ValidateBatch(name, []Content{c})[0]
+}
+
+// ValidateBatch runs all validators for a plugin on several contents at once, returning the
+// problems found for each content (in the same order)
+func (r *Registry) ValidateBatch(name string, cs []Content) [][]string {
+	problems := make([][]string, len(cs))
+	if len(cs) == 0 {
+		return problems
+	}
+	for _, v := range r.validators[name] {
+		for i, p := range v(cs) {
+			problems[i] = append(problems[i], p...)
+		}
+	}
+	return problems
+}
+
+// limit truncates a list of problems (noting how many were left out)
+func limit(problems []string) []string {
+	if len(problems) <= maxProblems {
+		return problems
+	}
+	more := len(problems) - maxProblems
+	return append(problems[:maxProblems], fmt.Sprintf("(%d more)", more))
+}
@@ -0,0 +1,217 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package validate_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/config"
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/validate"
+)
+
+func TestValidate(t *testing.T) {
+	reg := validate.New(&config.Validation{MaxAssetMB: 1, AssetTypes: []string{"image/*", "application/pdf"}}, &logger{})
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	cases := []struct {
+		name      string
+		sliceType string
+		content   validate.Content
+		wantCount int // number of problems
+	}{
+		{
+			name:      "Valid ACES file",
+			sliceType: "aces-file",
+			content:   validate.Content{Data: []byte(`<?xml version="1.0"?><ACES version="4.2"><App id="1"/></ACES>`)},
+		},
+		{
+			name:      "Truncated ACES file",
+			sliceType: "aces-file",
+			content:   validate.Content{Data: []byte(`<ACES version="4.2"><App id="1"/>`)},
+			wantCount: 1,
+		},
+		{
+			name:      "PIES file in ACES slice",
+			sliceType: "aces-file",
+			content:   validate.Content{Data: []byte(`<PIES><Items/></PIES>`)},
+			wantCount: 1,
+		},
+		{
+			name:      "Not xml",
+			sliceType: "pies-file",
+			content:   validate.Content{Data: []byte(`part,brand`)},
+			wantCount: 1,
+		},
+		{
+			name:      "Two root elements",
+			sliceType: "pies-items",
+			content:   validate.Content{Data: []byte(`<Item/><Item/>`)},
+			wantCount: 1,
+		},
+		{
+			name:      "ACES item",
+			sliceType: "aces-items",
+			content:   validate.Content{Data: []byte(`<Asset id="1"><AssetName>a.jpg</AssetName></Asset>`)},
+		},
+		{
+			name:      "Image asset",
+			sliceType: "asset-files",
+			content:   validate.Content{Key: "img/bb100.png", Data: png},
+		},
+		{
+			name:      "Sniffed asset type",
+			sliceType: "asset-files",
+			content:   validate.Content{Key: "bb100", Data: png},
+		},
+		{
+			name:      "Disallowed asset type",
+			sliceType: "asset-files",
+			content:   validate.Content{Source: "notes.txt", Data: []byte("hello")},
+			wantCount: 1,
+		},
+		{
+			name:      "Asset too big and wrong type",
+			sliceType: "asset-files",
+			content:   validate.Content{Source: "big.zip", Data: bytes.Repeat([]byte("x"), 1<<20+1)},
+			wantCount: 2,
+		},
+		{
+			name:      "Any xml root",
+			sliceType: "xml",
+			content:   validate.Content{Data: []byte(`<Manifest><File name="a.jpg"/></Manifest>`)},
+		},
+		{
+			name:      "Valid json",
+			sliceType: "json",
+			content:   validate.Content{Data: []byte(`{"files": [{"name": "a.jpg"}]}`)},
+		},
+		{
+			name:      "Two json values",
+			sliceType: "json",
+			content:   validate.Content{Data: []byte(`{"a": 1} {"b": 2}`)},
+			wantCount: 1,
+		},
+		{
+			name:      "Valid csv",
+			sliceType: "csv",
+			content:   validate.Content{Data: []byte("part,brand\nBB100,BBRK\n")},
+		},
+		{
+			name:      "Ragged csv",
+			sliceType: "csv",
+			content:   validate.Content{Data: []byte("part,brand\nBB100\nBB200,BBRK,extra\n")},
+			wantCount: 2,
+		},
+		{
+			name:      "Unvalidated slice type",
+			sliceType: "partspro-file",
+			content:   validate.Content{Data: []byte("anything")},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := reg.Validate(tt.sliceType, tt.content)
+			assert.Len(t, problems, tt.wantCount, "problems: %v", problems)
+		})
+	}
+}
+
+func TestXSD(t *testing.T) {
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "xsd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	schema := filepath.Join(dir, "aces.xsd")
+	xsd := `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+	<xs:element name="ACES">
+		<xs:complexType>
+			<xs:sequence>
+				<xs:element name="App" maxOccurs="unbounded">
+					<xs:complexType>
+						<xs:sequence><xs:element name="Qty" type="xs:integer"/></xs:sequence>
+						<xs:attribute name="id" type="xs:integer" use="required"/>
+					</xs:complexType>
+				</xs:element>
+			</xs:sequence>
+		</xs:complexType>
+	</xs:element>
+</xs:schema>`
+	if err := ioutil.WriteFile(schema, []byte(xsd), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := validate.New(&config.Validation{XMLLint: xmllint, Schemas: map[string]string{"aces-file": schema}}, &logger{})
+
+	problems := reg.Validate("aces-file", validate.Content{Data: []byte(`<ACES><App id="1"><Qty>2</Qty></App></ACES>`)})
+	assert.Empty(t, problems)
+
+	problems = reg.Validate("aces-file", validate.Content{Data: []byte(`<ACES><App id="1"><Qty>two</Qty></App></ACES>`)})
+	assert.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "Qty")
+
+	// a batch is checked by one xmllint process (problems are returned for each content)
+	batch := reg.ValidateBatch("aces-file", []validate.Content{
+		{Data: []byte(`<ACES><App id="1"><Qty>2</Qty></App></ACES>`)},
+		{Data: []byte(`<ACES><App id="x"><Qty>two</Qty></App></ACES>`)},
+		{Data: []byte(`<ACES><App id="3"><Qty>3</Qty></App></ACES>`)},
+		{Data: []byte(`<ACES><App id="4">`)},
+	})
+	if assert.Len(t, batch, 4) {
+		assert.Empty(t, batch[0])
+		assert.Len(t, batch[1], 2)
+		assert.Empty(t, batch[2])
+		assert.NotEmpty(t, batch[3])
+	}
+
+	// a broken schema skips xsd validation (rather than rejecting content)
+
+	broken := filepath.Join(dir, "broken.xsd")
+	if err := ioutil.WriteFile(broken, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element/></xs:schema>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	log := &logger{}
+	reg = validate.New(&config.Validation{XMLLint: xmllint, Schemas: map[string]string{"aces-file": broken}}, log)
+	assert.Empty(t, reg.Validate("aces-file", validate.Content{Data: []byte(`<ACES><App id="1"><Qty>two</Qty></App></ACES>`)}))
+	assert.Len(t, log.errs, 1)
+
+	// other validators for the plugin still run
+	assert.NotEmpty(t, reg.Validate("aces-file", validate.Content{Data: []byte(`<PIES/>`)}))
+}
+
+// logger records the errors logged
+type logger struct {
+	errs []error
+}
+
+func (l *logger) Log(_ echo.Context, _, _ string, err error, _ map[string]interface{}) {
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+}
+
+func TestXSDMissingXMLLint(t *testing.T) {
+	// a missing xmllint skips xsd validation (rather than rejecting content) and is logged
+	log := &logger{}
+	v := validate.XSD(filepath.Join(os.TempDir(), "sandpiper-missing-xmllint"), "aces.xsd", log)
+	problems := v([]validate.Content{{Data: []byte(`<ACES/>`)}, {Data: []byte(`<ACES/>`)}})
+	assert.Equal(t, [][]string{nil, nil}, problems)
+	assert.Len(t, log.errs, 1)
+}
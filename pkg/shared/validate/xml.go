@@ -0,0 +1,162 @@
+// Copyright The Sandpiper Authors. All rights reserved.
+// This file is licensed under the Artistic License 2.0.
+// License text can be found in the project's LICENSE file.
+
+package validate
+
+// xml validators (well-formed with expected root and optional xsd schema)
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/model"
+)
+
+// XMLRoot checks that content is well-formed xml with one of the supplied root elements (or
+// any root element if none are supplied)
+func XMLRoot(roots ...string) Validator {
+	return func(c Content) []string {
+		var root string
+
+		d := xml.NewDecoder(bytes.NewReader(c.Data))
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return []string{err.Error()} // includes line number
+			}
+			if t, ok := tok.(xml.StartElement); ok {
+				if root != "" {
+					return []string{fmt.Sprintf("more than one root element (found <%s> after <%s>)", t.Name.Local, root)}
+				}
+				root = t.Name.Local
+				if len(roots) > 0 && !contains(roots, root) {
+					return []string{fmt.Sprintf("root element <%s> is not one of <%s>", root, strings.Join(roots, ">, <"))}
+				}
+				// the whole element must be well-formed
+				if err := d.Skip(); err != nil {
+					return []string{err.Error()}
+				}
+			}
+		}
+		if root == "" {
+			if len(roots) == 0 {
+				return []string{"missing root element"}
+			}
+			return []string{"missing root element <" + strings.Join(roots, "> or <") + ">"}
+		}
+		return nil
+	}
+}
+
+// XSD validates contents against an xsd schema file using xmllint (from libxml2). A batch is
+// checked by a single xmllint process. If xmllint can't be run (or the schema can't be used), xsd
+// validation is skipped with a logged error rather than rejecting the content.
+func XSD(xmllint, schema string, logger sandpiper.Logger) BatchValidator {
+	if xmllint == "" {
+		xmllint = "xmllint"
+	}
+	return func(cs []Content) [][]string {
+		problems, err := xsdBatch(xmllint, schema, cs)
+		if err != nil {
+			logger.Log(nil, "validate", "XSD validation skipped", err,
+				map[string]interface{}{
+					"schema":   schema,
+					"contents": len(cs),
+				},
+			)
+			return make([][]string, len(cs))
+		}
+		return problems
+	}
+}
+
+// xsdBatch writes the contents to temporary files and validates them with one xmllint process
+func xsdBatch(xmllint, schema string, cs []Content) ([][]string, error) {
+	dir, err := ioutil.TempDir("", "sandpiper-xsd")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"--noout", "--nonet", "--schema", schema}
+	index := make(map[string]int, len(cs))
+	for i, c := range cs {
+		name := strconv.Itoa(i) + ".xml"
+		if err := ioutil.WriteFile(filepath.Join(dir, name), c.Data, 0600); err != nil {
+			return nil, err
+		}
+		index[name] = i
+		args = append(args, filepath.Join(dir, name))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(xmllint, args...)
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return make([][]string, len(cs)), nil
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		return nil, err // could not run the validator at all (so don't blame the content)
+	}
+
+	// one problem per line, starting with the file name (e.g. ".../3.xml:12: element Qty: Schemas
+	// validity error : ...") and a summary line for each file (".../3.xml fails to validate")
+	problems := make([][]string, len(cs))
+	failed := make(map[int]bool)
+	prefix := dir + string(filepath.Separator)
+	for _, line := range strings.Split(stderr.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			continue // not about a file (e.g. a schema problem)
+		}
+		line = line[len(prefix):]
+		n := strings.IndexAny(line, ": ")
+		if n < 0 {
+			continue
+		}
+		i, ok := index[line[:n]]
+		if !ok {
+			continue
+		}
+		switch msg := line[n:]; msg {
+		case " validates":
+		case " fails to validate":
+			failed[i] = true
+		default:
+			problems[i] = append(problems[i], strings.TrimPrefix(msg, ":"))
+		}
+	}
+	if len(failed) == 0 {
+		// xmllint failed without rejecting any content (e.g. the schema doesn't compile)
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	for i := range problems {
+		if failed[i] && len(problems[i]) == 0 {
+			problems[i] = []string{"fails to validate"}
+		}
+		problems[i] = limit(problems[i])
+	}
+	return problems, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -7,8 +7,11 @@ package zlog
 import (
 	"os"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
+
+	"github.com/sandpiper-framework/sandpiper/pkg/shared/middleware/session"
 )
 
 // Log represents zerolog logger
@@ -32,7 +35,8 @@ func New(logInfoLevel bool) *Log {
 	}
 }
 
-// Log implements the sandpiper.Logger interface (using zerolog)
+// Log implements the sandpiper.Logger interface (using zerolog). The context is nil for
+// background work (which has no request).
 func (z *Log) Log(ctx echo.Context, source, msg string, err error, params map[string]interface{}) {
 
 	if params == nil {
@@ -41,9 +45,15 @@ func (z *Log) Log(ctx echo.Context, source, msg string, err error, params map[st
 
 	params["service"] = source
 
-	if id, ok := ctx.Get("id").(int); ok {
-		params["user_id"] = id
-		params["user"] = ctx.Get("username").(string)
+	if ctx != nil {
+		if id, ok := ctx.Get("id").(int); ok {
+			params["user_id"] = id
+			params["user"] = ctx.Get("username").(string)
+		}
+
+		if id := session.ID(ctx); id != uuid.Nil {
+			params["sync_session"] = id
+		}
 	}
 
 	if err != nil {